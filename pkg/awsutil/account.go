@@ -24,6 +24,7 @@ type Account struct {
 	aliases         []string
 	regions         []string
 	disabledRegions []string
+	organization    *Organization
 }
 
 func NewAccount(creds *Credentials, endpoints config.CustomEndpoints) (*Account, error) {
@@ -132,6 +133,22 @@ func (a *Account) Aliases() []string {
 	return a.aliases
 }
 
+// SetOrganization sets the organization the account belongs to, for when the organization is accessed with other
+// credentials than those of the account, such as the credentials of the management account.
+func (a *Account) SetOrganization(org *Organization) {
+	a.organization = org
+}
+
+// Organization returns the organization the account belongs to, accessed with the credentials of the account unless
+// another organization was set.
+func (a *Account) Organization() (*Organization, error) {
+	if a.organization != nil {
+		return a.organization, nil
+	}
+
+	return NewOrganization(a.Credentials)
+}
+
 func (a *Account) ResourceTypeToServiceType(regionName, resourceType string) string {
 	customRegion := a.CustomEndpoints.GetRegion(regionName)
 	if customRegion == nil {
@@ -23,6 +23,7 @@ func (c *Credentials) NewConfig(ctx context.Context, region, serviceType string)
 	log.Debugf("creating new config in %s for %s", region, serviceType)
 
 	var global bool
+	owner := region
 	if region == GlobalRegionID {
 		region = "us-east-1"
 		global = true
@@ -42,7 +43,8 @@ func (c *Credentials) NewConfig(ctx context.Context, region, serviceType string)
 		opts = append(opts,
 			config.WithRegion(region),
 			config.WithCredentialsProvider(c.awsNewStaticCredentialsV2()),
-			config.WithBaseEndpoint(customService.URL))
+			config.WithBaseEndpoint(customService.URL),
+			config.WithAPIOptions([]func(*middleware.Stack) error{c.addRateLimit(), c.addObserveRequest(), c.addTraceCall(owner)}))
 
 		if customService.TLSInsecureSkipVerify {
 			client := &http.Client{
@@ -69,11 +71,10 @@ func (c *Credentials) NewConfig(ctx context.Context, region, serviceType string)
 
 		cfgCopy := root.Copy()
 		cfgCopy.Region = region
-		if global {
-			cfgCopy.APIOptions = append(cfgCopy.APIOptions, func(stack *middleware.Stack) error {
-				return stack.Initialize.Add(SkipGlobal{}, middleware.After)
-			})
-		}
+
+		// Note: the API options are copied, the root config is shared by every region
+		cfgCopy.APIOptions = append(append([]func(*middleware.Stack) error{}, root.APIOptions...),
+			addSkipRequest(global), c.addRateLimit(), c.addObserveRequest(), c.addTraceCall(owner))
 		cfg = &cfgCopy
 	}
 
@@ -81,10 +82,35 @@ func (c *Credentials) NewConfig(ctx context.Context, region, serviceType string)
 }
 
 func (c *Credentials) rootConfig(ctx context.Context) (*aws.Config, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	if c.cfg != nil {
 		return c.cfg, nil
 	}
 
+	if c.source != nil {
+		sourceCfg, err := c.source.rootConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := sourceCfg.Copy()
+		cfg.Credentials = stscreds.NewAssumeRoleProvider(sts.NewFromConfig(*sourceCfg), c.AssumeRoleArn,
+			func(p *stscreds.AssumeRoleOptions) {
+				if c.RoleSessionName != "" {
+					p.RoleSessionName = c.RoleSessionName
+				}
+
+				if c.ExternalID != "" {
+					p.ExternalID = aws.String(c.ExternalID)
+				}
+			})
+
+		c.cfg = &cfg
+		return c.cfg, nil
+	}
+
 	var opts []func(*config.LoadOptions) error
 	opts = append(opts, config.WithAPIOptions([]func(*middleware.Stack) error{
 		func(stack *middleware.Stack) error {
@@ -153,43 +179,6 @@ func (c *Credentials) rootConfig(ctx context.Context) (*aws.Config, error) {
 	return c.cfg, nil
 }
 
-// SkipGlobal returns ErrSkipRequest when operating in the global
-// pseudo-region.
-//
-// FUTURE: define mechanism for allowing specific resources, such as those in
-// IAM, to override this skip.
-//
-// The simplest way to do this would be to remove this middleware through
-// functional options on relevant operations. e.g.:
-//
-//	 func isGlobalResource(o *iam.Options) {
-//		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
-//			stack.Initialize.Remove(config.SkipGlobal{}.ID())
-//		})
-//	 }
-//
-//	 // per-operation
-//	 out, err := svc.ListRoles(context.Background(), nil, isGlobalResource)
-//	 // on a client, if you know you're only operating in the context of global resources
-//	 svc := iam.NewFromConfig(cfg, isGlobalResource)
-//
-// You could also define some kind of "is global resource" Context flag, which
-// SkipGlobal could react to. That may be preferrable to having SkipGlobal be
-// exported from this package.
-type SkipGlobal struct{}
-
-func (SkipGlobal) ID() string {
-	return "aws-nuke::skipGlobal"
-}
-
-func (SkipGlobal) HandleInitialize(
-	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
-) (
-	out middleware.InitializeOutput, md middleware.Metadata, err error,
-) {
-	return out, md, liberrors.ErrSkipRequest(fmt.Sprintf("skip global: '%s'", middleware.GetServiceID(ctx)))
-}
-
 type traceRequest struct{}
 
 func (traceRequest) ID() string {
@@ -0,0 +1,110 @@
+package awsutil
+
+import (
+	"context"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/metrics"
+)
+
+// attemptStartKey is the context key of the time at which the current attempt of an SDK v1 request was sent.
+type attemptStartKey struct{}
+
+// addMetricsHandlers adds the handlers that record the latency of every attempt of the requests of an SDK v1 session.
+// The time spent waiting for the rate limiter is not part of the latency.
+func (c *Credentials) addMetricsHandlers(handlers *request.Handlers) {
+	if c.Metrics == nil {
+		return
+	}
+
+	handlers.Send.PushFront(func(r *request.Request) {
+		r.SetContext(context.WithValue(r.Context(), attemptStartKey{}, time.Now()))
+	})
+
+	observe := func(r *request.Request, result string) {
+		start, ok := r.Context().Value(attemptStartKey{}).(time.Time)
+		if !ok {
+			return
+		}
+
+		c.Metrics.ObserveRequest(endpointsID(r), r.Operation.Name, aws.StringValue(r.Config.Region), result,
+			time.Since(start))
+	}
+
+	handlers.Retry.PushFront(func(r *request.Request) {
+		if isThrottleError(r) {
+			observe(r, metrics.ResultThrottled)
+		} else {
+			observe(r, metrics.ResultError)
+		}
+	})
+
+	handlers.Complete.PushBack(func(r *request.Request) {
+		if r.Error == nil {
+			observe(r, metrics.ResultSuccess)
+		}
+	})
+}
+
+// observeRequest is the SDK v2 equivalent of the metrics handlers of SDK v1 sessions. It runs right before the request
+// is signed and after the rate limiter, so it measures every attempt without the time spent waiting for the rate
+// limiter.
+type observeRequest struct {
+	metrics *metrics.Metrics
+}
+
+func (observeRequest) ID() string {
+	return "aws-nuke::observeRequest"
+}
+
+func (m observeRequest) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, md middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*smithyhttp.Request)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	start := time.Now()
+	out, md, err = next.HandleFinalize(ctx, in)
+
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultError
+		if isThrottleErrorV2(err) {
+			result = metrics.ResultThrottled
+		}
+	}
+
+	region := awsmiddleware.GetRegion(ctx)
+	m.metrics.ObserveRequest(endpointsIDFromHost(req.URL.Hostname(), region), awsmiddleware.GetOperationName(ctx),
+		region, result, time.Since(start))
+
+	return out, md, err
+}
+
+// addObserveRequest returns the API option that adds the observeRequest middleware right before the request is
+// signed, or does nothing when no metrics are collected.
+func (c *Credentials) addObserveRequest() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if c.Metrics == nil {
+			return nil
+		}
+
+		m := observeRequest{metrics: c.Metrics}
+
+		if _, ok := stack.Finalize.Get(signingID); ok {
+			return stack.Finalize.Insert(m, signingID, middleware.Before)
+		}
+
+		return stack.Finalize.Add(m, middleware.After)
+	}
+}
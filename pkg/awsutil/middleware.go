@@ -0,0 +1,96 @@
+package awsutil
+
+import (
+	"context"
+	"strings"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// resolveEndpointV2ID is the ID of the middleware that resolves the endpoint of a request in every SDK v2 client.
+const resolveEndpointV2ID = "ResolveEndpointV2"
+
+// SkipRequest is the SDK v2 equivalent of the skipGlobalHandler and skipMissingServiceInRegionHandler of SDK v1
+// sessions. It returns ErrSkipRequest when a global service is called from a regional config, a regional service is
+// called from the global config, or the service is not available in the region of the config.
+//
+// SDK v2 clients do not expose the endpoints ID of their service, so the service is identified by the host of the
+// resolved endpoint instead. The middleware therefore runs once the endpoint is resolved.
+type SkipRequest struct {
+	Global bool
+}
+
+func (SkipRequest) ID() string {
+	return "aws-nuke::skipRequest"
+}
+
+func (m SkipRequest) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, md middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*smithyhttp.Request)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	region := awsmiddleware.GetRegion(ctx)
+	host := req.URL.Hostname()
+	service := endpointsIDFromHost(host, region)
+
+	if err := skipGlobal(service, host, m.Global); err != nil {
+		return out, md, err
+	}
+
+	if err := skipMissingServiceInRegion(service, region); err != nil {
+		return out, md, err
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
+
+// addSkipRequest returns the API option that adds the SkipRequest middleware right after the endpoint is resolved.
+func addSkipRequest(global bool) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		m := SkipRequest{Global: global}
+
+		if _, ok := stack.Finalize.Get(resolveEndpointV2ID); ok {
+			return stack.Finalize.Insert(m, resolveEndpointV2ID, middleware.After)
+		}
+
+		return stack.Finalize.Add(m, middleware.After)
+	}
+}
+
+// endpointsIDFromHost returns the endpoints ID of the service from the host of its endpoint, for example logs for
+// logs.us-east-1.amazonaws.com and iam for iam.amazonaws.com. Labels in front of the endpoints ID, such as the account
+// ID of S3 Control or the bucket name of S3, are ignored. When no known endpoints ID is found, the full prefix of the
+// host is returned.
+func endpointsIDFromHost(host, region string) string {
+	labels := strings.Split(host, ".")
+
+	end := -1
+	for i, label := range labels {
+		if label == region || label == "amazonaws" {
+			end = i
+			break
+		}
+	}
+
+	if end <= 0 {
+		return host
+	}
+
+	prefix := labels[:end]
+	for i := range prefix {
+		candidate := strings.Join(prefix[i:], ".")
+		if _, ok := endpoints.RegionsForService(endpoints.DefaultPartitions(), DefaultAWSPartitionID, candidate); ok {
+			return candidate
+		}
+	}
+
+	return strings.Join(prefix, ".")
+}
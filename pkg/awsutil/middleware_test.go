@@ -0,0 +1,127 @@
+package awsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	credentialsv2 "github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	"github.com/aws/smithy-go/middleware"
+
+	liberrors "github.com/ekristen/libnuke/pkg/errors"
+)
+
+var errRequestSent = fmt.Errorf("request sent")
+
+type unsentHTTPClient struct{}
+
+func (unsentHTTPClient) Do(_ *http.Request) (*http.Response, error) {
+	return nil, errRequestSent
+}
+
+func testSkipRequestConfig(region string, global bool) awsv2.Config {
+	return awsv2.Config{
+		Region:           region,
+		Credentials:      credentialsv2.NewStaticCredentialsProvider("testing", "testing", ""),
+		HTTPClient:       unsentHTTPClient{},
+		RetryMaxAttempts: 1,
+		APIOptions:       []func(*middleware.Stack) error{addSkipRequest(global)},
+	}
+}
+
+func TestSkipRequest(t *testing.T) {
+	ctx := context.TODO()
+
+	cases := []struct {
+		name    string
+		global  bool
+		call    func(cfg awsv2.Config) error
+		skipped bool
+	}{
+		{
+			name:   "global service in global config",
+			global: true,
+			call: func(cfg awsv2.Config) error {
+				_, err := iam.NewFromConfig(cfg).ListRoles(ctx, &iam.ListRolesInput{})
+				return err
+			},
+		},
+		{
+			name: "global service in regional config",
+			call: func(cfg awsv2.Config) error {
+				_, err := cloudfront.NewFromConfig(cfg).ListDistributions(ctx, &cloudfront.ListDistributionsInput{})
+				return err
+			},
+			skipped: true,
+		},
+		{
+			name:   "regional service in global config",
+			global: true,
+			call: func(cfg awsv2.Config) error {
+				_, err := s3.NewFromConfig(cfg).ListBuckets(ctx, &s3.ListBucketsInput{})
+				return err
+			},
+			skipped: true,
+		},
+		{
+			name: "regional service in regional config",
+			call: func(cfg awsv2.Config) error {
+				_, err := s3control.NewFromConfig(cfg).ListAccessGrantsInstances(ctx,
+					&s3control.ListAccessGrantsInstancesInput{AccountId: awsv2.String("123456789012")})
+				return err
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call(testSkipRequestConfig("us-east-1", tc.global))
+
+			var skipErr liberrors.ErrSkipRequest
+			assert.Equal(t, tc.skipped, errors.As(err, &skipErr), "unexpected error: %v", err)
+			assert.Equal(t, !tc.skipped, errors.Is(err, errRequestSent), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestSkipRequest_MissingServiceInRegion(t *testing.T) {
+	// S3 is available in every region of the endpoints list, but not in a region that is not part of it
+	cfg := testSkipRequestConfig("us-east-1", false)
+	cfg.Region = "xx-invalid-1"
+
+	_, err := s3.NewFromConfig(cfg).ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+
+	var skipErr liberrors.ErrSkipRequest
+	assert.True(t, errors.As(err, &skipErr), "unexpected error: %v", err)
+}
+
+func TestEndpointsIDFromHost(t *testing.T) {
+	cases := []struct {
+		host   string
+		region string
+		want   string
+	}{
+		{host: "logs.us-east-1.amazonaws.com", region: "us-east-1", want: "logs"},
+		{host: "iam.amazonaws.com", region: "us-east-1", want: "iam"},
+		{host: "api.ecr.eu-west-1.amazonaws.com", region: "eu-west-1", want: "api.ecr"},
+		{host: "123456789012.s3-control.us-east-1.amazonaws.com", region: "us-east-1", want: "s3-control"},
+		{host: "bucket.s3.us-west-2.amazonaws.com", region: "us-west-2", want: "s3"},
+		{host: "unknown.us-east-1.amazonaws.com", region: "us-east-1", want: "unknown"},
+		{host: "localhost", region: "us-east-1", want: "localhost"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.host, func(t *testing.T) {
+			assert.Equal(t, tc.want, endpointsIDFromHost(tc.host, tc.region))
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package awsutil
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gotidy/ptr"
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+)
+
+// OrganizationAccount is a member account of an AWS Organization
+type OrganizationAccount struct {
+	ID     string
+	Name   string
+	Email  string
+	Status string
+}
+
+// MemberRoleArn returns the ARN of the role with the given name in the member account
+func (a *OrganizationAccount) MemberRoleArn(roleName string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", DefaultAWSPartitionID, a.ID, roleName)
+}
+
+// Organization provides access to the accounts of the AWS Organization the credentials belong to
+type Organization struct {
+	svc organizationsiface.OrganizationsAPI
+}
+
+// NewOrganization creates a new Organization using the given credentials, the credentials are expected to belong
+// to the management account or a delegated administrator of the organization.
+func NewOrganization(creds *Credentials) (*Organization, error) {
+	sess, err := creds.NewSession(GlobalRegionID, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create session in %s", GlobalRegionID)
+	}
+
+	return &Organization{
+		svc: organizations.New(sess),
+	}, nil
+}
+
+// Accounts returns the active accounts of the organization. If organizational units are given, only the accounts
+// that are within those organizational units, including any nested organizational units, are returned.
+func (o *Organization) Accounts(organizationalUnits []string) ([]*OrganizationAccount, error) {
+	var accounts []*OrganizationAccount
+
+	if len(organizationalUnits) == 0 {
+		if err := o.svc.ListAccountsPages(&organizations.ListAccountsInput{},
+			func(page *organizations.ListAccountsOutput, lastPage bool) bool {
+				accounts = append(accounts, newOrganizationAccounts(page.Accounts)...)
+				return !lastPage
+			}); err != nil {
+			return nil, errors.Wrap(err, "failed to list organization accounts")
+		}
+
+		return accounts, nil
+	}
+
+	for _, ou := range organizationalUnits {
+		ouAccounts, err := o.accountsForParent(ou)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range ouAccounts {
+			if slices.ContainsFunc(accounts, func(a *OrganizationAccount) bool { return a.ID == account.ID }) {
+				continue
+			}
+
+			accounts = append(accounts, account)
+		}
+	}
+
+	return accounts, nil
+}
+
+// accountsForParent recursively lists the active accounts under the parent organizational unit
+func (o *Organization) accountsForParent(parentID string) ([]*OrganizationAccount, error) {
+	var accounts []*OrganizationAccount
+
+	if err := o.svc.ListAccountsForParentPages(&organizations.ListAccountsForParentInput{
+		ParentId: ptr.String(parentID),
+	}, func(page *organizations.ListAccountsForParentOutput, lastPage bool) bool {
+		accounts = append(accounts, newOrganizationAccounts(page.Accounts)...)
+		return !lastPage
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list accounts for organizational unit %s", parentID)
+	}
+
+	var children []string
+	if err := o.svc.ListOrganizationalUnitsForParentPages(&organizations.ListOrganizationalUnitsForParentInput{
+		ParentId: ptr.String(parentID),
+	}, func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
+		for _, ou := range page.OrganizationalUnits {
+			children = append(children, ptr.ToString(ou.Id))
+		}
+		return !lastPage
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list organizational units for %s", parentID)
+	}
+
+	for _, child := range children {
+		childAccounts, err := o.accountsForParent(child)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, childAccounts...)
+	}
+
+	return accounts, nil
+}
+
+// newOrganizationAccounts converts the SDK accounts, only active accounts are returned as suspended accounts
+// cannot be accessed
+func newOrganizationAccounts(accounts []*organizations.Account) []*OrganizationAccount {
+	var converted []*OrganizationAccount
+	for _, account := range accounts {
+		if ptr.ToString(account.Status) != organizations.AccountStatusActive {
+			continue
+		}
+
+		converted = append(converted, &OrganizationAccount{
+			ID:     ptr.ToString(account.Id),
+			Name:   ptr.ToString(account.Name),
+			Email:  ptr.ToString(account.Email),
+			Status: ptr.ToString(account.Status),
+		})
+	}
+
+	return converted
+}
+
+// Guard returns an error that explains why the account must not be nuked, if it is the management account or a
+// delegated administrator of the organization, or if it is within one of the protected organizational units. Accounts
+// that do not belong to an organization pass. The account is refused as well when this cannot be verified.
+func (o *Organization) Guard(accountID string, protectedOUs []string) error {
+	orgOutput, err := o.svc.DescribeOrganization(&organizations.DescribeOrganizationInput{})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == organizations.ErrCodeAWSOrganizationsNotInUseException {
+			return nil
+		}
+
+		return errors.Wrap(err, "unable to verify that the account is not the management account of its organization")
+	}
+
+	org := orgOutput.Organization
+	if ptr.ToString(org.MasterAccountId) == accountID {
+		return fmt.Errorf("account %s is the management account of the organization %s, which controls every "+
+			"account of the organization. Aborting", accountID, ptr.ToString(org.Id))
+	}
+
+	var services []string
+	if err := o.svc.ListDelegatedServicesForAccountPages(&organizations.ListDelegatedServicesForAccountInput{
+		AccountId: ptr.String(accountID),
+	}, func(page *organizations.ListDelegatedServicesForAccountOutput, lastPage bool) bool {
+		for _, service := range page.DelegatedServices {
+			services = append(services, ptr.ToString(service.ServicePrincipal))
+		}
+		return !lastPage
+	}); err != nil {
+		var awsErr awserr.Error
+		if !errors.As(err, &awsErr) || awsErr.Code() != organizations.ErrCodeAccountNotRegisteredException {
+			return errors.Wrap(err, "unable to verify that the account is not a delegated administrator of "+
+				"its organization, the credentials of the management account are required")
+		}
+	}
+
+	if len(services) > 0 {
+		return fmt.Errorf("account %s is a delegated administrator of the organization %s for %s, which manage "+
+			"other accounts of the organization. Aborting", accountID, ptr.ToString(org.Id), strings.Join(services, ", "))
+	}
+
+	if len(protectedOUs) == 0 {
+		return nil
+	}
+
+	for childID := accountID; ; {
+		parentsOutput, err := o.svc.ListParents(&organizations.ListParentsInput{
+			ChildId: ptr.String(childID),
+		})
+		if err != nil {
+			return errors.Wrap(err, "unable to verify that the account is not in a protected organizational unit, "+
+				"the credentials of the management account are required")
+		}
+
+		if len(parentsOutput.Parents) == 0 {
+			return nil
+		}
+
+		parent := parentsOutput.Parents[0]
+		if slices.Contains(protectedOUs, ptr.ToString(parent.Id)) {
+			return fmt.Errorf("account %s is within the organizational unit %s, which is protected by the "+
+				"organization-guard of the configuration. Aborting", accountID, ptr.ToString(parent.Id))
+		}
+
+		if ptr.ToString(parent.Type) == organizations.ParentTypeRoot {
+			return nil
+		}
+
+		childID = ptr.ToString(parent.Id)
+	}
+}
@@ -0,0 +1,194 @@
+package awsutil
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/organizations"
+
+	"github.com/ekristen/aws-nuke/v3/mocks/mock_organizationsiface"
+)
+
+func Test_Mock_Organization_Accounts(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mock_organizationsiface.NewMockOrganizationsAPI(ctrl)
+
+	mockSvc.EXPECT().ListAccountsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ *organizations.ListAccountsInput, fn func(*organizations.ListAccountsOutput, bool) bool) error {
+			fn(&organizations.ListAccountsOutput{
+				Accounts: []*organizations.Account{
+					{Id: ptr.String("111111111111"), Status: ptr.String(organizations.AccountStatusActive)},
+					{Id: ptr.String("222222222222"), Status: ptr.String(organizations.AccountStatusSuspended)},
+				},
+			}, true)
+			return nil
+		})
+
+	org := &Organization{svc: mockSvc}
+
+	accounts, err := org.Accounts(nil)
+	a.NoError(err)
+	a.Len(accounts, 1)
+	a.Equal("111111111111", accounts[0].ID)
+	a.Equal("arn:aws:iam::111111111111:role/OrganizationAccountAccessRole",
+		accounts[0].MemberRoleArn("OrganizationAccountAccessRole"))
+}
+
+func Test_Mock_Organization_AccountsForOrganizationalUnits(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mock_organizationsiface.NewMockOrganizationsAPI(ctrl)
+
+	accountsByParent := map[string][]*organizations.Account{
+		"ou-root-parent": {
+			{Id: ptr.String("111111111111"), Status: ptr.String(organizations.AccountStatusActive)},
+		},
+		"ou-root-child": {
+			{Id: ptr.String("222222222222"), Status: ptr.String(organizations.AccountStatusActive)},
+		},
+	}
+
+	childrenByParent := map[string][]*organizations.OrganizationalUnit{
+		"ou-root-parent": {{Id: ptr.String("ou-root-child")}},
+	}
+
+	mockSvc.EXPECT().ListAccountsForParentPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(in *organizations.ListAccountsForParentInput,
+			fn func(*organizations.ListAccountsForParentOutput, bool) bool) error {
+			fn(&organizations.ListAccountsForParentOutput{Accounts: accountsByParent[*in.ParentId]}, true)
+			return nil
+		}).Times(3)
+
+	mockSvc.EXPECT().ListOrganizationalUnitsForParentPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(in *organizations.ListOrganizationalUnitsForParentInput,
+			fn func(*organizations.ListOrganizationalUnitsForParentOutput, bool) bool) error {
+			fn(&organizations.ListOrganizationalUnitsForParentOutput{
+				OrganizationalUnits: childrenByParent[*in.ParentId],
+			}, true)
+			return nil
+		}).Times(3)
+
+	org := &Organization{svc: mockSvc}
+
+	// Note: the child is also listed directly to ensure accounts are only returned once
+	accounts, err := org.Accounts([]string{"ou-root-parent", "ou-root-child"})
+	a.NoError(err)
+	a.Len(accounts, 2)
+	a.Equal("111111111111", accounts[0].ID)
+	a.Equal("222222222222", accounts[1].ID)
+}
+
+func Test_Mock_Organization_Guard(t *testing.T) {
+	notRegistered := awserr.New(organizations.ErrCodeAccountNotRegisteredException, "not registered", nil)
+
+	cases := []struct {
+		name         string
+		accountID    string
+		protectedOUs []string
+		describeErr  error
+		services     []string
+		error        string
+	}{
+		{
+			name:        "no-organization",
+			accountID:   "111111111111",
+			describeErr: awserr.New(organizations.ErrCodeAWSOrganizationsNotInUseException, "not in use", nil),
+		},
+		{
+			name:        "describe-denied",
+			accountID:   "111111111111",
+			describeErr: awserr.New(organizations.ErrCodeAccessDeniedException, "denied", nil),
+			error:       "unable to verify that the account is not the management account",
+		},
+		{
+			name:      "management-account",
+			accountID: "999999999999",
+			error:     "account 999999999999 is the management account of the organization o-example",
+		},
+		{
+			name:      "delegated-administrator",
+			accountID: "222222222222",
+			services:  []string{"securityhub.amazonaws.com", "guardduty.amazonaws.com"},
+			error: "account 222222222222 is a delegated administrator of the organization o-example for " +
+				"securityhub.amazonaws.com, guardduty.amazonaws.com",
+		},
+		{
+			name:      "member-account",
+			accountID: "111111111111",
+		},
+		{
+			name:         "protected-nested-organizational-unit",
+			accountID:    "111111111111",
+			protectedOUs: []string{"ou-root-parent"},
+			error:        "account 111111111111 is within the organizational unit ou-root-parent",
+		},
+		{
+			name:         "unprotected-organizational-unit",
+			accountID:    "111111111111",
+			protectedOUs: []string{"ou-root-other"},
+		},
+	}
+
+	parents := map[string]*organizations.Parent{
+		"111111111111":   {Id: ptr.String("ou-root-child"), Type: ptr.String(organizations.ParentTypeOrganizationalUnit)},
+		"ou-root-child":  {Id: ptr.String("ou-root-parent"), Type: ptr.String(organizations.ParentTypeOrganizationalUnit)},
+		"ou-root-parent": {Id: ptr.String("r-root"), Type: ptr.String(organizations.ParentTypeRoot)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockSvc := mock_organizationsiface.NewMockOrganizationsAPI(ctrl)
+
+			mockSvc.EXPECT().DescribeOrganization(gomock.Any()).Return(&organizations.DescribeOrganizationOutput{
+				Organization: &organizations.Organization{
+					Id:              ptr.String("o-example"),
+					MasterAccountId: ptr.String("999999999999"),
+				},
+			}, tc.describeErr)
+
+			mockSvc.EXPECT().ListDelegatedServicesForAccountPages(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ *organizations.ListDelegatedServicesForAccountInput,
+					fn func(*organizations.ListDelegatedServicesForAccountOutput, bool) bool) error {
+					if len(tc.services) == 0 {
+						return notRegistered
+					}
+
+					page := &organizations.ListDelegatedServicesForAccountOutput{}
+					for _, service := range tc.services {
+						page.DelegatedServices = append(page.DelegatedServices,
+							&organizations.DelegatedService{ServicePrincipal: ptr.String(service)})
+					}
+					fn(page, true)
+					return nil
+				}).AnyTimes()
+
+			mockSvc.EXPECT().ListParents(gomock.Any()).DoAndReturn(
+				func(input *organizations.ListParentsInput) (*organizations.ListParentsOutput, error) {
+					return &organizations.ListParentsOutput{
+						Parents: []*organizations.Parent{parents[ptr.ToString(input.ChildId)]},
+					}, nil
+				}).AnyTimes()
+
+			org := &Organization{svc: mockSvc}
+
+			err := org.Guard(tc.accountID, tc.protectedOUs)
+			if tc.error == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.error)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package awsutil
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+)
+
+const (
+	// signingID is the ID of the middleware that signs every attempt of a request in the SDK v2 clients.
+	signingID = "Signing"
+
+	// rateLimitThrottleFactor is the factor the rate is multiplied with when a request is throttled.
+	rateLimitThrottleFactor = 0.5
+
+	// rateLimitRecoveryFactor is the share of the configured rate that the rate is raised by when a request succeeds.
+	rateLimitRecoveryFactor = 0.02
+
+	// rateLimitMinimumFactor is the share of the configured rate that the rate is never lowered below.
+	rateLimitMinimumFactor = 0.05
+)
+
+// RateLimiter is a token bucket for the requests to one service in one region. The bucket holds up to one second of
+// requests. When a request is throttled the rate is halved, and while requests succeed it is raised back up to the
+// configured rate.
+type RateLimiter struct {
+	lock sync.Mutex
+
+	max    float64
+	min    float64
+	rate   float64
+	tokens float64
+	last   time.Time
+
+	now func() time.Time
+}
+
+// NewRateLimiter returns a rate limiter that allows the given number of requests per second.
+func NewRateLimiter(rate float64) *RateLimiter {
+	return &RateLimiter{
+		max:    rate,
+		min:    rate * rateLimitMinimumFactor,
+		rate:   rate,
+		tokens: math.Max(rate, 1),
+		now:    time.Now,
+	}
+}
+
+// Rate returns the current number of requests per second.
+func (l *RateLimiter) Rate() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.rate
+}
+
+// Wait blocks until the next request may be sent, or the context is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token from the bucket and returns how long to wait until the token is available. The tokens are
+// allowed to go negative, which reserves the tokens of requests that are already waiting.
+func (l *RateLimiter) reserve() time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.tokens+now.Sub(l.last).Seconds()*l.rate, math.Max(l.rate, 1))
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Throttled lowers the rate after a request was throttled.
+func (l *RateLimiter) Throttled() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.rate = math.Max(l.rate*rateLimitThrottleFactor, l.min)
+}
+
+// Succeeded raises the rate after a request succeeded, up to the configured rate.
+func (l *RateLimiter) Succeeded() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.rate = math.Min(l.rate+l.max*rateLimitRecoveryFactor, l.max)
+}
+
+// rateLimiters holds the rate limiters of an account, per service and region. They are created the first time a
+// service is called in a region.
+type rateLimiters struct {
+	lock     sync.Mutex
+	limiters map[string]*RateLimiter
+}
+
+// get returns the rate limiter of the service in the region, or nil when the service is not rate limited.
+func (r *rateLimiters) get(limits config.RateLimits, service, region string) *RateLimiter {
+	rate := limits.Get(service)
+	if rate <= 0 {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := service + "/" + region
+
+	if r.limiters == nil {
+		r.limiters = make(map[string]*RateLimiter)
+	}
+
+	limiter, ok := r.limiters[key]
+	if !ok {
+		log.Debugf("rate limiting %s in %s to %g requests per second", service, region, rate)
+
+		limiter = NewRateLimiter(rate)
+		r.limiters[key] = limiter
+	}
+
+	return limiter
+}
+
+// rateLimiter returns the rate limiter of the service in the region for the credentials, or nil when the service is
+// not rate limited.
+func (c *Credentials) rateLimiter(service, region string) *RateLimiter {
+	return c.rateLimiters.get(c.RateLimits, service, region)
+}
+
+// addRateLimitHandlers adds the handlers that rate limit the requests of an SDK v1 session. Every attempt waits for the
+// rate limiter, so retries are rate limited as well.
+func (c *Credentials) addRateLimitHandlers(handlers *request.Handlers) {
+	handlers.Sign.PushFront(func(r *request.Request) {
+		limiter := c.rateLimiter(endpointsID(r), aws.StringValue(r.Config.Region))
+		if limiter == nil {
+			return
+		}
+
+		if err := limiter.Wait(r.Context()); err != nil {
+			r.Error = err
+		}
+	})
+
+	handlers.Retry.PushFront(func(r *request.Request) {
+		if !isThrottleError(r) {
+			return
+		}
+
+		if limiter := c.rateLimiter(endpointsID(r), aws.StringValue(r.Config.Region)); limiter != nil {
+			limiter.Throttled()
+			log.Debugf("%s in %s is throttled, lowered the rate limit to %g requests per second",
+				endpointsID(r), aws.StringValue(r.Config.Region), limiter.Rate())
+		}
+	})
+
+	handlers.Complete.PushBack(func(r *request.Request) {
+		if r.Error != nil {
+			return
+		}
+
+		if limiter := c.rateLimiter(endpointsID(r), aws.StringValue(r.Config.Region)); limiter != nil {
+			limiter.Succeeded()
+		}
+	})
+}
+
+// rateLimit is the SDK v2 equivalent of the rate limit handlers of SDK v1 sessions. It runs right before the request
+// is signed, which is after the retry middleware and after the endpoint is resolved, so every attempt waits for the
+// rate limiter and is taken into account.
+type rateLimit struct {
+	credentials *Credentials
+}
+
+func (rateLimit) ID() string {
+	return "aws-nuke::rateLimit"
+}
+
+func (m rateLimit) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, md middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*smithyhttp.Request)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	region := awsmiddleware.GetRegion(ctx)
+	service := endpointsIDFromHost(req.URL.Hostname(), region)
+
+	limiter := m.credentials.rateLimiter(service, region)
+	if limiter == nil {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	if err := limiter.Wait(ctx); err != nil {
+		return out, md, err
+	}
+
+	out, md, err = next.HandleFinalize(ctx, in)
+
+	switch {
+	case err == nil:
+		limiter.Succeeded()
+	case isThrottleErrorV2(err):
+		limiter.Throttled()
+		log.Debugf("%s in %s is throttled, lowered the rate limit to %g requests per second",
+			service, region, limiter.Rate())
+	}
+
+	return out, md, err
+}
+
+// addRateLimit returns the API option that adds the rateLimit middleware right before the request is signed.
+func (c *Credentials) addRateLimit() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		m := rateLimit{credentials: c}
+
+		if _, ok := stack.Finalize.Get(signingID); ok {
+			return stack.Finalize.Insert(m, signingID, middleware.Before)
+		}
+
+		return stack.Finalize.Add(m, middleware.After)
+	}
+}
+
+// isThrottleError returns true when the error of the SDK v1 request is one of the throttle errors that the SDK knows
+// of, or the response status is 429 Too Many Requests.
+func isThrottleError(r *request.Request) bool {
+	return request.IsErrorThrottle(r.Error) ||
+		(r.HTTPResponse != nil && r.HTTPResponse.StatusCode == http.StatusTooManyRequests)
+}
+
+// isThrottleErrorV2 returns true when the SDK v2 error is one of the throttle errors that the SDK knows of, or the
+// response status is 429 Too Many Requests.
+func isThrottleErrorV2(err error) bool {
+	if retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == awsv2.TrueTernary {
+		return true
+	}
+
+	var respErr interface{ HTTPStatusCode() int }
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusTooManyRequests
+}
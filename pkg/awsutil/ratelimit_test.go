@@ -0,0 +1,159 @@
+package awsutil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	credentialsv2 "github.com/aws/aws-sdk-go-v2/credentials"
+	iamv2 "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+)
+
+const (
+	testThrottlingResponse = `<ErrorResponse><Error><Type>Sender</Type><Code>Throttling</Code>` +
+		`<Message>Rate exceeded</Message></Error><RequestId>test</RequestId></ErrorResponse>`
+	testListRolesResponse = `<ListRolesResponse><ListRolesResult><Roles></Roles><IsTruncated>false</IsTruncated>` +
+		`</ListRolesResult></ListRolesResponse>`
+)
+
+// responseHTTPClient responds to every request with the status code and body.
+type responseHTTPClient struct {
+	statusCode int
+	body       string
+}
+
+func (c *responseHTTPClient) Do(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: c.statusCode,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    r,
+	}, nil
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	limiter := NewRateLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	// the bucket starts with one second of requests
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+	assert.Equal(t, time.Second, limiter.reserve())
+
+	// the reserved tokens are paid back before new tokens are available
+	now = now.Add(time.Second)
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+}
+
+func TestRateLimiter_Adaptive(t *testing.T) {
+	limiter := NewRateLimiter(10)
+
+	limiter.Throttled()
+	assert.Equal(t, 5.0, limiter.Rate())
+
+	for i := 0; i < 10; i++ {
+		limiter.Throttled()
+	}
+	assert.Equal(t, 0.5, limiter.Rate(), "the rate is never lowered below its minimum")
+
+	limiter.Succeeded()
+	assert.InDelta(t, 0.7, limiter.Rate(), 0.0001)
+
+	for i := 0; i < 100; i++ {
+		limiter.Succeeded()
+	}
+	assert.Equal(t, 10.0, limiter.Rate(), "the rate is never raised above the configured rate")
+}
+
+func TestCredentials_RateLimiter(t *testing.T) {
+	c := &Credentials{RateLimits: config.RateLimits{Default: 10, Services: map[string]float64{"iam": 0}}}
+
+	assert.Nil(t, c.rateLimiter("iam", "us-east-1"))
+	assert.NotNil(t, c.rateLimiter("ec2", "us-east-1"))
+	assert.Same(t, c.rateLimiter("ec2", "us-east-1"), c.rateLimiter("ec2", "us-east-1"))
+	assert.NotSame(t, c.rateLimiter("ec2", "us-east-1"), c.rateLimiter("ec2", "eu-west-1"))
+
+	assumed := c.AssumeRole("arn:aws:iam::123456789012:role/test", "", "")
+	assert.NotSame(t, c.rateLimiter("ec2", "us-east-1"), assumed.rateLimiter("ec2", "us-east-1"),
+		"an assumed role is another account and must not share the rate limiters")
+}
+
+func TestRateLimitHandlers(t *testing.T) {
+	c := &Credentials{RateLimits: config.RateLimits{Services: map[string]float64{"iam": 100}}}
+	httpClient := &responseHTTPClient{statusCode: http.StatusBadRequest, body: testThrottlingResponse}
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("testing", "testing", ""),
+		MaxRetries:  aws.Int(0),
+	}))
+	sess.Config.HTTPClient = &http.Client{Transport: roundTripper{httpClient}}
+	c.addRateLimitHandlers(&sess.Handlers)
+
+	_, err := iam.New(sess).ListRoles(&iam.ListRolesInput{})
+	assert.Error(t, err)
+	assert.Equal(t, 50.0, c.rateLimiter("iam", "us-east-1").Rate())
+
+	httpClient.statusCode, httpClient.body = http.StatusOK, testListRolesResponse
+
+	_, err = iam.New(sess).ListRoles(&iam.ListRolesInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, 52.0, c.rateLimiter("iam", "us-east-1").Rate())
+}
+
+func TestRateLimit(t *testing.T) {
+	c := &Credentials{RateLimits: config.RateLimits{Services: map[string]float64{"iam": 100}}}
+	httpClient := &responseHTTPClient{statusCode: http.StatusBadRequest, body: testThrottlingResponse}
+
+	cfg := awsv2.Config{
+		Region:           "us-east-1",
+		Credentials:      credentialsv2.NewStaticCredentialsProvider("testing", "testing", ""),
+		HTTPClient:       httpClient,
+		RetryMaxAttempts: 1,
+		APIOptions:       []func(*middleware.Stack) error{c.addRateLimit()},
+	}
+
+	_, err := iamv2.NewFromConfig(cfg).ListRoles(context.TODO(), &iamv2.ListRolesInput{})
+	assert.Error(t, err)
+	assert.Equal(t, 50.0, c.rateLimiter("iam", "us-east-1").Rate())
+
+	httpClient.statusCode, httpClient.body = http.StatusOK, testListRolesResponse
+
+	_, err = iamv2.NewFromConfig(cfg).ListRoles(context.TODO(), &iamv2.ListRolesInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, 52.0, c.rateLimiter("iam", "us-east-1").Rate())
+}
+
+// roundTripper adapts the response client to the transport of an SDK v1 HTTP client.
+type roundTripper struct {
+	client *responseHTTPClient
+}
+
+func (t roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return t.client.Do(r)
+}
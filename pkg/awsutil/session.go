@@ -8,8 +8,10 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 
 	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
 	credentialsv2 "github.com/aws/aws-sdk-go-v2/credentials"
@@ -23,6 +25,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3control"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/config"
+	"github.com/ekristen/aws-nuke/v3/pkg/metrics"
 	liberrors "github.com/ekristen/libnuke/pkg/errors"
 )
 
@@ -51,8 +54,42 @@ type Credentials struct {
 	Credentials *credentials.Credentials
 
 	CustomEndpoints config.CustomEndpoints
+	RateLimits      config.RateLimits
+	Metrics         *metrics.Metrics
+	Tracer          trace.Tracer
 	session         *session.Session
 	cfg             *awsv2.Config
+
+	// lock guards the lazy creation of the root session and config, credentials can be shared between accounts that
+	// are nuked concurrently when they are the source of an assumed role.
+	lock sync.Mutex
+
+	// traceParents holds the contexts of the phases of the run by region, see SetTraceParent.
+	traceParents sync.Map
+
+	// rateLimiters holds the rate limiters of the services that are called with the credentials, an assumed role is
+	// another account and gets rate limiters of its own.
+	rateLimiters rateLimiters
+
+	// source is the identity used to assume AssumeRoleArn when the role is chained off of other credentials instead
+	// of a profile or static keys, see AssumeRole.
+	source *Credentials
+}
+
+// AssumeRole returns new credentials that assume the given role using the current credentials, including any role
+// they assume themselves, as the source identity. This is used to chain into other accounts, for example into the
+// member accounts of an organization from the management account.
+func (c *Credentials) AssumeRole(roleArn, sessionName, externalID string) *Credentials {
+	return &Credentials{
+		AssumeRoleArn:   roleArn,
+		RoleSessionName: sessionName,
+		ExternalID:      externalID,
+		CustomEndpoints: c.CustomEndpoints,
+		RateLimits:      c.RateLimits,
+		Metrics:         c.Metrics,
+		Tracer:          c.Tracer,
+		source:          c,
+	}
 }
 
 func (c *Credentials) HasProfile() bool {
@@ -80,8 +117,32 @@ func (c *Credentials) Validate() error {
 }
 
 // FUTURE(187): when all services are migrated to SDK v2, remove usage of
-// session.Session throughout
+// session.Session throughout. The migration is not complete, only the
+// CloudFront resources are migrated so far.
 func (c *Credentials) rootSession() (*session.Session, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.session == nil && c.source != nil {
+		sourceSession, err := c.source.rootSession()
+		if err != nil {
+			return nil, err
+		}
+
+		sess := sourceSession.Copy()
+		sess.Config.Credentials = stscreds.NewCredentials(sourceSession, c.AssumeRoleArn, func(p *stscreds.AssumeRoleProvider) {
+			if c.RoleSessionName != "" {
+				p.RoleSessionName = c.RoleSessionName
+			}
+
+			if c.ExternalID != "" {
+				p.ExternalID = aws.String(c.ExternalID)
+			}
+		})
+
+		c.session = sess
+	}
+
 	if c.session == nil {
 		var opts session.Options
 
@@ -169,6 +230,7 @@ func (c *Credentials) NewSession(region, serviceType string) (*session.Session,
 	log.Debugf("creating new session in %s for %s", region, serviceType)
 
 	global := false
+	owner := region
 
 	if region == GlobalRegionID {
 		region = DefaultRegionID
@@ -223,6 +285,10 @@ func (c *Credentials) NewSession(region, serviceType string) (*session.Session,
 		log.Tracef("received AWS response:\n%s", DumpResponse(r.HTTPResponse))
 	})
 
+	c.addRateLimitHandlers(&sess.Handlers)
+	c.addMetricsHandlers(&sess.Handlers)
+	c.addTracingHandlers(&sess.Handlers, owner)
+
 	if !isCustom {
 		sess.Handlers.Validate.PushFront(skipMissingServiceInRegionHandler)
 		sess.Handlers.Validate.PushFront(skipGlobalHandler(global))
@@ -231,72 +297,91 @@ func (c *Credentials) NewSession(region, serviceType string) (*session.Session,
 }
 
 func skipMissingServiceInRegionHandler(r *request.Request) {
-	region := *r.Config.Region
+	if err := skipMissingServiceInRegion(r.ClientInfo.ServiceName, *r.Config.Region); err != nil {
+		r.Error = err
+	}
+}
+
+func skipGlobalHandler(global bool) func(r *request.Request) {
+	return func(r *request.Request) {
+		if err := skipGlobal(endpointsID(r), r.HTTPRequest.URL.Hostname(), global); err != nil {
+			r.Error = err
+		}
+	}
+}
+
+// endpointsID returns the endpoints ID of the service of the request.
+func endpointsID(r *request.Request) string {
 	service := r.ClientInfo.ServiceName
+	if service == s3control.ServiceName {
+		service = s3control.EndpointsID
+		// Rewrite S3 Control ServiceName to proper EndpointsID
+		// https://github.com/rebuy-de/aws-nuke/issues/708
+	}
+	if service == iottwinmaker.ServiceName {
+		service = iottwinmaker.EndpointsID
+		// IoTTwinMaker have two endpoints, must point on "api" one
+		// https://docs.aws.amazon.com/iot-twinmaker/latest/guide/endpionts-and-quotas.html
+	}
 
+	return service
+}
+
+// skipMissingServiceInRegion returns ErrSkipRequest when the service is not available in the region.
+func skipMissingServiceInRegion(service, region string) error {
 	rs, ok := endpoints.RegionsForService(endpoints.DefaultPartitions(), DefaultAWSPartitionID, service)
 	if !ok {
 		// This means that the service does not exist and this shouldn't be handled here.
-		return
+		return nil
 	}
 
 	if len(rs) == 0 {
 		// Avoid to throw an error on global services.
-		return
+		return nil
 	}
 
-	_, ok = rs[region]
-	if !ok {
-		r.Error = liberrors.ErrSkipRequest(fmt.Sprintf(
+	if _, ok = rs[region]; !ok {
+		return liberrors.ErrSkipRequest(fmt.Sprintf(
 			"service '%s' is not available in region '%s'",
 			service, region))
 	}
+
+	return nil
 }
 
-func skipGlobalHandler(global bool) func(r *request.Request) {
-	return func(r *request.Request) {
-		service := r.ClientInfo.ServiceName
-		if service == s3control.ServiceName {
-			service = s3control.EndpointsID
-			// Rewrite S3 Control ServiceName to proper EndpointsID
-			// https://github.com/rebuy-de/aws-nuke/issues/708
-		}
-		if service == iottwinmaker.ServiceName {
-			service = iottwinmaker.EndpointsID
-			// IoTTwinMaker have two endpoints, must point on "api" one
-			// https://docs.aws.amazon.com/iot-twinmaker/latest/guide/endpionts-and-quotas.html
-		}
-		rs, ok := endpoints.RegionsForService(endpoints.DefaultPartitions(), DefaultAWSPartitionID, service)
-		if !ok {
-			// This means that the service does not exist in the endpoints list.
-			if global {
-				r.Error = liberrors.ErrSkipRequest(
-					fmt.Sprintf("service '%s' is was not found in the endpoint list; assuming it is not global",
-						service))
-			} else {
-				host := r.HTTPRequest.URL.Hostname()
-				_, err := net.LookupHost(host)
-				if err != nil {
-					log.Debug(err)
-					r.Error = liberrors.ErrUnknownEndpoint(
-						fmt.Sprintf("DNS lookup failed for %s; assuming it does not exist in this region", host))
-				}
-			}
-			return
+// skipGlobal returns ErrSkipRequest when a global service is called from a regional session or a regional service is
+// called from the global session. A service that is not in the endpoints list is assumed to be regional, and is
+// skipped when the DNS lookup of its host fails.
+func skipGlobal(service, host string, global bool) error {
+	rs, ok := endpoints.RegionsForService(endpoints.DefaultPartitions(), DefaultAWSPartitionID, service)
+	if !ok {
+		// This means that the service does not exist in the endpoints list.
+		if global {
+			return liberrors.ErrSkipRequest(
+				fmt.Sprintf("service '%s' is was not found in the endpoint list; assuming it is not global",
+					service))
 		}
 
-		if len(rs) == 0 && !global {
-			r.Error = liberrors.ErrSkipRequest(
-				fmt.Sprintf("service '%s' is global, but the session is not", service))
-			return
+		if _, err := net.LookupHost(host); err != nil {
+			log.Debug(err)
+			return liberrors.ErrUnknownEndpoint(
+				fmt.Sprintf("DNS lookup failed for %s; assuming it does not exist in this region", host))
 		}
 
-		if (len(rs) > 0 && global) && service != "sts" {
-			r.Error = liberrors.ErrSkipRequest(
-				fmt.Sprintf("service '%s' is not global, but the session is", service))
-			return
-		}
+		return nil
 	}
+
+	if len(rs) == 0 && !global {
+		return liberrors.ErrSkipRequest(
+			fmt.Sprintf("service '%s' is global, but the session is not", service))
+	}
+
+	if (len(rs) > 0 && global) && service != "sts" {
+		return liberrors.ErrSkipRequest(
+			fmt.Sprintf("service '%s' is not global, but the session is", service))
+	}
+
+	return nil
 }
 
 // SDK v2 does not directly expose an environment creds provider since that
@@ -0,0 +1,215 @@
+package awsutil
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedCallKey is the context key of the tracedCall of an AWS API call.
+type tracedCallKey struct{}
+
+// tracedCall is an AWS API call whose span is recorded once the call is complete, including its retries and the time
+// spent waiting for the rate limiter.
+type tracedCall struct {
+	start   time.Time
+	service string
+
+	// sent is whether any attempt of the call was sent, calls that are skipped before that have no span.
+	sent bool
+}
+
+// recordCall records the span of the call as a child of the span in the context.
+func (c *Credentials) recordCall(
+	ctx context.Context, call *tracedCall, operation, region, requestID string, retries int, err error,
+) {
+	if !call.sent {
+		return
+	}
+
+	_, span := c.Tracer.Start(ctx, call.service+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(call.start),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("aws-api"),
+			semconv.RPCService(call.service),
+			semconv.RPCMethod(operation),
+			semconv.CloudRegion(region),
+			attribute.Int("aws.retries", retries),
+		))
+
+	if requestID != "" {
+		span.SetAttributes(semconv.AWSRequestID(requestID))
+	}
+
+	if err != nil {
+		if code := errorCode(err); code != "" {
+			span.SetAttributes(semconv.ErrorTypeKey.String(code))
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// SetTraceParent sets the context of the phase of the run whose span is the parent of the spans of the calls of the
+// region that are made without a context, an empty region sets it for the calls of every region that has none of its
+// own. Most resources do not pass their context on to the SDK v1 calls, whose spans would otherwise be traces of their
+// own.
+func (c *Credentials) SetTraceParent(ctx context.Context, region string) {
+	c.traceParents.Store(region, ctx)
+}
+
+// ClearTraceParent removes the context of the phase of the run of the region, see SetTraceParent.
+func (c *Credentials) ClearTraceParent(region string) {
+	c.traceParents.Delete(region)
+}
+
+// traceParent returns the context of the call when it has a span, and otherwise the context of the phase of the run
+// that the calls of the region are made in, see SetTraceParent.
+func (c *Credentials) traceParent(ctx context.Context, region string) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	for _, key := range []string{region, ""} {
+		if parent, ok := c.traceParents.Load(key); ok {
+			return parent.(context.Context)
+		}
+	}
+
+	return ctx
+}
+
+// errorCode returns the error code of the AWS API of an error of either SDK.
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code()
+	}
+
+	return ""
+}
+
+// addTracingHandlers adds the handlers that record a span for every call of an SDK v1 session of the region, when the
+// calls are traced. The calls that are made without a context get the span of the phase of the region as parent.
+func (c *Credentials) addTracingHandlers(handlers *request.Handlers, region string) {
+	if c.Tracer == nil {
+		return
+	}
+
+	handlers.Validate.PushFront(func(r *request.Request) {
+		r.SetContext(context.WithValue(r.Context(), tracedCallKey{}, &tracedCall{start: time.Now()}))
+	})
+
+	handlers.Send.PushFront(func(r *request.Request) {
+		if call, ok := r.Context().Value(tracedCallKey{}).(*tracedCall); ok {
+			call.sent = true
+		}
+	})
+
+	handlers.Complete.PushBack(func(r *request.Request) {
+		call, ok := r.Context().Value(tracedCallKey{}).(*tracedCall)
+		if !ok {
+			return
+		}
+
+		call.service = endpointsID(r)
+		c.recordCall(c.traceParent(r.Context(), region), call, r.Operation.Name, aws.StringValue(r.Config.Region),
+			r.RequestID, r.RetryCount, r.Error)
+	})
+}
+
+// traceCall is the SDK v2 equivalent of the tracing handlers of SDK v1 sessions. It wraps the whole call, including
+// its retries, and records the span once the call is complete.
+type traceCall struct {
+	creds  *Credentials
+	region string
+}
+
+func (traceCall) ID() string {
+	return "aws-nuke::traceCall"
+}
+
+func (m traceCall) HandleInitialize(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (
+	out middleware.InitializeOutput, md middleware.Metadata, err error,
+) {
+	call := &tracedCall{start: time.Now()}
+	out, md, err = next.HandleInitialize(context.WithValue(ctx, tracedCallKey{}, call), in)
+
+	var retries int
+	if results, ok := retry.GetAttemptResults(md); ok && len(results.Results) > 0 {
+		retries = len(results.Results) - 1
+	}
+
+	requestID, _ := awsmiddleware.GetRequestIDMetadata(md)
+	m.creds.recordCall(m.creds.traceParent(ctx, m.region), call, awsmiddleware.GetOperationName(ctx),
+		awsmiddleware.GetRegion(ctx), requestID, retries, err)
+
+	return out, md, err
+}
+
+// traceCallSent marks the call as sent right before an attempt is signed, after the endpoint is resolved and the
+// SkipRequest middleware had its say.
+type traceCallSent struct{}
+
+func (traceCallSent) ID() string {
+	return "aws-nuke::traceCallSent"
+}
+
+func (traceCallSent) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	middleware.FinalizeOutput, middleware.Metadata, error,
+) {
+	call, ok := ctx.Value(tracedCallKey{}).(*tracedCall)
+	req, isHTTP := in.Request.(*smithyhttp.Request)
+	if ok && isHTTP {
+		call.sent = true
+		call.service = endpointsIDFromHost(req.URL.Hostname(), awsmiddleware.GetRegion(ctx))
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
+
+// addTraceCall returns the API option that adds the traceCall middlewares for the calls of the region, or does nothing
+// when the calls are not traced.
+func (c *Credentials) addTraceCall(region string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if c.Tracer == nil {
+			return nil
+		}
+
+		if err := stack.Initialize.Add(traceCall{creds: c, region: region}, middleware.After); err != nil {
+			return err
+		}
+
+		if _, ok := stack.Finalize.Get(signingID); ok {
+			return stack.Finalize.Insert(traceCallSent{}, signingID, middleware.Before)
+		}
+
+		return stack.Finalize.Add(traceCallSent{}, middleware.After)
+	}
+}
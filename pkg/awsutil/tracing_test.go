@@ -0,0 +1,189 @@
+package awsutil
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	credentialsv2 "github.com/aws/aws-sdk-go-v2/credentials"
+	iamv2 "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func newTestTracer() (*Credentials, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	return &Credentials{Tracer: provider.Tracer("test")}, recorder
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+
+	return attributes
+}
+
+func TestTracingHandlers(t *testing.T) {
+	c, recorder := newTestTracer()
+	httpClient := &responseHTTPClient{statusCode: http.StatusBadRequest, body: testThrottlingResponse}
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("testing", "testing", ""),
+		MaxRetries:  aws.Int(0),
+	}))
+	sess.Config.HTTPClient = &http.Client{Transport: roundTripper{httpClient}}
+	c.addTracingHandlers(&sess.Handlers, "us-east-1")
+
+	_, err := iam.New(sess).ListRoles(&iam.ListRolesInput{})
+	assert.Error(t, err)
+
+	httpClient.statusCode, httpClient.body = http.StatusOK, testListRolesResponse
+
+	_, err = iam.New(sess).ListRoles(&iam.ListRolesInput{})
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	assert.Equal(t, "iam.ListRoles", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	attributes := spanAttributes(spans[0])
+	assert.Equal(t, "iam", attributes["rpc.service"].AsString())
+	assert.Equal(t, "ListRoles", attributes["rpc.method"].AsString())
+	assert.Equal(t, "us-east-1", attributes["cloud.region"].AsString())
+	assert.Equal(t, "Throttling", attributes["error.type"].AsString())
+
+	assert.Equal(t, "iam.ListRoles", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.NotContains(t, spanAttributes(spans[1]), attribute.Key("error.type"))
+}
+
+func TestTracingHandlers_TraceParent(t *testing.T) {
+	c, recorder := newTestTracer()
+	httpClient := &responseHTTPClient{statusCode: http.StatusOK, body: testListRolesResponse}
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("testing", "testing", ""),
+		MaxRetries:  aws.Int(0),
+	}))
+	sess.Config.HTTPClient = &http.Client{Transport: roundTripper{httpClient}}
+	c.addTracingHandlers(&sess.Handlers, GlobalRegionID)
+
+	svc := iam.New(sess)
+
+	// Note: without a parent the span of a call without a context is a trace of its own
+	_, err := svc.ListRoles(&iam.ListRolesInput{})
+	assert.NoError(t, err)
+
+	run, runSpan := c.Tracer.Start(context.TODO(), "nuke")
+	c.SetTraceParent(run, "")
+
+	_, err = svc.ListRoles(&iam.ListRolesInput{})
+	assert.NoError(t, err)
+
+	region, regionSpan := c.Tracer.Start(run, "scan region")
+	c.SetTraceParent(region, GlobalRegionID)
+
+	_, err = svc.ListRoles(&iam.ListRolesInput{})
+	assert.NoError(t, err)
+
+	list, listSpan := c.Tracer.Start(region, "list")
+	_, err = svc.ListRolesWithContext(list, &iam.ListRolesInput{})
+	assert.NoError(t, err)
+
+	c.ClearTraceParent(GlobalRegionID)
+
+	_, err = svc.ListRoles(&iam.ListRolesInput{})
+	assert.NoError(t, err)
+
+	listSpan.End()
+	regionSpan.End()
+	runSpan.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 8)
+
+	assert.False(t, spans[0].Parent().IsValid())
+	assert.Equal(t, runSpan.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Equal(t, regionSpan.SpanContext().SpanID(), spans[2].Parent().SpanID())
+	assert.Equal(t, listSpan.SpanContext().SpanID(), spans[3].Parent().SpanID())
+	assert.Equal(t, runSpan.SpanContext().SpanID(), spans[4].Parent().SpanID())
+}
+
+func TestTraceCall(t *testing.T) {
+	c, recorder := newTestTracer()
+	httpClient := &responseHTTPClient{statusCode: http.StatusBadRequest, body: testThrottlingResponse}
+
+	cfg := awsv2.Config{
+		Region:           "us-east-1",
+		Credentials:      credentialsv2.NewStaticCredentialsProvider("testing", "testing", ""),
+		HTTPClient:       httpClient,
+		RetryMaxAttempts: 1,
+		APIOptions:       []func(*middleware.Stack) error{c.addTraceCall("us-east-1")},
+	}
+
+	parent, span := c.Tracer.Start(context.TODO(), "list")
+
+	_, err := iamv2.NewFromConfig(cfg).ListRoles(parent, &iamv2.ListRolesInput{})
+	assert.Error(t, err)
+
+	httpClient.statusCode, httpClient.body = http.StatusOK, testListRolesResponse
+
+	_, err = iamv2.NewFromConfig(cfg).ListRoles(parent, &iamv2.ListRolesInput{})
+	assert.NoError(t, err)
+
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+
+	assert.Equal(t, "iam.ListRoles", spans[0].Name())
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	attributes := spanAttributes(spans[0])
+	assert.Equal(t, "iam", attributes["rpc.service"].AsString())
+	assert.Equal(t, "ListRoles", attributes["rpc.method"].AsString())
+	assert.Equal(t, "us-east-1", attributes["cloud.region"].AsString())
+	assert.Equal(t, "Throttling", attributes["error.type"].AsString())
+
+	assert.Equal(t, "iam.ListRoles", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestTraceCall_Skipped(t *testing.T) {
+	c, recorder := newTestTracer()
+
+	cfg := awsv2.Config{
+		Region:      "us-east-1",
+		Credentials: credentialsv2.NewStaticCredentialsProvider("testing", "testing", ""),
+		HTTPClient:  &responseHTTPClient{statusCode: http.StatusOK, body: testListRolesResponse},
+		APIOptions:  []func(*middleware.Stack) error{addSkipRequest(false), c.addTraceCall("us-east-1")},
+	}
+
+	// IAM is global and is skipped in a regional config
+	_, err := iamv2.NewFromConfig(cfg).ListRoles(context.TODO(), &iamv2.ListRolesInput{})
+	assert.Error(t, err)
+	assert.Empty(t, recorder.Ended())
+}
+
+func TestTraceCall_Disabled(t *testing.T) {
+	stack := middleware.NewStack("test", nil)
+	assert.NoError(t, (&Credentials{}).addTraceCall("us-east-1")(stack))
+	assert.Empty(t, stack.Initialize.List())
+}
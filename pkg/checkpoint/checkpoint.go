@@ -0,0 +1,176 @@
+// Package checkpoint provides an on-disk record of the progress of a nuke run. It captures every resource that was
+// discovered during the scan along with its removal state, so that a run that died part way through can be resumed
+// without issuing removals again for resources that are already being removed.
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/plan"
+)
+
+// Checkpoint is the progress of a nuke run against a single account.
+type Checkpoint struct {
+	AccountID string    `json:"accountId"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Items     []*Item   `json:"items"`
+}
+
+// Item is a single resource that was discovered during the scan and its last known state.
+type Item struct {
+	Region       string            `json:"region"`
+	ResourceType string            `json:"resourceType"`
+	Identity     string            `json:"identity,omitempty"`
+	Properties   map[string]string `json:"properties,omitempty"`
+	State        string            `json:"state"`
+	Reason       string            `json:"reason,omitempty"`
+}
+
+// Key returns the key that identifies the resource across runs, the same key that identifies it in a plan, see
+// plan.Key.
+func (i *Item) Key() string {
+	return plan.Key(i.Region, i.ResourceType, i.Identity, i.Properties)
+}
+
+// RemovalInProgress returns true if the removal of the resource was already requested but not yet confirmed.
+func (i *Item) RemovalInProgress() bool {
+	return i.State == queue.ItemStatePending.String() || i.State == queue.ItemStateWaiting.String()
+}
+
+// New creates a new empty checkpoint for the given account.
+func New(accountID string) *Checkpoint {
+	return &Checkpoint{
+		AccountID: accountID,
+		Items:     make([]*Item, 0),
+	}
+}
+
+// Load reads a checkpoint from the file at the given path.
+func Load(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("unable to parse checkpoint %s: %w", path, err)
+	}
+
+	return cp, nil
+}
+
+// NewItem converts a queue item into a checkpoint item.
+func NewItem(item *queue.Item) *Item {
+	cpItem := &Item{
+		Region:       item.Owner,
+		ResourceType: item.Type,
+		State:        item.GetState().String(),
+		Reason:       item.GetReason(),
+	}
+
+	if stringer, ok := item.Resource.(resource.LegacyStringer); ok {
+		cpItem.Identity = stringer.String()
+	}
+
+	if getter, ok := item.Resource.(resource.PropertyGetter); ok {
+		cpItem.Properties = make(map[string]string)
+		for k, v := range getter.Properties() {
+			// Note: keys prefixed with an underscore are internal to libnuke and not actual properties
+			if strings.HasPrefix(k, "_") {
+				continue
+			}
+			cpItem.Properties[k] = v
+		}
+	}
+
+	return cpItem
+}
+
+// Record replaces the items of the checkpoint with the current state of the items in the queue. Items that were
+// confirmed as removed by a previous run are kept, since they no longer show up in the queue.
+func (c *Checkpoint) Record(q *queue.Queue) {
+	items := make([]*Item, 0, len(q.GetItems()))
+	seen := map[string]bool{}
+
+	for _, item := range q.GetItems() {
+		cpItem := NewItem(item)
+		seen[cpItem.Key()] = true
+		items = append(items, cpItem)
+	}
+
+	for _, cpItem := range c.Items {
+		if cpItem.State != queue.ItemStateFinished.String() || seen[cpItem.Key()] {
+			continue
+		}
+
+		items = append(items, cpItem)
+	}
+
+	c.Items = items
+	c.UpdatedAt = time.Now().UTC()
+}
+
+// Resume updates the state of the items in the queue from the checkpoint. Resources whose removal was already
+// requested are moved to the pending state, so they are polled until they are gone instead of being removed again.
+// Resources that were confirmed as removed are not listed anymore and therefore are not part of the queue. It returns
+// the number of resumed items.
+func (c *Checkpoint) Resume(q *queue.Queue) int {
+	inProgress := map[string]bool{}
+	for _, cpItem := range c.Items {
+		if cpItem.RemovalInProgress() {
+			inProgress[cpItem.Key()] = true
+		}
+	}
+
+	resumed := 0
+	for _, item := range q.GetItems() {
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		if !inProgress[NewItem(item).Key()] {
+			continue
+		}
+
+		item.State = queue.ItemStatePending
+		item.Reason = "removal resumed from checkpoint"
+		resumed++
+	}
+
+	return resumed
+}
+
+// Save writes the checkpoint to the file at the given path. The file is replaced atomically, so a process that dies
+// while saving does not leave a corrupt checkpoint behind.
+func (c *Checkpoint) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
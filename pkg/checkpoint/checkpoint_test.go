@@ -0,0 +1,114 @@
+package checkpoint
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/plan"
+)
+
+type TestResource struct {
+	id string
+}
+
+func (r *TestResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *TestResource) String() string {
+	return r.id
+}
+
+func (r *TestResource) Properties() types.Properties {
+	return types.NewProperties().Set("Name", r.id)
+}
+
+func testItem(id string, state queue.ItemState) *queue.Item {
+	return &queue.Item{
+		Resource: &TestResource{id: id},
+		State:    state,
+		Type:     "TestResource",
+		Owner:    "us-east-1",
+	}
+}
+
+func TestCheckpoint_RecordAndResume(t *testing.T) {
+	q := queue.New()
+	q.Items = append(q.Items,
+		testItem("finished", queue.ItemStateFinished),
+		testItem("pending", queue.ItemStatePending),
+		testItem("waiting", queue.ItemStateWaiting),
+		testItem("failed", queue.ItemStateFailed),
+	)
+
+	cp := New("123456789012")
+	cp.Record(q)
+	assert.Len(t, cp.Items, 4)
+
+	// The next run no longer lists the finished resource
+	resumedQueue := queue.New()
+	resumedQueue.Items = append(resumedQueue.Items,
+		testItem("pending", queue.ItemStateNew),
+		testItem("waiting", queue.ItemStateNewDependency),
+		testItem("failed", queue.ItemStateNew),
+		testItem("filtered", queue.ItemStateFiltered),
+	)
+
+	assert.Equal(t, 2, cp.Resume(resumedQueue))
+	assert.Equal(t, queue.ItemStatePending, resumedQueue.Items[0].State)
+	assert.Equal(t, queue.ItemStatePending, resumedQueue.Items[1].State)
+	assert.Equal(t, queue.ItemStateNew, resumedQueue.Items[2].State)
+	assert.Equal(t, queue.ItemStateFiltered, resumedQueue.Items[3].State)
+
+	// The finished resource is kept even though it is not part of the queue anymore
+	cp.Record(resumedQueue)
+	assert.Len(t, cp.Items, 5)
+	assert.Equal(t, "finished", cp.Items[4].Identity)
+}
+
+func TestCheckpoint_SaveAndLoad(t *testing.T) {
+	q := queue.New()
+	q.Items = append(q.Items, testItem("pending", queue.ItemStatePending))
+
+	cp := New("123456789012")
+	cp.Record(q)
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	assert.NoError(t, cp.Save(path))
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012", loaded.AccountID)
+	assert.Len(t, loaded.Items, 1)
+	assert.Equal(t, "pending", loaded.Items[0].State)
+	assert.Equal(t, map[string]string{"Name": "pending"}, loaded.Items[0].Properties)
+	assert.True(t, loaded.Items[0].RemovalInProgress())
+}
+
+func TestItem_KeyWithoutIdentity(t *testing.T) {
+	a := &Item{Region: "global", ResourceType: "Test", Properties: map[string]string{"B": "2", "A": "1"}}
+	assert.Equal(t, `global|Test|[A: "1", B: "2"]`, a.Key())
+}
+
+func TestItem_KeyWithIdentityProperties(t *testing.T) {
+	a := &Item{Region: "us-east-1", ResourceType: "ECSTask",
+		Properties: map[string]string{"TaskARN": "arn:task", "ClusterARN": "arn:cluster", "tag:team": "alice"}}
+	b := &Item{Region: "us-east-1", ResourceType: "ECSTask",
+		Properties: map[string]string{"TaskARN": "arn:task", "ClusterARN": "arn:cluster", "tag:team": "bob"}}
+	assert.Equal(t, `us-east-1|ECSTask|[ClusterARN: "arn:cluster", TaskARN: "arn:task"]`, a.Key())
+	assert.Equal(t, a.Key(), b.Key())
+}
+
+func TestItem_KeyMatchesPlan(t *testing.T) {
+	a := &Item{Region: "us-east-1", ResourceType: "ECSTask",
+		Properties: map[string]string{"TaskARN": "arn:task", "ClusterARN": "arn:cluster"}}
+	b := &plan.Item{Region: "us-east-1", ResourceType: "ECSTask",
+		Properties: map[string]string{"TaskARN": "arn:task", "ClusterARN": "arn:cluster"}}
+	assert.Equal(t, b.Key(), a.Key())
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/commands/global"
+	"github.com/ekristen/aws-nuke/v3/pkg/common"
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+)
+
+func executeSchema(c *cli.Context) error {
+	data, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+
+	if c.Path("output") == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(c.Path("output"), data, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote the schema of the configuration file to %s\n", c.Path("output"))
+
+	return nil
+}
+
+func init() {
+	flags := []cli.Flag{
+		&cli.PathFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "path to write the schema to, if empty, it will be written to stdout",
+		},
+	}
+
+	cmd := &cli.Command{
+		Name:  "config-schema",
+		Usage: "generate the JSON Schema of the configuration file",
+		Description: `generate the JSON Schema of the configuration file for editors with a YAML language server. The schema
+covers every configuration key, the resource types and settings of this version and suggests the properties that the
+resource types can be filtered on.`,
+		Flags:  append(flags, global.Flags()...),
+		Before: global.Before,
+		Action: executeSchema,
+	}
+
+	common.RegisterCommand(cmd)
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	libconfig "github.com/ekristen/libnuke/pkg/config"
+	"github.com/ekristen/libnuke/pkg/registry"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/commands/global"
+	"github.com/ekristen/aws-nuke/v3/pkg/common"
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+)
+
+func executeValidate(c *cli.Context) error {
+	path := c.Path("config")
+
+	problems, err := config.Validate(path)
+	if err != nil {
+		return err
+	}
+
+	errorCount, warningCount := 0, 0
+	for _, problem := range problems {
+		fmt.Println(problem.String())
+
+		if problem.Severity == config.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	// Note: loading the configuration catches the remaining mistakes, such as invalid values
+	if _, err := config.New(libconfig.Options{
+		Path:         path,
+		Deprecations: registry.GetDeprecatedResourceTypeMapping(),
+	}); err != nil {
+		return err
+	}
+
+	if errorCount > 0 || (c.Bool("strict") && warningCount > 0) {
+		return fmt.Errorf("%s has %d errors and %d warnings", path, errorCount, warningCount)
+	}
+
+	fmt.Printf("%s is valid, %d warnings\n", path, warningCount)
+
+	return nil
+}
+
+func init() {
+	flags := []cli.Flag{
+		&cli.PathFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Usage:   "path to config file",
+			Value:   "config.yaml",
+		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "fail on warnings as well as errors",
+		},
+	}
+
+	cmd := &cli.Command{
+		Name:  "validate-config",
+		Usage: "validate the configuration file without authenticating against an account",
+		Description: `validate the configuration file and report every problem with its position in the file. Filters and
+settings of unknown resource types and unknown settings are errors, filters on properties that the resource type does
+not document are warnings. The command fails when there are errors, or warnings with --strict.`,
+		Flags:  append(flags, global.Flags()...),
+		Before: global.Before,
+		Action: executeValidate,
+	}
+
+	common.RegisterCommand(cmd)
+}
@@ -0,0 +1,275 @@
+package nuke
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/awsutil"
+	"github.com/ekristen/aws-nuke/v3/pkg/commands/global"
+	"github.com/ekristen/aws-nuke/v3/pkg/common"
+	"github.com/ekristen/aws-nuke/v3/pkg/notify"
+)
+
+// accountTarget is an account to nuke when running against multiple accounts and the role to assume in it.
+type accountTarget struct {
+	AccountID string
+	RoleArn   string
+}
+
+// parseAccountTargets parses the accounts given on the command line. Every account is given as either
+// <account-id>=<role-arn> or just <account-id>, in which case the role with the given role name is assumed.
+func parseAccountTargets(values []string, roleName string) ([]*accountTarget, error) {
+	targets := make([]*accountTarget, 0, len(values))
+	seen := map[string]bool{}
+
+	for _, value := range values {
+		accountID, roleArn, _ := strings.Cut(strings.TrimSpace(value), "=")
+		if accountID == "" {
+			return nil, fmt.Errorf("invalid account '%s', must be <account-id>=<role-arn> or <account-id>", value)
+		}
+
+		if roleArn == "" {
+			if roleName == "" {
+				return nil, fmt.Errorf("no role arn given for account %s and no role name configured", accountID)
+			}
+
+			roleArn = fmt.Sprintf("arn:%s:iam::%s:role/%s", awsutil.DefaultAWSPartitionID, accountID, roleName)
+		}
+
+		if seen[accountID] {
+			return nil, fmt.Errorf("account %s is given more than once", accountID)
+		}
+		seen[accountID] = true
+
+		targets = append(targets, &accountTarget{
+			AccountID: accountID,
+			RoleArn:   roleArn,
+		})
+	}
+
+	return targets, nil
+}
+
+// accountFormatter wraps the configured formatter to prefix every log line with the account ID, so the output of
+// accounts that are nuked concurrently can be told apart. JSON output gets an account field instead.
+type accountFormatter struct {
+	accountID string
+	formatter logrus.Formatter
+}
+
+func (f *accountFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if _, ok := f.formatter.(*logrus.JSONFormatter); ok {
+		accountEntry := *entry
+		accountEntry.Data = make(logrus.Fields, len(entry.Data)+1)
+		for k, v := range entry.Data {
+			accountEntry.Data[k] = v
+		}
+		accountEntry.Data["account"] = f.accountID
+
+		return f.formatter.Format(&accountEntry)
+	}
+
+	out, err := f.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := []byte(fmt.Sprintf("[%s] ", f.accountID))
+
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(out, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		buf.Write(prefix)
+		buf.Write(line)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newAccountLogger returns a logger that behaves like the given logger but prefixes its output with the account ID.
+func newAccountLogger(base *logrus.Logger, accountID string) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(base.Out)
+	logger.SetLevel(base.GetLevel())
+	logger.SetReportCaller(base.ReportCaller)
+	logger.SetFormatter(&accountFormatter{
+		accountID: accountID,
+		formatter: base.Formatter,
+	})
+	logger.ReplaceHooks(base.Hooks)
+
+	return logger
+}
+
+func executeAccounts(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	creds := ConfigureCreds(c)
+
+	if err := creds.Validate(); err != nil {
+		return err
+	}
+
+	reportFormat, err := reportFormatFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	workers := c.Int("workers")
+	if workers < 1 {
+		return fmt.Errorf("value for --workers must be at least 1")
+	}
+
+	// Create the parameters object that will be used to configure the nuke process for every account.
+	params := NewParameters(c)
+
+	// Note: the prompt cannot be answered for several accounts at the same time
+	if workers > 1 && !params.Force {
+		return fmt.Errorf("--no-prompt is required when running against accounts in parallel")
+	}
+
+	logger := logrus.StandardLogger()
+	logger.SetOutput(os.Stdout)
+
+	parsedConfig, err := parseConfig(c, logger)
+	if err != nil {
+		return err
+	}
+
+	// Set the default region for the AWS SDK to use.
+	if err := ConfigureDefaultRegion(c.String("default-region"), parsedConfig, logger); err != nil {
+		return err
+	}
+
+	targets, err := parseAccountTargets(c.StringSlice("account"), c.String("role-name"))
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		return fmt.Errorf("at least one --account is required")
+	}
+
+	creds.CustomEndpoints = parsedConfig.CustomEndpoints
+	creds.RateLimits = parsedConfig.RateLimits
+
+	notifier, err := notify.New(parsedConfig.Notifications)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := startMetrics(c, creds, logger)
+	if err != nil {
+		return err
+	}
+	defer exporter.Stop()
+
+	tracer, err := startTracing(c, creds, logger)
+	if err != nil {
+		return err
+	}
+	defer tracer.Stop()
+
+	// Note: the registry is shared by the workers, it must not be modified once they start
+	accountIDs := make([]string, 0, len(targets))
+	for _, target := range targets {
+		accountIDs = append(accountIDs, target.AccountID)
+	}
+	registerAlternativeResourceTypes(c, parsedConfig, accountIDs)
+
+	logger.Infof("running against %d accounts with %d workers", len(targets), workers)
+
+	results := make([]*accountResult, len(targets))
+	for i, target := range targets {
+		results[i] = &accountResult{
+			AccountID: target.AccountID,
+		}
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for idx := range jobs {
+				target := targets[idx]
+				result := results[idx]
+
+				if parsedConfig.InBlocklist(target.AccountID) {
+					result.SkipReason = "account is in blocklist"
+					continue
+				}
+
+				accountLogger := newAccountLogger(logger, target.AccountID)
+				accountLogger.Infof("starting nuke of account %s", target.AccountID)
+
+				accountCreds := creds.AssumeRole(target.RoleArn, c.String("assume-role-session-name"),
+					c.String("role-external-id"))
+
+				nukeAccount(ctx, c, params, parsedConfig, accountCreds, nil, accountLogger, reportFormat, notifier, result)
+
+				accountLogger.Infof("finished nuke of account %s", target.AccountID)
+			}
+		}()
+	}
+
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return summarizeAccounts(results, logger)
+}
+
+func init() {
+	flags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "account",
+			Usage: "an account to run against given as <account-id>=<role-arn> or <account-id> (can be repeated)",
+		},
+		&cli.StringFlag{
+			Name:  "role-name",
+			Usage: "the name of the role to assume in accounts that are given without a role arn",
+			Value: "OrganizationAccountAccessRole",
+		},
+		&cli.StringFlag{
+			Name:  "role-external-id",
+			Usage: "the external id to provide when assuming the role in each account",
+		},
+		&cli.IntFlag{
+			Name:  "workers",
+			Usage: "the number of accounts to run against in parallel",
+			Value: 4,
+		},
+	}
+
+	cmd := &cli.Command{
+		Name:  "run-accounts",
+		Usage: "run nuke against multiple aws accounts in parallel",
+		Description: `run nuke against every account given by --account, assuming the given role in each account.
+Up to --workers accounts are nuked at the same time, each with its own credentials and with its log output prefixed
+by the account ID. Accounts that are in the blocklist are skipped. A consolidated summary is printed at the end and
+the command fails if the nuke process failed for any of the accounts.`,
+		Flags:  append(append(flags, Flags()...), global.Flags()...),
+		Before: global.Before,
+		Action: executeAccounts,
+	}
+
+	common.RegisterCommand(cmd)
+}
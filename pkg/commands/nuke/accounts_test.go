@@ -0,0 +1,155 @@
+package nuke
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	libconfig "github.com/ekristen/libnuke/pkg/config"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+)
+
+func TestParseAccountTargets(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   []string
+		roleName string
+		want     []*accountTarget
+		err      string
+	}{
+		{
+			name:     "role arn",
+			values:   []string{"111111111111=arn:aws:iam::111111111111:role/custom"},
+			roleName: "OrganizationAccountAccessRole",
+			want: []*accountTarget{
+				{AccountID: "111111111111", RoleArn: "arn:aws:iam::111111111111:role/custom"},
+			},
+		},
+		{
+			name:     "role name",
+			values:   []string{" 222222222222 "},
+			roleName: "OrganizationAccountAccessRole",
+			want: []*accountTarget{
+				{AccountID: "222222222222", RoleArn: "arn:aws:iam::222222222222:role/OrganizationAccountAccessRole"},
+			},
+		},
+		{
+			name:     "mixed",
+			values:   []string{"111111111111=arn:aws:iam::111111111111:role/custom", "222222222222"},
+			roleName: "nuke",
+			want: []*accountTarget{
+				{AccountID: "111111111111", RoleArn: "arn:aws:iam::111111111111:role/custom"},
+				{AccountID: "222222222222", RoleArn: "arn:aws:iam::222222222222:role/nuke"},
+			},
+		},
+		{
+			name:   "empty account",
+			values: []string{"=arn:aws:iam::111111111111:role/custom"},
+			err:    "invalid account",
+		},
+		{
+			name:   "no role",
+			values: []string{"111111111111"},
+			err:    "no role arn given for account 111111111111",
+		},
+		{
+			name:     "duplicate",
+			values:   []string{"111111111111", "111111111111=arn:aws:iam::111111111111:role/custom"},
+			roleName: "nuke",
+			err:      "account 111111111111 is given more than once",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			targets, err := parseAccountTargets(tc.values, tc.roleName)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, targets)
+		})
+	}
+}
+
+func TestAccountFormatter_Text(t *testing.T) {
+	formatter := &accountFormatter{
+		accountID: "111111111111",
+		formatter: &logrus.TextFormatter{DisableColors: true, DisableTimestamp: true},
+	}
+
+	out, err := formatter.Format(&logrus.Entry{
+		Logger:  logrus.New(),
+		Level:   logrus.InfoLevel,
+		Message: "first\nsecond",
+		Data:    logrus.Fields{},
+	})
+	assert.NoError(t, err)
+
+	for _, line := range bytes.Split(bytes.TrimSuffix(out, []byte("\n")), []byte("\n")) {
+		assert.True(t, bytes.HasPrefix(line, []byte("[111111111111] ")), string(line))
+	}
+}
+
+func TestAccountFormatter_JSON(t *testing.T) {
+	formatter := &accountFormatter{
+		accountID: "111111111111",
+		formatter: &logrus.JSONFormatter{},
+	}
+
+	entry := &logrus.Entry{
+		Logger:  logrus.New(),
+		Level:   logrus.InfoLevel,
+		Message: "scanning",
+		Data:    logrus.Fields{"region": "us-east-1"},
+	}
+
+	out, err := formatter.Format(entry)
+	assert.NoError(t, err)
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out, &fields))
+	assert.Equal(t, "111111111111", fields["account"])
+	assert.Equal(t, "us-east-1", fields["region"])
+	assert.Equal(t, "scanning", fields["msg"])
+
+	// Note: the entry is shared by the hooks and formatters of the logger, it must not be modified
+	assert.NotContains(t, entry.Data, "account")
+}
+
+func TestAlternativeResourceTypes(t *testing.T) {
+	parsedConfig := &config.Config{
+		Config: &libconfig.Config{
+			ResourceTypes: libconfig.ResourceTypes{
+				Alternatives: types.Collection{"AWS::EC2::VPC"},
+			},
+			Accounts: map[string]*libconfig.Account{
+				"111111111111": {
+					ResourceTypes: libconfig.ResourceTypes{
+						Alternatives: types.Collection{"AWS::EC2::Subnet"},
+					},
+				},
+				"222222222222": {
+					ResourceTypes: libconfig.ResourceTypes{
+						Alternatives: types.Collection{"AWS::EC2::RouteTable"},
+					},
+				},
+				"333333333333": nil,
+			},
+		},
+	}
+
+	altResourceTypes := alternativeResourceTypes([]string{"AWS::EC2::InternetGateway"}, parsedConfig,
+		[]string{"111111111111", "222222222222", "333333333333", "444444444444"})
+
+	assert.ElementsMatch(t, types.Collection{
+		"AWS::EC2::InternetGateway", "AWS::EC2::VPC", "AWS::EC2::Subnet", "AWS::EC2::RouteTable",
+	}, altResourceTypes)
+}
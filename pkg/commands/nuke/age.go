@@ -0,0 +1,104 @@
+package nuke
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+)
+
+const (
+	// ReasonUnknownAge is the reason of resources that are filtered because their creation time is not known.
+	ReasonUnknownAge = "creation time unknown, protected by min-age"
+)
+
+// registerCreatedAt registers the hook on the runner that adds the normalized CreatedAt property to the scanned
+// resources and filters the resources that are younger than the min-age setting. The hook is only registered when a
+// min-age is set or a filter refers to the CreatedAt property, otherwise there is nothing it could filter.
+func registerCreatedAt(r *runner, parsedConfig *config.Config, logger *logrus.Logger) error {
+	minAge, err := parsedConfig.MinAgeDuration()
+	if err != nil {
+		return err
+	}
+
+	if minAge == 0 && !filtersCreatedAt(r.nuke) {
+		return nil
+	}
+
+	r.OnAfterScan(func(q *queue.Queue) error {
+		return filterByAge(r.nuke, q, minAge, time.Now(), logger)
+	})
+
+	return nil
+}
+
+// filtersCreatedAt returns true if any of the filters refers to the CreatedAt property.
+func filtersCreatedAt(n *libnuke.Nuke) bool {
+	for _, filters := range n.Filters {
+		for _, f := range filters {
+			if f.Property == nuke.CreatedAtProperty {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// filterByAge filters the resources that would be removed and are younger than the minimum age, or whose creation
+// time is not known, and filters the others again with their creation time as the CreatedAt property. Only the
+// resources that end up filtered keep the CreatedAt property, the other resources are restored as they were scanned.
+func filterByAge(n *libnuke.Nuke, q *queue.Queue, minAge time.Duration, now time.Time, logger *logrus.Logger) error {
+	unknown, young := 0, 0
+
+	for _, item := range q.Items {
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		createdAt, ok := nuke.CreatedAt(item.Resource)
+		if !ok {
+			if minAge == 0 {
+				continue
+			}
+
+			unknown++
+			item.State = queue.ItemStateFiltered
+			item.Reason = ReasonUnknownAge
+		} else {
+			original := item.Resource
+			item.Resource = &nuke.CreatedAtResource{Resource: original, CreatedAt: createdAt}
+
+			if age := now.Sub(createdAt); age < minAge {
+				young++
+				item.State = queue.ItemStateFiltered
+				item.Reason = fmt.Sprintf("created %s ago, protected by min-age %s", age.Round(time.Second), minAge)
+			} else if err := n.Filter(item); err != nil {
+				return err
+			}
+
+			if item.GetState() != queue.ItemStateFiltered {
+				item.Resource = original
+				continue
+			}
+		}
+
+		if !n.Parameters.Quiet {
+			item.Print()
+		}
+	}
+
+	if minAge > 0 {
+		logger.WithField("_handler", "println").
+			Infof("Min-age of %s applied: %d resources too young, %d resources with an unknown creation time.",
+				minAge, young, unknown)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package nuke
+
+import (
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+	"github.com/ekristen/aws-nuke/v3/resources"
+)
+
+const (
+	// ReasonArchiveBucket is the reason of the buckets and objects that are filtered because they are archive buckets.
+	ReasonArchiveBucket = "archive bucket"
+
+	// ReasonArchived is the reason of the objects and items that are filtered because they are archived and removed
+	// together with their bucket or table.
+	ReasonArchived = "archived and removed with its bucket or table"
+)
+
+// registerArchival registers the hook on the runner that protects the data which is archived before it is removed.
+// The archive buckets are never removed, and the objects of archived buckets and items of archived tables are left to
+// their bucket or table, otherwise they would be removed before they could be archived. The hook is only registered
+// when an archive bucket is set.
+func registerArchival(r *runner) {
+	bucketSettings := r.nuke.Settings.Get(resources.S3BucketResource)
+	tableSettings := r.nuke.Settings.Get(resources.DynamoDBTableResource)
+
+	if nuke.ArchiveBucket(bucketSettings) == "" && nuke.ArchiveBucket(tableSettings) == "" {
+		return
+	}
+
+	r.OnAfterScan(func(q *queue.Queue) error {
+		filterArchival(r.nuke, q, nuke.ArchiveBucket(bucketSettings) != "", nuke.ArchiveBucket(tableSettings) != "")
+		return nil
+	})
+}
+
+// filterArchival filters the archive buckets with their objects, and the objects and items of the buckets and tables
+// that are archived before they are removed.
+func filterArchival(n *libnuke.Nuke, q *queue.Queue, archiveBuckets, archiveTables bool) {
+	archives := map[string]bool{}
+	for _, resourceType := range []string{resources.S3BucketResource, resources.DynamoDBTableResource} {
+		if bucket := nuke.ArchiveBucket(n.Settings.Get(resourceType)); bucket != "" {
+			archives[bucket] = true
+		}
+	}
+
+	// Note: buckets and tables are keyed by their owner, names are only unique within a region
+	archived := map[string]bool{}
+	for _, item := range q.Items {
+		if item.Type == resources.S3BucketResource && archives[itemProperty(item, "Name")] {
+			filterArchiveItem(n, item, ReasonArchiveBucket)
+			continue
+		}
+
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		if (item.Type == resources.S3BucketResource && archiveBuckets) ||
+			(item.Type == resources.DynamoDBTableResource && archiveTables) {
+			archived[item.Type+"/"+item.Owner+"/"+itemProperty(item, "Name")] = true
+		}
+	}
+
+	for _, item := range q.Items {
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		switch item.Type {
+		case resources.S3ObjectResource:
+			bucket := itemProperty(item, "Bucket")
+			if archives[bucket] {
+				filterArchiveItem(n, item, ReasonArchiveBucket)
+			} else if archived[resources.S3BucketResource+"/"+item.Owner+"/"+bucket] {
+				filterArchiveItem(n, item, ReasonArchived)
+			}
+		case resources.DynamoDBTableItemResource:
+			if archived[resources.DynamoDBTableResource+"/"+item.Owner+"/"+itemProperty(item, "Table")] {
+				filterArchiveItem(n, item, ReasonArchived)
+			}
+		}
+	}
+}
+
+// filterArchiveItem filters the item with the reason.
+func filterArchiveItem(n *libnuke.Nuke, item *queue.Item, reason string) {
+	if item.GetState() == queue.ItemStateFiltered {
+		return
+	}
+
+	item.State = queue.ItemStateFiltered
+	item.Reason = reason
+
+	if !n.Parameters.Quiet {
+		item.Print()
+	}
+}
+
+// itemProperty returns the property of the item, or an empty string when the resource has no properties.
+func itemProperty(item *queue.Item, key string) string {
+	value, err := item.GetProperty(key)
+	if err != nil {
+		return ""
+	}
+
+	return value
+}
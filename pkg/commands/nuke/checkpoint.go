@@ -0,0 +1,62 @@
+package nuke
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/checkpoint"
+)
+
+// registerCheckpoint registers the hooks on the runner that write the progress of the run to the checkpoint file.
+// When resuming, the previous checkpoint is loaded and the removals that were already in progress are resumed.
+func registerCheckpoint(c *cli.Context, r *runner, accountID, checkpointPath string, logger *logrus.Logger) error {
+	if checkpointPath == "" {
+		if c.Bool("resume") {
+			return fmt.Errorf("--resume requires --checkpoint")
+		}
+
+		return nil
+	}
+
+	cp := checkpoint.New(accountID)
+
+	if c.Bool("resume") {
+		loaded, err := checkpoint.Load(checkpointPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			logger.Warnf("no checkpoint found at %s, starting from the beginning", checkpointPath)
+		case err != nil:
+			return err
+		case loaded.AccountID != accountID:
+			return fmt.Errorf("checkpoint %s belongs to account %s, not %s",
+				checkpointPath, loaded.AccountID, accountID)
+		default:
+			cp = loaded
+
+			r.OnAfterScan(func(q *queue.Queue) error {
+				resumed := cp.Resume(q)
+				logger.WithField("_handler", "println").
+					Infof("Resumed from checkpoint: %d resources with a removal already in progress.", resumed)
+				return nil
+			})
+		}
+	}
+
+	r.OnAfterPass(func(q *queue.Queue) error {
+		cp.Record(q)
+
+		if err := cp.Save(checkpointPath); err != nil {
+			return fmt.Errorf("unable to write checkpoint to %s: %w", checkpointPath, err)
+		}
+
+		return nil
+	})
+
+	return nil
+}
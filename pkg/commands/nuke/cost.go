@@ -0,0 +1,74 @@
+package nuke
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/cost"
+)
+
+// registerCostEstimation registers the hook on the runner that estimates the monthly cost of the resources that would
+// be removed once the scan is complete, and prints the estimates by region and resource type. The pricing table also
+// estimates the cost of every resource in the report of the run.
+func registerCostEstimation(c *cli.Context, r *runner, logger *logrus.Logger) error {
+	if !c.Bool("estimate-cost") {
+		return nil
+	}
+
+	table, err := cost.Load(c.Path("pricing-table"))
+	if err != nil {
+		return err
+	}
+
+	r.estimator = table
+
+	r.OnAfterScan(func(q *queue.Queue) error {
+		printEstimates(estimateCosts(q, table), logger)
+		return nil
+	})
+
+	return nil
+}
+
+// estimateCosts adds up the estimated monthly costs of the resources that would be removed.
+func estimateCosts(q *queue.Queue, table *cost.Table) *cost.Summary {
+	summary := cost.NewSummary()
+
+	for _, item := range q.Items {
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		getter, ok := item.Resource.(resource.PropertyGetter)
+		if !ok {
+			continue
+		}
+
+		if monthly, ok := table.Estimate(item.Type, getter.Properties()); ok {
+			summary.Add(item.Owner, item.Type, monthly)
+		}
+	}
+
+	return summary
+}
+
+// printEstimates prints the estimated monthly costs by region and resource type, followed by the total.
+func printEstimates(summary *cost.Summary, logger *logrus.Logger) {
+	log := logger.WithField("component", "cost-estimation").WithField("_handler", "println")
+
+	estimates := summary.Estimates()
+	if len(estimates) == 0 {
+		log.Info("Cost estimation complete: none of the resources that would be removed have a known price.")
+		return
+	}
+
+	for _, e := range estimates {
+		log.Infof("%s - %s - %d resources - $%.2f/month", e.Region, e.ResourceType, e.Count, e.Monthly)
+	}
+
+	log.Infof("Cost estimation complete: $%.2f/month of estimated savings, based on approximate on-demand prices.",
+		summary.Total())
+}
@@ -0,0 +1,151 @@
+package nuke
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	liblog "github.com/ekristen/libnuke/pkg/log"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/commands/global"
+	"github.com/ekristen/aws-nuke/v3/pkg/common"
+	"github.com/ekristen/aws-nuke/v3/pkg/diff"
+	"github.com/ekristen/aws-nuke/v3/pkg/plan"
+)
+
+func executeDiff(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	if c.Args().Len() < 1 || c.Args().Len() > 2 {
+		return fmt.Errorf("one snapshot to compare against the live account, or two snapshots are required")
+	}
+
+	oldSnapshot, err := diff.LoadSnapshot(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	var newSnapshot *plan.Plan
+	if c.Args().Len() == 2 {
+		newSnapshot, err = diff.LoadSnapshot(c.Args().Get(1))
+		if err != nil {
+			return err
+		}
+	} else {
+		newSnapshot, err = liveSnapshot(ctx, c)
+		if err != nil {
+			return err
+		}
+	}
+
+	if oldSnapshot.AccountID != newSnapshot.AccountID {
+		return fmt.Errorf("snapshots belong to different accounts: %s and %s",
+			oldSnapshot.AccountID, newSnapshot.AccountID)
+	}
+
+	result := diff.Compare(oldSnapshot, newSnapshot, c.StringSlice("property"))
+
+	printDiff(result)
+
+	if c.Bool("exit-code") && !result.Empty() {
+		return cli.Exit("", 1)
+	}
+
+	return nil
+}
+
+// liveSnapshot scans the account like a dry-run and returns the resources that would be removed.
+func liveSnapshot(ctx context.Context, c *cli.Context) (*plan.Plan, error) {
+	params := NewParameters(c)
+	params.NoDryRun = false
+
+	parsedConfig, account, logger, err := setupAccount(c)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter, err := startMetrics(c, account.Credentials, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer exporter.Stop()
+
+	tracer, err := startTracing(c, account.Credentials, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer tracer.Stop()
+
+	n, err := newAccountNuke(c, params, parsedConfig, account, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Note: nothing can be removed while taking a snapshot, so there is nothing to confirm
+	n.RegisterPrompt(func() error { return nil })
+
+	r, err := newAccountRunner(c, n, parsedConfig, account, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Run(ctx); err != nil {
+		return nil, err
+	}
+
+	return plan.New(account.ID(), account.Alias(), n.Queue), nil
+}
+
+// printDiff prints the difference between the snapshots to the screen.
+func printDiff(result *diff.Result) {
+	fmt.Printf("Inventory Drift\n\n")
+
+	fmt.Printf("Appeared:    %d\n", len(result.Appeared))
+	fmt.Printf("Disappeared: %d\n", len(result.Disappeared))
+	fmt.Printf("Changed:     %d\n", len(result.Changed))
+
+	fmt.Println("")
+
+	for _, item := range result.Appeared {
+		fmt.Printf("+ %s - %s - %s - %s\n", item.Region, item.ResourceType, item.Identity, liblog.Sorted(item.Properties))
+	}
+
+	for _, item := range result.Disappeared {
+		fmt.Printf("- %s - %s - %s\n", item.Region, item.ResourceType, item.Identity)
+	}
+
+	for _, change := range result.Changed {
+		fmt.Printf("~ %s - %s - %s\n", change.Item.Region, change.Item.ResourceType, change.Item.Identity)
+		for _, property := range change.Properties {
+			fmt.Printf("    %s: %q => %q\n", property.Key, property.Old, property.New)
+		}
+	}
+}
+
+func init() {
+	cmd := &cli.Command{
+		Name:      "diff",
+		Usage:     "show the drift of the nukeable resources between two snapshots or a snapshot and the live account",
+		ArgsUsage: "<old-snapshot> [<new-snapshot>]",
+		Description: `diff compares two snapshots of the resources that would be removed from an account. A snapshot is
+either a plan file created by the plan command or a json report created by run --report. When only one snapshot is
+given, it is compared against a dry-run of the live account using the given configuration. The resources that
+appeared, disappeared or changed their properties are shown.`,
+		Flags: append(append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "property",
+				Usage: "only compare these properties, a trailing * matches a prefix, for example tag:*",
+			},
+			&cli.BoolFlag{
+				Name:  "exit-code",
+				Usage: "exit with a non-zero exit code if there is any drift",
+			},
+		}, Flags()...), global.Flags()...),
+		Before: global.Before,
+		Action: executeDiff,
+	}
+
+	common.RegisterCommand(cmd)
+}
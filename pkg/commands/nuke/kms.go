@@ -0,0 +1,51 @@
+package nuke
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+	"github.com/ekristen/aws-nuke/v3/resources"
+)
+
+// registerKMSKeyOrder registers the hook on the runner that schedules the deletion of the replicas of multi-region
+// keys before their primary keys. The hook is only registered when KMS keys are removed.
+func registerKMSKeyOrder(r *runner) {
+	if !slices.Contains(r.nuke.ResourceTypes[nuke.Account], resources.KMSKeyResource) {
+		return
+	}
+
+	r.OnAfterScan(func(q *queue.Queue) error {
+		orderKMSKeys(q)
+		return nil
+	})
+}
+
+// orderKMSKeys moves the replica keys in front of the other keys in the queue. The keys only swap places among
+// themselves, every other item keeps its place.
+func orderKMSKeys(q *queue.Queue) {
+	positions := make([]int, 0)
+	keys := make([]*queue.Item, 0)
+	for i, item := range q.Items {
+		if item.Type == resources.KMSKeyResource {
+			positions = append(positions, i)
+			keys = append(keys, item)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return isKMSKeyReplica(keys[i]) && !isKMSKeyReplica(keys[j])
+	})
+
+	for i, position := range positions {
+		q.Items[position] = keys[i]
+	}
+}
+
+// isKMSKeyReplica returns true if the item is the replica of a multi-region key.
+func isKMSKeyReplica(item *queue.Item) bool {
+	key, ok := item.Resource.(*resources.KMSKey)
+	return ok && key.IsReplica()
+}
@@ -0,0 +1,114 @@
+package nuke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/awsutil"
+	"github.com/ekristen/aws-nuke/v3/pkg/metrics"
+)
+
+// metricsExporter serves the metrics of the run while it is in progress and pushes them once it is complete.
+type metricsExporter struct {
+	metrics     *metrics.Metrics
+	server      *http.Server
+	pushgateway string
+	job         string
+	logger      *logrus.Logger
+}
+
+// startMetrics creates the metrics of the run when --metrics-address or --metrics-pushgateway is given and sets them on
+// the credentials, so the calls to the AWS APIs are measured. The metrics are served on --metrics-address until the
+// exporter is stopped. Without either flag nil is returned, which is safe to stop.
+func startMetrics(c *cli.Context, creds *awsutil.Credentials, logger *logrus.Logger) (*metricsExporter, error) {
+	address := c.String("metrics-address")
+	pushgateway := c.String("metrics-pushgateway")
+
+	if address == "" && pushgateway == "" {
+		return nil, nil
+	}
+
+	e := &metricsExporter{
+		metrics:     metrics.New(),
+		pushgateway: pushgateway,
+		job:         c.String("metrics-job"),
+		logger:      logger,
+	}
+
+	if address != "" {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serve metrics on %s: %w", address, err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", e.metrics.Handler())
+
+		e.server = &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			if err := e.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.WithError(err).Error("unable to serve metrics")
+			}
+		}()
+
+		logger.Infof("serving metrics on %s/metrics", listener.Addr())
+	}
+
+	creds.Metrics = e.metrics
+
+	return e, nil
+}
+
+// Stop pushes the metrics to the Pushgateway and stops serving them. A failed push is logged and does not fail the
+// run.
+func (e *metricsExporter) Stop() {
+	if e == nil {
+		return
+	}
+
+	if e.pushgateway != "" {
+		if err := e.metrics.Push(e.pushgateway, e.job); err != nil {
+			e.logger.WithError(err).Errorf("unable to push metrics to %s", e.pushgateway)
+		}
+	}
+
+	if e.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = e.server.Shutdown(ctx)
+	}
+}
+
+// registerMetrics registers the hooks on the runner that record the metrics of the resources of the account, when
+// metrics are collected.
+func registerMetrics(r *runner, account *awsutil.Account) {
+	if account.Metrics == nil {
+		return
+	}
+
+	m := account.Metrics
+	tracker := m.NewTracker(account.ID())
+
+	r.OnAfterRegionScan(func(region string, duration time.Duration) {
+		m.ObserveList(account.ID(), region, duration)
+	})
+
+	r.OnAfterPass(func(q *queue.Queue) error {
+		tracker.Update(q)
+		return nil
+	})
+}
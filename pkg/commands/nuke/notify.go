@@ -0,0 +1,22 @@
+package nuke
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/notify"
+	"github.com/ekristen/aws-nuke/v3/pkg/report"
+)
+
+// notifyRun posts the summary of the run to the configured webhooks. The summary is posted even when the run was
+// cancelled, and a failed notification is logged and does not fail the run.
+func notifyRun(ctx context.Context, notifier *notify.Notifier, runReport *report.Report, logger *logrus.Logger) {
+	if !notifier.Enabled() {
+		return
+	}
+
+	if err := notifier.Notify(context.WithoutCancel(ctx), notify.NewSummary(runReport)); err != nil {
+		logger.WithError(err).Error("unable to send notification")
+	}
+}
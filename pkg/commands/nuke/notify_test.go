@@ -0,0 +1,38 @@
+package nuke
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+	"github.com/ekristen/aws-nuke/v3/pkg/notify"
+	"github.com/ekristen/aws-nuke/v3/pkg/report"
+)
+
+func TestNotifyRun_Redacted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	notifier, err := notify.New(config.Notifications{
+		Webhooks: []config.Webhook{{URL: server.URL + "/services/T000/B000/secret"}},
+	})
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+
+	notifyRun(context.TODO(), notifier, &report.Report{Summary: &report.Summary{}}, logger)
+
+	// Note: the webhook is not reachable, the error is logged without the secret path of the webhook
+	assert.Contains(t, out.String(), "unable to send notification")
+	assert.Contains(t, out.String(), server.URL)
+	assert.NotContains(t, out.String(), "/services")
+	assert.NotContains(t, out.String(), "secret")
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/ekristen/aws-nuke/v3/pkg/commands/global"
 	"github.com/ekristen/aws-nuke/v3/pkg/common"
 	"github.com/ekristen/aws-nuke/v3/pkg/config"
+	"github.com/ekristen/aws-nuke/v3/pkg/notify"
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+	"github.com/ekristen/aws-nuke/v3/pkg/report"
 
 	"github.com/ekristen/aws-nuke/v3/resources"
 )
@@ -44,18 +46,8 @@ func ConfigureCreds(c *cli.Context) (creds *awsutil.Credentials) {
 	return creds
 }
 
-func execute(c *cli.Context) error { //nolint:funlen,gocyclo
-	ctx, cancel := context.WithCancel(c.Context)
-	defer cancel()
-
-	defaultRegion := c.String("default-region")
-	creds := ConfigureCreds(c)
-
-	if err := creds.Validate(); err != nil {
-		return err
-	}
-
-	// Create the parameters object that will be used to configure the nuke process.
+// NewParameters is a helper function to configure the libnuke.Parameters object from the cli.Context
+func NewParameters(c *cli.Context) *libnuke.Parameters {
 	params := &libnuke.Parameters{
 		Force:          c.Bool("force"),
 		ForceSleep:     c.Int("force-sleep"),
@@ -77,10 +69,151 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 		}
 	}
 
+	return params
+}
+
+// ConfigureDefaultRegion is a helper function to set the default region and partition for the AWS SDK to use.
+func ConfigureDefaultRegion(defaultRegion string, parsedConfig *config.Config, logger *logrus.Logger) error {
+	if defaultRegion == "" {
+		return nil
+	}
+
+	awsutil.DefaultRegionID = defaultRegion
+
+	partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), defaultRegion)
+	if !ok {
+		if parsedConfig.CustomEndpoints.GetRegion(defaultRegion) == nil {
+			err := fmt.Errorf(
+				"the custom region '%s' must be specified in the configuration 'endpoints'"+
+					" to determine its partition", defaultRegion)
+			logger.WithError(err).Errorf("unable to resolve partition for region: %s", defaultRegion)
+			return err
+		}
+	}
+
+	awsutil.DefaultAWSPartitionID = partition.ID()
+
+	return nil
+}
+
+func execute(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	reportFormat, err := reportFormatFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	// Create the parameters object that will be used to configure the nuke process.
+	params := NewParameters(c)
+
+	parsedConfig, account, logger, err := setupAccount(c)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := notify.New(parsedConfig.Notifications)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := startMetrics(c, account.Credentials, logger)
+	if err != nil {
+		return err
+	}
+	defer exporter.Stop()
+
+	tracer, err := startTracing(c, account.Credentials, logger)
+	if err != nil {
+		return err
+	}
+	defer tracer.Stop()
+
+	n, err := newAccountNuke(c, params, parsedConfig, account, logger)
+	if err != nil {
+		return err
+	}
+
+	r, err := newAccountRunner(c, n, parsedConfig, account, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := registerCheckpoint(c, r, account.ID(), c.Path("checkpoint"), logger); err != nil {
+		return err
+	}
+
+	return runWithReport(ctx, c, r, account, reportFormat, notifier, logger)
+}
+
+// setupAccount validates the credentials, parses the configuration and resolves the account that the credentials
+// belong to. It is the common setup of all commands that run against a single account.
+func setupAccount(c *cli.Context) (*config.Config, *awsutil.Account, *logrus.Logger, error) {
+	creds := ConfigureCreds(c)
+
+	if err := creds.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
 	logger := logrus.StandardLogger()
 	logger.SetOutput(os.Stdout)
 
 	// Parse the user supplied configuration file to pass in part to configure the nuke process.
+	parsedConfig, err := parseConfig(c, logger)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Set the default region for the AWS SDK to use.
+	if err := ConfigureDefaultRegion(c.String("default-region"), parsedConfig, logger); err != nil {
+		return nil, nil, nil, err
+	}
+
+	creds.RateLimits = parsedConfig.RateLimits
+
+	// Create the AWS Account object. This will be used to get the account ID and aliases for the account.
+	account, err := awsutil.NewAccount(creds, parsedConfig.CustomEndpoints)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	registerAlternativeResourceTypes(c, parsedConfig, []string{account.ID()})
+
+	return parsedConfig, account, logger, nil
+}
+
+// runWithReport runs the nuke process, writes the machine-readable report when one is requested and posts the summary
+// of the run to the configured webhooks.
+func runWithReport(
+	ctx context.Context, c *cli.Context, r *runner, account *awsutil.Account, reportFormat report.Format,
+	notifier *notify.Notifier, logger *logrus.Logger,
+) error {
+	// Create the run report before the run starts, so the duration of the run is accurately captured
+	runReport := report.New(account.ID(), account.Alias(), !r.nuke.Parameters.NoDryRun)
+	runReport.Estimator = r.estimator
+
+	runErr := r.Run(ctx)
+
+	// Write the machine-readable report and notify regardless of the outcome of the run, a failed run is still worth
+	// reporting.
+	runReport.Build(r.nuke.Queue, runErr)
+	notifyRun(ctx, notifier, runReport, logger)
+
+	if reportPath := c.Path("report"); reportPath != "" {
+		if err := runReport.WriteFile(reportPath, reportFormat); err != nil {
+			logger.WithError(err).Errorf("unable to write report to %s", reportPath)
+			if runErr == nil {
+				return err
+			}
+		}
+	}
+
+	return runErr
+}
+
+// parseConfig parses the configuration file given on the command line.
+func parseConfig(c *cli.Context, logger *logrus.Logger) (*config.Config, error) {
 	parsedConfig, err := config.New(libconfig.Options{
 		Path:         c.Path("config"),
 		Deprecations: registry.GetDeprecatedResourceTypeMapping(),
@@ -88,37 +221,44 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 	})
 	if err != nil {
 		logger.Errorf("Failed to parse config file %s", c.Path("config"))
-		return err
+		return nil, err
 	}
 
-	// Set the default region for the AWS SDK to use.
-	if defaultRegion != "" {
-		awsutil.DefaultRegionID = defaultRegion
-
-		partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), defaultRegion)
-		if !ok {
-			if parsedConfig.CustomEndpoints.GetRegion(defaultRegion) == nil {
-				err = fmt.Errorf(
-					"the custom region '%s' must be specified in the configuration 'endpoints'"+
-						" to determine its partition", defaultRegion)
-				logger.WithError(err).Errorf("unable to resolve partition for region: %s", defaultRegion)
-				return err
-			}
-		}
+	// Note: the problems are only warned about, the validate-config command reports them as errors
+	problems, err := config.Validate(c.Path("config"))
+	if err != nil {
+		return nil, err
+	}
 
-		awsutil.DefaultAWSPartitionID = partition.ID()
+	for _, problem := range problems {
+		logger.Warn(problem.String())
 	}
 
-	// Create the AWS Account object. This will be used to get the account ID and aliases for the account.
-	account, err := awsutil.NewAccount(creds, parsedConfig.CustomEndpoints)
-	if err != nil {
-		return err
+	return parsedConfig, nil
+}
+
+// reportFormatFromContext returns the report format requested on the command line after validating it.
+func reportFormatFromContext(c *cli.Context) (report.Format, error) {
+	reportFormat := report.Format(c.String("report-format"))
+	if reportFormat != report.FormatJSON && reportFormat != report.FormatNDJSON {
+		return "", fmt.Errorf("invalid report format '%s', must be one of: %s, %s",
+			reportFormat, report.FormatJSON, report.FormatNDJSON)
 	}
 
+	return reportFormat, nil
+}
+
+// newAccountNuke instantiates and configures libnuke for a single account. It resolves the filters, resource types
+// and regions from the configuration for the account and registers a scanner for every region. The returned instance
+// is ready to be run.
+func newAccountNuke( //nolint:funlen,gocyclo
+	c *cli.Context, params *libnuke.Parameters, parsedConfig *config.Config,
+	account *awsutil.Account, logger *logrus.Logger,
+) (*libnuke.Nuke, error) {
 	// Get the filters for the account that is being connected to via the AWS SDK.
 	filters, err := parsedConfig.Filters(account.ID())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Instantiate libnuke
@@ -133,6 +273,18 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 		return parsedConfig.ValidateAccount(account.ID(), account.Aliases(), c.Bool("no-alias-check"))
 	})
 
+	// Register the organization guard that refuses the management, delegated administrator and protected accounts
+	if parsedConfig.OrganizationGuard.IsEnabled() {
+		n.RegisterValidateHandler(func() error {
+			org, err := account.Organization()
+			if err != nil {
+				return err
+			}
+
+			return org.Guard(account.ID(), parsedConfig.OrganizationGuard.ProtectedOrganizationalUnits)
+		})
+	}
+
 	// Register our custom prompt handler that shows the account information
 	p := &nuke.Prompt{Parameters: params, Account: account, Logger: logger}
 	n.RegisterPrompt(p.Prompt)
@@ -140,26 +292,11 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 	// Get any specific account level configuration
 	accountConfig := parsedConfig.Accounts[account.ID()]
 
-	// Get current registered resource names
-	resourceNames := registry.GetNames()
-
-	// Combine all the places where alternative resource types can be defined and then dynamically
-	// register them as a Cloud Control resource type.
-	altResourceTypes := types.Collection(registry.ExpandNames(n.Parameters.Alternatives))
-	altResourceTypes = altResourceTypes.Union(parsedConfig.ResourceTypes.GetAlternatives())
-	altResourceTypes = altResourceTypes.Union(accountConfig.ResourceTypes.GetAlternatives())
-	for _, rt := range altResourceTypes {
-		if slices.Contains(resourceNames, rt) {
-			continue
-		}
-
-		resources.RegisterCloudControl(rt)
-	}
-
 	// Resolve the resource types to be used for the nuke process based on the parameters, global configuration, and
-	// account level configuration.
+	// account level configuration. The alternative resource types were registered before, see
+	// registerAlternativeResourceTypes.
 	resourceTypes := types.ResolveResourceTypes(
-		registry.GetNames(), // note: we want to re-pull the registry here due to the dynamic registration above
+		registry.GetNames(),
 		[]types.Collection{
 			registry.ExpandNames(n.Parameters.Includes),
 			parsedConfig.ResourceTypes.GetIncludes(),
@@ -178,10 +315,35 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 		registry.GetAlternativeResourceTypeMapping(),
 	)
 
+	// Protect the resources that the services of the protect profiles created.
+	n.Filters = parsedConfig.WithProtectProfiles(n.Filters, resourceTypes)
+
+	// Protect the resources with any of the protect tags, and warn about the resource types that cannot be protected
+	// that way, because they do not expose any tags. With tag enrichment the tags of the resources are looked up by
+	// their ARN, so the resource types that expose an ARN can be protected as well, but the ones that expose neither
+	// tags nor an ARN still cannot.
+	if c.Bool("tag-enrichment") {
+		n.Filters = parsedConfig.WithProtectTagsForAll(n.Filters, resourceTypes)
+		if unsupported := parsedConfig.ProtectTagsUnsupportedWithEnrichment(resourceTypes); len(unsupported) > 0 {
+			logger.Warnf("protect-tags cannot protect the following %d resource types as they do not expose tags "+
+				"or an ARN: %s", len(unsupported), strings.Join(unsupported, ", "))
+		}
+	} else {
+		n.Filters = parsedConfig.WithProtectTags(n.Filters, resourceTypes)
+		if unsupported := parsedConfig.ProtectTagsUnsupported(resourceTypes); len(unsupported) > 0 {
+			logger.Warnf("protect-tags cannot protect the following %d resource types as they do not expose tags: %s",
+				len(unsupported), strings.Join(unsupported, ", "))
+		}
+	}
+
+	// Note: the regions are resolved per account and not written back to the configuration, when running against
+	// multiple accounts the enabled regions can differ between them.
+	regions := parsedConfig.Regions
+
 	// If the user has specified the "all" region, then we need to get the enabled regions for the account
 	// and use those. Otherwise, we will use the regions that are specified in the configuration.
-	if slices.Contains(parsedConfig.Regions, "all") {
-		parsedConfig.Regions = account.Regions()
+	if slices.Contains(regions, "all") {
+		regions = account.Regions()
 
 		logger.Info(
 			`"all" detected in region list, only enabled regions and "global" will be used, all others ignored`)
@@ -190,22 +352,27 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 			logger.Warnf(`additional regions defined along with "all", these will be ignored!`)
 		}
 
-		logger.Infof("The following regions are enabled for the account (%d total):", len(parsedConfig.Regions))
+		logger.Infof("The following regions are enabled for the account (%d total):", len(regions))
 
 		printableRegions := make([]string, 0)
-		for i, region := range parsedConfig.Regions {
+		for i, region := range regions {
 			printableRegions = append(printableRegions, region)
 			if i%6 == 0 { // print 5 regions per line
 				logger.Infof("> %s", strings.Join(printableRegions, ", "))
 				printableRegions = make([]string, 0)
-			} else if i == len(parsedConfig.Regions)-1 {
+			} else if i == len(regions)-1 {
 				logger.Infof("> %s", strings.Join(printableRegions, ", "))
 			}
 		}
 	}
 
+	_, regionConcurrency, err := scanConcurrency(c)
+	if err != nil {
+		return nil, err
+	}
+
 	// Register the scanners for each region that is defined in the configuration.
-	for _, regionName := range parsedConfig.Regions {
+	for _, regionName := range regions {
 		// Step 1 - Create the region object
 		region := nuke.NewRegion(regionName, account.ResourceTypeToServiceType, account.NewSession, account.NewConfig)
 
@@ -219,27 +386,92 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 			}),
 		})
 		scannerActual.SetLogger(logger)
+		scannerActual.SetParallelQueries(int64(regionConcurrency))
 
 		// Step 3 - Register a mutate function that will be called to modify the lister options for each resource type
 		// see pkg/nuke/resource.go for the MutateOpts function. Its purpose is to create the proper session for the
 		// proper region.
 		regMutateErr := scannerActual.RegisterMutateOptsFunc(nuke.MutateOpts)
 		if regMutateErr != nil {
-			return regMutateErr
+			return nil, regMutateErr
 		}
 
 		// Step 4 - Register the scannerActual with the nuke object
 		regScanErr := n.RegisterScanner(nuke.Account, scannerActual)
 		if regScanErr != nil {
-			return regScanErr
+			return nil, regScanErr
+		}
+	}
+
+	return n, nil
+}
+
+// newAccountRunner creates the runner for the nuke instance of an account, with the hooks that add properties to the
+// scanned resources and filter them again registered.
+func newAccountRunner(
+	c *cli.Context, n *libnuke.Nuke, parsedConfig *config.Config, account *awsutil.Account, logger *logrus.Logger,
+) (*runner, error) {
+	r := newRunner(c, n, logger)
+
+	registerTagEnrichment(c, r, account, logger)
+
+	if err := registerCreatedAt(r, parsedConfig, logger); err != nil {
+		return nil, err
+	}
+
+	if err := registerCostEstimation(c, r, logger); err != nil {
+		return nil, err
+	}
+
+	registerArchival(r)
+	registerKMSKeyOrder(r)
+	registerMetrics(r, account)
+	registerTracing(r, account)
+
+	return r, nil
+}
+
+// registerAlternativeResourceTypes registers the alternative resource types of the command line, the configuration and
+// the given accounts as Cloud Control resource types. The registry is global and not safe for concurrent use, so they
+// have to be registered once, before any account is configured or scanned.
+func registerAlternativeResourceTypes(c *cli.Context, parsedConfig *config.Config, accountIDs []string) {
+	registerCloudControlTypes(alternativeResourceTypes(c.StringSlice("cloud-control"), parsedConfig, accountIDs))
+}
+
+// alternativeResourceTypes returns the union of the alternative resource types that are defined on the command line,
+// in the configuration and in the configuration of any of the given accounts.
+func alternativeResourceTypes(
+	alternatives []string, parsedConfig *config.Config, accountIDs []string,
+) types.Collection {
+	altResourceTypes := types.Collection(registry.ExpandNames(alternatives))
+	altResourceTypes = altResourceTypes.Union(parsedConfig.ResourceTypes.GetAlternatives())
+
+	for _, accountID := range accountIDs {
+		if accountConfig := parsedConfig.Accounts[accountID]; accountConfig != nil {
+			altResourceTypes = altResourceTypes.Union(accountConfig.ResourceTypes.GetAlternatives())
 		}
 	}
 
-	return n.Run(ctx)
+	return altResourceTypes
 }
 
-func init() { //nolint:funlen
-	flags := []cli.Flag{
+// registerCloudControlTypes registers the given resource types as Cloud Control resource types, unless a resource
+// with the same name is already registered.
+func registerCloudControlTypes(resourceTypes types.Collection) {
+	resourceNames := registry.GetNames()
+	for _, rt := range resourceTypes {
+		if slices.Contains(resourceNames, rt) {
+			continue
+		}
+
+		resources.RegisterCloudControl(rt)
+		resourceNames = append(resourceNames, rt)
+	}
+}
+
+// Flags returns the flags that are shared by all commands that run the nuke process.
+func Flags() []cli.Flag { //nolint:funlen
+	return []cli.Flag{
 		&cli.PathFlag{
 			Name:    "config",
 			Aliases: []string{"c"},
@@ -290,6 +522,18 @@ func init() { //nolint:funlen
 			Usage:   "time to sleep between run/loops of resource deletions, default is 5 seconds",
 			Value:   5 * time.Second,
 		},
+		&cli.IntFlag{
+			Name:    "max-concurrency",
+			EnvVars: []string{"AWS_NUKE_MAX_CONCURRENCY"},
+			Usage:   "maximum number of resource types that are listed at the same time across all regions",
+			Value:   scanner.DefaultParallelQueries,
+		},
+		&cli.IntFlag{
+			Name:    "region-concurrency",
+			EnvVars: []string{"AWS_NUKE_REGION_CONCURRENCY"},
+			Usage:   "maximum number of resource types that are listed at the same time within a region",
+			Value:   scanner.DefaultParallelQueries,
+		},
 		&cli.BoolFlag{
 			Name:  "no-alias-check",
 			Usage: "disable aws account alias check - requires entry in config as well",
@@ -298,6 +542,58 @@ func init() { //nolint:funlen
 			Name:  "feature-flag",
 			Usage: "enable experimental behaviors that may not be fully tested or supported",
 		},
+		&cli.PathFlag{
+			Name:  "report",
+			Usage: "write a machine-readable report of the run to this file",
+		},
+		&cli.StringFlag{
+			Name:  "report-format",
+			Usage: "the format of the report, either json or ndjson",
+			Value: string(report.FormatJSON),
+		},
+		&cli.PathFlag{
+			Name:  "checkpoint",
+			Usage: "write the progress of the run to this file so it can be resumed",
+		},
+		&cli.BoolFlag{
+			Name:  "resume",
+			Usage: "resume the run from the file given by --checkpoint",
+		},
+		&cli.BoolFlag{
+			Name:  "tag-enrichment",
+			Usage: "look up the tags of every resource with the resource groups tagging api and filter on them",
+		},
+		&cli.BoolFlag{
+			Name:    "estimate-cost",
+			EnvVars: []string{"AWS_NUKE_ESTIMATE_COST"},
+			Usage:   "estimate the monthly cost of the resources that would be removed from an offline pricing table",
+		},
+		&cli.PathFlag{
+			Name:    "pricing-table",
+			EnvVars: []string{"AWS_NUKE_PRICING_TABLE"},
+			Usage:   "add the prices of this pricing table file to the bundled pricing table of --estimate-cost",
+		},
+		&cli.StringFlag{
+			Name:    "metrics-address",
+			EnvVars: []string{"AWS_NUKE_METRICS_ADDRESS"},
+			Usage:   "serve prometheus metrics of the run on this address, for example :9090",
+		},
+		&cli.StringFlag{
+			Name:    "metrics-pushgateway",
+			EnvVars: []string{"AWS_NUKE_METRICS_PUSHGATEWAY"},
+			Usage:   "push prometheus metrics of the run to the pushgateway at this url once the run is complete",
+		},
+		&cli.StringFlag{
+			Name:    "metrics-job",
+			EnvVars: []string{"AWS_NUKE_METRICS_JOB"},
+			Usage:   "the job name to push the metrics to the pushgateway with",
+			Value:   "aws-nuke",
+		},
+		&cli.StringFlag{
+			Name:    "tracing-endpoint",
+			EnvVars: []string{"AWS_NUKE_TRACING_ENDPOINT"},
+			Usage:   "export opentelemetry traces of the run to the otlp/http endpoint at this url, for example http://localhost:4318",
+		},
 		&cli.StringFlag{
 			Name:    "default-region",
 			EnvVars: []string{"AWS_DEFAULT_REGION"},
@@ -339,14 +635,16 @@ func init() { //nolint:funlen
 			Usage:   "the external id to provide for the assumed role",
 		},
 	}
+}
 
+func init() {
 	cmd := &cli.Command{
 		Name:  "run",
 		Usage: "run nuke against an aws account and remove everything from it",
 		Aliases: []string{
 			"nuke",
 		},
-		Flags:  append(flags, global.Flags()...),
+		Flags:  append(Flags(), global.Flags()...),
 		Before: global.Before,
 		Action: execute,
 	}
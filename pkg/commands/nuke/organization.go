@@ -0,0 +1,289 @@
+package nuke
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/awsutil"
+	"github.com/ekristen/aws-nuke/v3/pkg/commands/global"
+	"github.com/ekristen/aws-nuke/v3/pkg/common"
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+	"github.com/ekristen/aws-nuke/v3/pkg/notify"
+	"github.com/ekristen/aws-nuke/v3/pkg/report"
+)
+
+// accountResult is the outcome of the nuke process against a single account when running against multiple accounts.
+type accountResult struct {
+	AccountID  string
+	Alias      string
+	SkipReason string
+	Report     *report.Report
+	Err        error
+}
+
+// State returns a short description of the outcome for the account.
+func (r *accountResult) State() string {
+	switch {
+	case r.SkipReason != "":
+		return "skipped"
+	case r.Err != nil:
+		return "failed"
+	default:
+		return "succeeded"
+	}
+}
+
+func executeOrganization(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	creds := ConfigureCreds(c)
+
+	if err := creds.Validate(); err != nil {
+		return err
+	}
+
+	reportFormat, err := reportFormatFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	// Create the parameters object that will be used to configure the nuke process for every account.
+	params := NewParameters(c)
+
+	logger := logrus.StandardLogger()
+	logger.SetOutput(os.Stdout)
+
+	parsedConfig, err := parseConfig(c, logger)
+	if err != nil {
+		return err
+	}
+
+	// Set the default region for the AWS SDK to use.
+	if err := ConfigureDefaultRegion(c.String("default-region"), parsedConfig, logger); err != nil {
+		return err
+	}
+
+	creds.CustomEndpoints = parsedConfig.CustomEndpoints
+	creds.RateLimits = parsedConfig.RateLimits
+
+	notifier, err := notify.New(parsedConfig.Notifications)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := startMetrics(c, creds, logger)
+	if err != nil {
+		return err
+	}
+	defer exporter.Stop()
+
+	tracer, err := startTracing(c, creds, logger)
+	if err != nil {
+		return err
+	}
+	defer tracer.Stop()
+
+	org, err := awsutil.NewOrganization(creds)
+	if err != nil {
+		return err
+	}
+
+	orgAccounts, err := org.Accounts(c.StringSlice("organization-unit"))
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("found %d active accounts in the organization", len(orgAccounts))
+
+	accountIDs := make([]string, 0, len(orgAccounts))
+	for _, orgAccount := range orgAccounts {
+		accountIDs = append(accountIDs, orgAccount.ID)
+	}
+	registerAlternativeResourceTypes(c, parsedConfig, accountIDs)
+
+	results := make([]*accountResult, 0, len(orgAccounts))
+	for _, orgAccount := range orgAccounts {
+		result := &accountResult{
+			AccountID: orgAccount.ID,
+			Alias:     orgAccount.Name,
+		}
+		results = append(results, result)
+
+		if parsedConfig.InBlocklist(orgAccount.ID) {
+			result.SkipReason = "account is in blocklist"
+			continue
+		}
+
+		if _, ok := parsedConfig.Accounts[orgAccount.ID]; !ok {
+			result.SkipReason = "account is not configured"
+			continue
+		}
+
+		logger.Infof("starting nuke of organization account %s (%s)", orgAccount.ID, orgAccount.Name)
+
+		memberCreds := creds.AssumeRole(
+			orgAccount.MemberRoleArn(c.String("organization-role-name")),
+			c.String("assume-role-session-name"),
+			c.String("organization-role-external-id"))
+
+		nukeAccount(ctx, c, params, parsedConfig, memberCreds, org, logger, reportFormat, notifier, result)
+	}
+
+	return summarizeAccounts(results, logger)
+}
+
+// nukeAccount runs the nuke process against a single account of a multi-account run and records the outcome on
+// the result. The report for the account is written to a dedicated file when requested, and the summary of the run
+// is posted to the configured webhooks.
+func nukeAccount(
+	ctx context.Context, c *cli.Context, params *libnuke.Parameters, parsedConfig *config.Config,
+	creds *awsutil.Credentials, org *awsutil.Organization, logger *logrus.Logger, reportFormat report.Format,
+	notifier *notify.Notifier, result *accountResult,
+) {
+	account, err := awsutil.NewAccount(creds, parsedConfig.CustomEndpoints)
+	if err != nil {
+		result.Err = err
+		return
+	}
+
+	// Note: the organization is accessed with the credentials of the management account, a member account cannot
+	// look up the delegated administrators and organizational units
+	account.SetOrganization(org)
+
+	// Guard against a role that resolves to a different account than the one that is expected to be nuked.
+	if account.ID() != result.AccountID {
+		result.Err = fmt.Errorf("credentials belong to account %s, expected %s", account.ID(), result.AccountID)
+		return
+	}
+
+	result.Alias = account.Alias()
+
+	n, err := newAccountNuke(c, params, parsedConfig, account, logger)
+	if err != nil {
+		result.Err = err
+		return
+	}
+
+	var checkpointPath string
+	if path := c.Path("checkpoint"); path != "" {
+		checkpointPath = report.PathForAccount(path, account.ID())
+	}
+
+	r, err := newAccountRunner(c, n, parsedConfig, account, logger)
+	if err != nil {
+		result.Err = err
+		return
+	}
+
+	if err := registerCheckpoint(c, r, account.ID(), checkpointPath, logger); err != nil {
+		result.Err = err
+		return
+	}
+
+	result.Report = report.New(account.ID(), account.Alias(), !params.NoDryRun)
+	result.Report.Estimator = r.estimator
+
+	result.Err = r.Run(ctx)
+
+	result.Report.Build(n.Queue, result.Err)
+	notifyRun(ctx, notifier, result.Report, logger)
+
+	if reportPath := c.Path("report"); reportPath != "" {
+		accountReportPath := report.PathForAccount(reportPath, account.ID())
+		if err := result.Report.WriteFile(accountReportPath, reportFormat); err != nil {
+			logger.WithError(err).Errorf("unable to write report to %s", accountReportPath)
+		}
+	}
+}
+
+// summarizeAccounts prints the consolidated summary of a multi-account run and returns an error if the nuke process
+// failed for any of the accounts.
+func summarizeAccounts(results []*accountResult, logger *logrus.Logger) error {
+	printLog := logger.WithField("_handler", "println")
+
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.State()]++
+	}
+
+	printLog.
+		WithFields(logrus.Fields{
+			"accounts":  len(results),
+			"succeeded": counts["succeeded"],
+			"failed":    counts["failed"],
+			"skipped":   counts["skipped"],
+		}).
+		Infof("Accounts complete: %d total, %d succeeded, %d failed, %d skipped.",
+			len(results), counts["succeeded"], counts["failed"], counts["skipped"])
+
+	for _, result := range results {
+		accountLog := printLog.WithFields(logrus.Fields{
+			"account": result.AccountID,
+			"alias":   result.Alias,
+			"state":   result.State(),
+		})
+
+		switch {
+		case result.SkipReason != "":
+			accountLog.Infof("> %s (%s): skipped - %s", result.AccountID, result.Alias, result.SkipReason)
+		case result.Report == nil:
+			accountLog.Errorf("> %s (%s): failed - %v", result.AccountID, result.Alias, result.Err)
+		default:
+			summary := result.Report.Summary
+			line := fmt.Sprintf("> %s (%s): %s - %d total, %d nukeable, %d filtered, %d removed, %d failed",
+				result.AccountID, result.Alias, result.State(),
+				summary.Total, summary.Nukeable, summary.Filtered, summary.Removed, summary.Failed)
+			if result.Err != nil {
+				accountLog.Errorf("%s - %v", line, result.Err)
+			} else {
+				accountLog.Info(line)
+			}
+		}
+	}
+
+	if counts["failed"] > 0 {
+		return fmt.Errorf("nuke failed for %d of %d accounts", counts["failed"], len(results))
+	}
+
+	return nil
+}
+
+func init() {
+	flags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "organization-unit",
+			Usage: "only run against the accounts within these organizational units (including nested units)",
+		},
+		&cli.StringFlag{
+			Name:  "organization-role-name",
+			Usage: "the name of the role to assume in each member account",
+			Value: "OrganizationAccountAccessRole",
+		},
+		&cli.StringFlag{
+			Name:  "organization-role-external-id",
+			Usage: "the external id to provide when assuming the role in each member account",
+		},
+	}
+
+	cmd := &cli.Command{
+		Name:    "run-organization",
+		Aliases: []string{"run-org"},
+		Usage:   "run nuke against the member accounts of an aws organization",
+		Description: `run nuke against every active member account of the aws organization that the credentials belong to,
+optionally limited to one or more organizational units. For each account the role given by --organization-role-name
+is assumed and the account is nuked using its own accounts block from the configuration. Accounts that are not
+configured or are in the blocklist are skipped. A consolidated summary is printed at the end.`,
+		Flags:  append(append(flags, Flags()...), global.Flags()...),
+		Before: global.Before,
+		Action: executeOrganization,
+	}
+
+	common.RegisterCommand(cmd)
+}
@@ -0,0 +1,216 @@
+package nuke
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/commands/global"
+	"github.com/ekristen/aws-nuke/v3/pkg/common"
+	"github.com/ekristen/aws-nuke/v3/pkg/notify"
+	"github.com/ekristen/aws-nuke/v3/pkg/plan"
+)
+
+func executePlan(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	out := c.Path("out")
+	if out == "" {
+		return fmt.Errorf("--out is required")
+	}
+
+	// A plan is always created from a dry-run
+	params := NewParameters(c)
+	params.NoDryRun = false
+
+	parsedConfig, account, logger, err := setupAccount(c)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := startMetrics(c, account.Credentials, logger)
+	if err != nil {
+		return err
+	}
+	defer exporter.Stop()
+
+	tracer, err := startTracing(c, account.Credentials, logger)
+	if err != nil {
+		return err
+	}
+	defer tracer.Stop()
+
+	n, err := newAccountNuke(c, params, parsedConfig, account, logger)
+	if err != nil {
+		return err
+	}
+
+	r, err := newAccountRunner(c, n, parsedConfig, account, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Run(ctx); err != nil {
+		return err
+	}
+
+	p := plan.New(account.ID(), account.Alias(), n.Queue)
+	if err := p.WriteFile(out); err != nil {
+		return err
+	}
+
+	logger.WithField("_handler", "println").
+		Infof("Plan written to %s: %d resources would be removed.", out, len(p.Items))
+
+	return nil
+}
+
+func executeApply(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("the path to exactly one plan file is required")
+	}
+
+	reportFormat, err := reportFormatFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	p, err := plan.Load(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	// Applying a plan always removes resources, and only the resource types in the plan need to be scanned
+	params := NewParameters(c)
+	params.NoDryRun = true
+	params.Includes = p.ResourceTypes()
+
+	parsedConfig, account, logger, err := setupAccount(c)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := notify.New(parsedConfig.Notifications)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := startMetrics(c, account.Credentials, logger)
+	if err != nil {
+		return err
+	}
+	defer exporter.Stop()
+
+	tracer, err := startTracing(c, account.Credentials, logger)
+	if err != nil {
+		return err
+	}
+	defer tracer.Stop()
+
+	if account.ID() != p.AccountID {
+		return fmt.Errorf("plan belongs to account %s, not %s", p.AccountID, account.ID())
+	}
+
+	if len(p.Items) == 0 {
+		logger.WithField("_handler", "println").Info("No resource to delete.")
+		return nil
+	}
+
+	n, err := newAccountNuke(c, params, parsedConfig, account, logger)
+	if err != nil {
+		return err
+	}
+
+	r, err := newAccountRunner(c, n, parsedConfig, account, logger)
+	if err != nil {
+		return err
+	}
+
+	r.OnAfterScan(func(q *queue.Queue) error {
+		return verifyPlan(p, q, c.Bool("strict"), logger)
+	})
+
+	if err := registerCheckpoint(c, r, account.ID(), c.Path("checkpoint"), logger); err != nil {
+		return err
+	}
+
+	return runWithReport(ctx, c, r, account, reportFormat, notifier, logger)
+}
+
+// verifyPlan restricts the queue to the resources in the plan and reports the planned resources that changed or no
+// longer exist. In strict mode any difference aborts the apply before anything is removed.
+func verifyPlan(p *plan.Plan, q *queue.Queue, strict bool, logger *logrus.Logger) error {
+	result := p.Verify(q)
+
+	printLog := logger.WithField("_handler", "println")
+
+	for _, item := range result.Changed {
+		printLog.Warnf("%s - %s - %s - %s, skipping", item.Region, item.ResourceType, item.Identity, plan.ReasonChanged)
+	}
+
+	for _, item := range result.Missing {
+		printLog.Warnf("%s - %s - %s - no longer exists or is filtered, skipping",
+			item.Region, item.ResourceType, item.Identity)
+	}
+
+	printLog.
+		WithFields(logrus.Fields{
+			"planned": len(p.Items),
+			"matched": result.Matched,
+			"changed": len(result.Changed),
+			"missing": len(result.Missing),
+		}).
+		Infof("Plan verified: %d planned, %d matched, %d changed, %d missing.",
+			len(p.Items), result.Matched, len(result.Changed), len(result.Missing))
+
+	if strict && (len(result.Changed) > 0 || len(result.Missing) > 0) {
+		return fmt.Errorf("the account no longer matches the plan, aborting without removing anything")
+	}
+
+	return nil
+}
+
+func init() {
+	planCmd := &cli.Command{
+		Name:  "plan",
+		Usage: "save the resources that a dry-run would remove to a plan file",
+		Description: `plan performs a dry-run against the account and writes every resource that would be removed to the
+file given by --out. The plan can be reviewed and later removed with the apply command.`,
+		Flags: append(append([]cli.Flag{
+			&cli.PathFlag{
+				Name:  "out",
+				Usage: "the file to write the plan to",
+			},
+		}, Flags()...), global.Flags()...),
+		Before: global.Before,
+		Action: executePlan,
+	}
+
+	applyCmd := &cli.Command{
+		Name:      "apply",
+		Usage:     "remove exactly the resources in a plan file",
+		ArgsUsage: "<plan-file>",
+		Description: `apply removes the resources in a plan file created by the plan command. The account is scanned again
+and only resources that are part of the plan and are unchanged since the plan was created are removed. Resources that
+are not part of the plan are never removed.`,
+		Flags: append(append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "abort without removing anything if any planned resource changed or no longer exists",
+			},
+		}, Flags()...), global.Flags()...),
+		Before: global.Before,
+		Action: executeApply,
+	}
+
+	common.RegisterCommand(planCmd)
+	common.RegisterCommand(applyCmd)
+}
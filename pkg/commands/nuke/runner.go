@@ -0,0 +1,332 @@
+package nuke
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/scanner"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/report"
+)
+
+// runner runs the nuke process like libnuke does, with the addition of hooks that are called after the scan and
+// after every pass over the queue, of scanning regions in parallel and of tracing the phases of the run. libnuke does
+// not expose a hook into its run loop, so the loop is replicated here using the exported handlers. Without any hooks,
+// with one region scanned at a time and without tracing the run is handed to libnuke as is.
+type runner struct {
+	nuke     *libnuke.Nuke
+	runSleep time.Duration
+	log      *logrus.Entry
+
+	// parallelRegions is the number of regions that are scanned at the same time.
+	parallelRegions int
+
+	// regionConcurrency is the number of resource types that are listed at the same time within a region.
+	regionConcurrency int
+
+	// afterScan is called once the scan is complete, before anything is removed. It may change the state of items.
+	afterScan []func(q *queue.Queue) error
+
+	// afterPass is called after the scan and after every pass over the queue.
+	afterPass []func(q *queue.Queue) error
+
+	// afterRegionScan is called once the scan of a region is complete, with how long it took.
+	afterRegionScan []func(region string, duration time.Duration)
+
+	// tracer starts the spans of the phases of the run, it is nil when the run is not traced.
+	tracer trace.Tracer
+
+	// traceAttributes are the attributes of the span of the run.
+	traceAttributes []attribute.KeyValue
+
+	// traceParents are the parents of the spans of the AWS API calls that are made without a context, they are set to
+	// the phase of the run that makes the calls. It is nil when the run is not traced.
+	traceParents traceParents
+
+	// estimator estimates the monthly cost of the resources in the report of the run, it is nil when costs are not
+	// estimated.
+	estimator report.Estimator
+
+	failedCount  int
+	waitingCount int
+}
+
+// newRunner creates a runner for the nuke instance without any hooks.
+func newRunner(c *cli.Context, n *libnuke.Nuke, logger *logrus.Logger) *runner {
+	_, regionConcurrency, err := scanConcurrency(c)
+	if err != nil {
+		regionConcurrency = scanner.DefaultParallelQueries
+	}
+
+	return &runner{
+		nuke:              n,
+		runSleep:          c.Duration("run-sleep-delay"),
+		log:               logger.WithField("component", "libnuke"),
+		parallelRegions:   parallelRegions(c),
+		regionConcurrency: regionConcurrency,
+	}
+}
+
+// OnAfterScan registers a hook that is called once the scan is complete.
+func (r *runner) OnAfterScan(hook func(q *queue.Queue) error) {
+	r.afterScan = append(r.afterScan, hook)
+}
+
+// OnAfterPass registers a hook that is called after the scan and after every pass over the queue.
+func (r *runner) OnAfterPass(hook func(q *queue.Queue) error) {
+	r.afterPass = append(r.afterPass, hook)
+}
+
+// OnAfterRegionScan registers a hook that is called once the scan of a region is complete.
+func (r *runner) OnAfterRegionScan(hook func(region string, duration time.Duration)) {
+	r.afterRegionScan = append(r.afterRegionScan, hook)
+}
+
+// Run validates, prompts, scans and then processes the queue until every resource is removed or the run fails.
+func (r *runner) Run(ctx context.Context) error {
+	if len(r.afterScan) == 0 && len(r.afterPass) == 0 && len(r.afterRegionScan) == 0 && r.parallelRegions <= 1 &&
+		r.tracer == nil {
+		return r.nuke.Run(ctx)
+	}
+
+	ctx, span := r.startSpan(ctx, "nuke", r.traceAttributes...)
+	r.setTraceParent(ctx, "")
+	err := r.run(ctx)
+	r.clearTraceParent("")
+	endSpan(span, err)
+
+	return err
+}
+
+// run replicates the run loop of libnuke within the span of the run.
+func (r *runner) run(ctx context.Context) error { //nolint:gocyclo
+	n := r.nuke
+
+	n.Version()
+
+	printLog := r.log.WithField("_handler", "println")
+
+	_, span := r.startSpan(ctx, "validate")
+	err := n.Validate()
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+
+	if err := n.Prompt(); err != nil {
+		return err
+	}
+
+	printLog.Info("starting scan for resources")
+
+	scanCtx, span := r.startSpan(ctx, "scan")
+	r.setTraceParent(scanCtx, "")
+	err = r.scan(scanCtx)
+	r.setTraceParent(ctx, "")
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range r.afterScan {
+		if err := hook(n.Queue); err != nil {
+			return err
+		}
+	}
+
+	if err := r.handlePass(); err != nil {
+		return err
+	}
+
+	if n.Queue.Count(queue.ItemStateNew, queue.ItemStateNewDependency, queue.ItemStatePending) == 0 {
+		printLog.Info("No resource to delete.")
+		return nil
+	}
+
+	if !n.Parameters.NoDryRun {
+		printLog.Info("The above resources would be deleted with the supplied configuration. Provide --no-dry-run to actually destroy resources.")
+		return nil
+	}
+
+	if err := n.Prompt(); err != nil {
+		return err
+	}
+
+	if r.runSleep == 0 {
+		r.runSleep = 5 * time.Second
+	}
+
+	for pass := 1; ; pass++ {
+		passCtx, span := r.startSpan(ctx, "remove", attribute.Int("aws_nuke.pass", pass))
+		r.setTraceParent(passCtx, "")
+		n.HandleQueue(passCtx)
+		r.setTraceParent(ctx, "")
+		span.SetAttributes(
+			attribute.Int("aws_nuke.waiting", n.Queue.Count(queue.ItemStateWaiting, queue.ItemStatePending)),
+			attribute.Int("aws_nuke.failed", n.Queue.Count(queue.ItemStateFailed)),
+			attribute.Int("aws_nuke.finished", n.Queue.Count(queue.ItemStateFinished)),
+		)
+		span.End()
+
+		if err := r.handlePass(); err != nil {
+			return err
+		}
+
+		if err := r.handleFailure(); err != nil {
+			return err
+		}
+
+		if err := r.handleWaiting(); err != nil {
+			return err
+		}
+
+		unfinishedCount := n.Queue.Count(queue.ItemStateNew, queue.ItemStateNewDependency,
+			queue.ItemStatePending, queue.ItemStatePendingDependency, queue.ItemStateFailed,
+			queue.ItemStateWaiting, queue.ItemStateHold,
+		)
+
+		if unfinishedCount == 0 {
+			break
+		}
+
+		_, span = r.startSpan(ctx, "wait", attribute.Int("aws_nuke.pass", pass))
+		time.Sleep(r.runSleep)
+		span.End()
+	}
+
+	printLog.
+		WithFields(logrus.Fields{
+			"failed":   n.Queue.Count(queue.ItemStateFailed),
+			"skipped":  n.Queue.Count(queue.ItemStateFiltered),
+			"finished": n.Queue.Count(queue.ItemStateFinished),
+		}).
+		Infof("Nuke complete: %d failed, %d skipped, %d finished.\n",
+			n.Queue.Count(queue.ItemStateFailed), n.Queue.Count(queue.ItemStateFiltered),
+			n.Queue.Count(queue.ItemStateFinished))
+
+	return nil
+}
+
+// startSpan starts the span of a phase of the run as a child of the span in the context, when the run is traced.
+func (r *runner) startSpan(
+	ctx context.Context, name string, attributes ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	if r.tracer == nil {
+		return ctx, noop.Span{}
+	}
+
+	return r.tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// traceParents sets the parents of the spans of the AWS API calls that are made without a context, see
+// awsutil.Credentials.SetTraceParent.
+type traceParents interface {
+	SetTraceParent(ctx context.Context, region string)
+	ClearTraceParent(region string)
+}
+
+// setTraceParent sets the phase of the run in the context as the parent of the spans of the AWS API calls of the
+// region, or of every region when the region is empty, when the run is traced.
+func (r *runner) setTraceParent(ctx context.Context, region string) {
+	if r.traceParents != nil {
+		r.traceParents.SetTraceParent(ctx, region)
+	}
+}
+
+// clearTraceParent removes the parent of the spans of the AWS API calls of the region, when the run is traced.
+func (r *runner) clearTraceParent(region string) {
+	if r.traceParents != nil {
+		r.traceParents.ClearTraceParent(region)
+	}
+}
+
+// endSpan ends the span of a phase of the run and records the error the phase failed with.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// handlePass calls the hooks that are registered to run after every pass over the queue.
+func (r *runner) handlePass() error {
+	for _, hook := range r.afterPass {
+		if err := hook(r.nuke.Queue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleFailure mirrors libnuke, it fails the run once only failed resources are left and retrying them twice did not
+// make any progress.
+func (r *runner) handleFailure() error {
+	q := r.nuke.Queue
+
+	processingCount := q.Count(queue.ItemStatePending, queue.ItemStatePendingDependency, queue.ItemStateHold,
+		queue.ItemStateWaiting, queue.ItemStateNew, queue.ItemStateNewDependency)
+	failedCount := q.Count(queue.ItemStateFailed)
+
+	if processingCount > 0 || failedCount == 0 {
+		r.failedCount = 0
+		return nil
+	}
+
+	if r.failedCount < 2 {
+		r.failedCount++
+		return nil
+	}
+
+	printLog := r.log.WithField("_handler", "println")
+	printLog.Errorf("There are resources in failed state, but none are ready for deletion, anymore.")
+
+	for _, item := range q.GetItems() {
+		if item.GetState() != queue.ItemStateFailed {
+			continue
+		}
+
+		item.Print()
+		printLog.Error(item.GetReason())
+	}
+
+	return fmt.Errorf("failed")
+}
+
+// handleWaiting mirrors libnuke, it fails the run once resources have been waited on for more than MaxWaitRetries
+// passes over the queue.
+func (r *runner) handleWaiting() error {
+	maxWaitRetries := r.nuke.Parameters.MaxWaitRetries
+	if maxWaitRetries == 0 {
+		return nil
+	}
+
+	q := r.nuke.Queue
+	pendingCount := q.Count(queue.ItemStateWaiting, queue.ItemStatePending,
+		queue.ItemStatePendingDependency, queue.ItemStateHold)
+	newCount := q.Count(queue.ItemStateNew, queue.ItemStateNewDependency)
+
+	if pendingCount == 0 || newCount > 0 {
+		r.waitingCount = 0
+		return nil
+	}
+
+	if r.waitingCount >= maxWaitRetries {
+		return fmt.Errorf("max wait retries of %d exceeded", maxWaitRetries)
+	}
+	r.waitingCount++
+
+	return nil
+}
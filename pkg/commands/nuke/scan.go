@@ -0,0 +1,264 @@
+package nuke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"golang.org/x/sync/semaphore"
+
+	liberrors "github.com/ekristen/libnuke/pkg/errors"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/scanner"
+	"github.com/ekristen/libnuke/pkg/utils"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+)
+
+// scanConcurrency returns the number of List calls that may be in flight at the same time across all regions and
+// within a single region. The concurrency of a region never exceeds the overall concurrency.
+func scanConcurrency(c *cli.Context) (maxConcurrency, regionConcurrency int, err error) {
+	maxConcurrency = c.Int("max-concurrency")
+	if maxConcurrency < 1 {
+		return 0, 0, fmt.Errorf("invalid max-concurrency %d, must be at least 1", maxConcurrency)
+	}
+
+	regionConcurrency = c.Int("region-concurrency")
+	if regionConcurrency < 1 {
+		return 0, 0, fmt.Errorf("invalid region-concurrency %d, must be at least 1", regionConcurrency)
+	}
+
+	return maxConcurrency, min(regionConcurrency, maxConcurrency), nil
+}
+
+// parallelRegions returns the number of regions that are scanned at the same time, so that the List calls of all the
+// regions that are scanned stay within the overall concurrency.
+func parallelRegions(c *cli.Context) int {
+	maxConcurrency, regionConcurrency, err := scanConcurrency(c)
+	if err != nil {
+		return 1
+	}
+
+	return max(maxConcurrency/regionConcurrency, 1)
+}
+
+// scan scans the regions for resources like libnuke does, but scans as many regions at the same time as the
+// concurrency allows and calls the hooks once the scan of a region is complete. libnuke scans one region after the
+// other. The items of every region are still filtered and printed in the order of the regions, once the scan of the
+// region is complete.
+func (r *runner) scan(ctx context.Context) error {
+	n := r.nuke
+
+	if r.parallelRegions <= 1 && len(r.afterRegionScan) == 0 && r.tracer == nil {
+		return n.Scan(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var scanners []*scanner.Scanner
+	for _, scopeScanners := range n.Scanners {
+		scanners = append(scanners, scopeScanners...)
+	}
+
+	slots := make(chan struct{}, max(r.parallelRegions, 1))
+	results := make([]chan scanResult, len(scanners))
+
+	for i, s := range scanners {
+		results[i] = make(chan scanResult, 1)
+
+		go func(s *scanner.Scanner, result chan<- scanResult) {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				result <- scanResult{err: ctx.Err()}
+				return
+			}
+			defer func() { <-slots }()
+
+			regionCtx, span := r.startSpan(ctx, "scan region", semconv.CloudRegion(s.Owner))
+			r.setTraceParent(regionCtx, s.Owner)
+			start := time.Now()
+
+			var err error
+			if r.tracer != nil {
+				err = r.runScanner(regionCtx, s)
+			} else {
+				err = s.Run(regionCtx)
+			}
+
+			r.clearTraceParent(s.Owner)
+			endSpan(span, err)
+			result <- scanResult{err: err, duration: time.Since(start)}
+		}(s, results[i])
+	}
+
+	itemQueue := queue.New()
+
+	for i, s := range scanners {
+		result := <-results[i]
+		if result.err != nil {
+			return result.err
+		}
+
+		for _, hook := range r.afterRegionScan {
+			hook(s.Owner, result.duration)
+		}
+
+		_, span := r.startSpan(ctx, "filter", semconv.CloudRegion(s.Owner))
+		for item := range s.Items {
+			if err := r.enqueue(itemQueue, item); err != nil {
+				endSpan(span, err)
+				return err
+			}
+		}
+		span.End()
+	}
+
+	count := itemQueue.Count(queue.ItemStateNew, queue.ItemStateNewDependency)
+
+	r.log.WithField("_handler", "println").
+		WithFields(logrus.Fields{
+			"total":    itemQueue.Total(),
+			"nukeable": count,
+			"filtered": itemQueue.Count(queue.ItemStateFiltered),
+		}).
+		Infof("Scan complete: %d total, %d nukeable, %d filtered.\n",
+			itemQueue.Total(), count, itemQueue.Count(queue.ItemStateFiltered))
+
+	n.Queue = itemQueue
+
+	return nil
+}
+
+// runScanner mirrors the scanner of libnuke, it lists the resource types of the region of the scanner and sends the
+// items to the scanner, but with a span for every resource type. It is only used when the run is traced, libnuke does
+// not expose a hook around the lister of a resource type.
+func (r *runner) runScanner(ctx context.Context, s *scanner.Scanner) error {
+	sem := semaphore.NewWeighted(int64(r.regionConcurrency))
+
+	for _, resourceType := range s.ResourceTypes {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			return err
+		}
+
+		opts := nuke.MutateOpts(s.Options, resourceType)
+
+		go func(resourceType string) {
+			defer sem.Release(1)
+			r.list(ctx, s, resourceType, opts)
+		}(resourceType)
+	}
+
+	// Wait for all listers to finish.
+	if err := sem.Acquire(ctx, int64(r.regionConcurrency)); err != nil {
+		return err
+	}
+
+	close(s.Items)
+
+	return nil
+}
+
+// list mirrors libnuke, it lists the resources of a resource type and sends them to the scanner as new items.
+func (r *runner) list(ctx context.Context, s *scanner.Scanner, resourceType string, opts interface{}) {
+	ctx, span := r.startSpan(ctx, "list", semconv.CloudRegion(s.Owner),
+		attribute.String("aws_nuke.resource_type", resourceType))
+	defer span.End()
+
+	logger := r.log.Logger.WithField("resource_type", resourceType).WithField("owner", s.Owner)
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			err := fmt.Errorf("%v\n\n%s", rec, string(debug.Stack()))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "listing failed")
+			logger.Errorf("listing failed:\n%s", utils.Indent(err.Error(), "    "))
+		}
+	}()
+
+	lister := registry.GetLister(resourceType)
+	if lister == nil {
+		logger.Error("lister for resource type not found")
+		return
+	}
+
+	rs, err := lister.List(ctx, opts)
+	if err != nil {
+		var errSkipRequest liberrors.ErrSkipRequest
+		var errUnknownEndpoint liberrors.ErrUnknownEndpoint
+		if errors.As(err, &errSkipRequest) || errors.As(err, &errUnknownEndpoint) {
+			span.SetAttributes(attribute.Bool("aws_nuke.skipped", true))
+			logger.Debugf("skipping request: %v", err)
+			return
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.WithError(err).Errorf("listing failed:\n%s", utils.Indent(fmt.Sprintf("%v", err), "    "))
+		return
+	}
+
+	span.SetAttributes(attribute.Int("aws_nuke.count", len(rs)))
+
+	for _, res := range rs {
+		item := &queue.Item{
+			Resource: res,
+			State:    queue.ItemStateNew,
+			Type:     resourceType,
+			Owner:    s.Owner,
+			Opts:     opts,
+			Logger:   r.log.Logger,
+		}
+
+		if itemHook, ok := res.(resource.QueueItemHook); ok {
+			itemHook.BeforeEnqueue(item)
+		}
+
+		s.Items <- item
+	}
+}
+
+// scanResult is the outcome of the scan of a region.
+type scanResult struct {
+	err      error
+	duration time.Duration
+}
+
+// enqueue mirrors libnuke, it adds a scanned item to the queue, filters it and prints it.
+func (r *runner) enqueue(itemQueue *queue.Queue, item *queue.Item) error {
+	n := r.nuke
+
+	if n.Parameters.WaitOnDependencies {
+		reg := registry.GetRegistration(item.Type)
+		if len(reg.DependsOn) > 0 {
+			item.State = queue.ItemStateNewDependency
+		}
+	}
+
+	if sGetter, ok := item.Resource.(resource.SettingsGetter); ok {
+		sGetter.Settings(n.Settings.Get(item.Type))
+	}
+
+	itemQueue.Items = append(itemQueue.Items, item)
+	if err := n.Filter(item); err != nil {
+		return err
+	}
+
+	if n.Parameters.Quiet && item.State == queue.ItemStateFiltered {
+		return nil
+	}
+
+	item.Print()
+
+	return nil
+}
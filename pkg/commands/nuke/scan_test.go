@@ -0,0 +1,251 @@
+package nuke
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/scanner"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+)
+
+const testScanResourceType = "TestScanResource"
+
+type testScanResource struct {
+	Region string
+	Name   string
+}
+
+func (r *testScanResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testScanResource) Properties() types.Properties {
+	return types.NewProperties().Set("Region", r.Region).Set("Name", r.Name)
+}
+
+// newTestScanOpts returns lister options of the region whose sessions and configs are empty, so that the options can
+// be mutated like they are for a real scan.
+func newTestScanOpts(region string) *nuke.ListerOpts {
+	return &nuke.ListerOpts{
+		Region: nuke.NewRegion(region,
+			func(_, _ string) string {
+				return "test"
+			},
+			func(_, _ string) (*session.Session, error) {
+				return &session.Session{}, nil
+			},
+			func(_ context.Context, _, _ string) (*awsv2.Config, error) {
+				return &awsv2.Config{}, nil
+			}),
+	}
+}
+
+// testScanLister lists a few resources per region. It waits until the expected number of regions have been listed at
+// the same time, to check that the regions are scanned in parallel, and records the most regions listed at once.
+type testScanLister struct {
+	lock     sync.Mutex
+	wait     int
+	inFlight int
+	maximum  int
+}
+
+func (l *testScanLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*nuke.ListerOpts)
+
+	l.lock.Lock()
+	l.inFlight++
+	l.maximum = max(l.maximum, l.inFlight)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for l.maximum < l.wait && time.Now().Before(deadline) {
+		l.lock.Unlock()
+		time.Sleep(time.Millisecond)
+		l.lock.Lock()
+	}
+	l.lock.Unlock()
+
+	resources := make([]resource.Resource, 0, 3)
+	for i := 0; i < 3; i++ {
+		resources = append(resources, &testScanResource{Region: opts.Region.Name, Name: fmt.Sprintf("resource-%d", i)})
+	}
+
+	l.lock.Lock()
+	l.inFlight--
+	l.lock.Unlock()
+
+	return resources, nil
+}
+
+var testScanListerInstance = func() *testScanLister {
+	l := &testScanLister{}
+
+	registry.Register(&registry.Registration{
+		Name:     testScanResourceType,
+		Scope:    "test",
+		Resource: &testScanResource{},
+		Lister:   l,
+	})
+
+	return l
+}()
+
+// testTraceParents records the regions whose trace parent is set.
+type testTraceParents struct {
+	lock    sync.Mutex
+	set     []string
+	parents map[string]context.Context
+}
+
+func (p *testTraceParents) SetTraceParent(ctx context.Context, region string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.set = append(p.set, region)
+	p.parents[region] = ctx
+}
+
+func (p *testTraceParents) ClearTraceParent(region string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.parents, region)
+}
+
+func newScanContext(maxConcurrency, regionConcurrency int) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Int("max-concurrency", maxConcurrency, "")
+	set.Int("region-concurrency", regionConcurrency, "")
+
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestParallelRegions(t *testing.T) {
+	cases := []struct {
+		name              string
+		maxConcurrency    int
+		regionConcurrency int
+		want              int
+	}{
+		{name: "defaults", maxConcurrency: 10, regionConcurrency: 10, want: 1},
+		{name: "multiple", maxConcurrency: 20, regionConcurrency: 5, want: 4},
+		{name: "remainder", maxConcurrency: 7, regionConcurrency: 2, want: 3},
+		{name: "region above max", maxConcurrency: 4, regionConcurrency: 10, want: 1},
+		{name: "single", maxConcurrency: 1, regionConcurrency: 1, want: 1},
+		{name: "invalid max", maxConcurrency: 0, regionConcurrency: 5, want: 1},
+		{name: "invalid region", maxConcurrency: 10, regionConcurrency: 0, want: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parallelRegions(newScanContext(tc.maxConcurrency, tc.regionConcurrency)))
+		})
+	}
+}
+
+func TestScanConcurrency(t *testing.T) {
+	maxConcurrency, regionConcurrency, err := scanConcurrency(newScanContext(4, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, maxConcurrency)
+	assert.Equal(t, 4, regionConcurrency)
+
+	_, _, err = scanConcurrency(newScanContext(0, 10))
+	assert.ErrorContains(t, err, "invalid max-concurrency 0")
+
+	_, _, err = scanConcurrency(newScanContext(10, -1))
+	assert.ErrorContains(t, err, "invalid region-concurrency -1")
+}
+
+func TestRunner_ScanParallel(t *testing.T) {
+	for _, traced := range []bool{false, true} {
+		t.Run(fmt.Sprintf("traced=%t", traced), func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+
+			regions := []string{"us-east-1", "us-west-2", "eu-west-1", "eu-central-1", "ap-southeast-2"}
+
+			n := libnuke.New(&libnuke.Parameters{Quiet: true}, filter.Filters{
+				testScanResourceType: []filter.Filter{{Property: "Name", Value: "resource-0"}},
+			}, nil)
+			n.SetLogger(logger.WithField("test", true))
+
+			for _, region := range regions {
+				s := scanner.New(region, []string{testScanResourceType}, newTestScanOpts(region))
+				s.SetLogger(logger)
+				assert.NoError(t, n.RegisterScanner("test", s))
+			}
+
+			parallel := 3
+
+			lister := testScanListerInstance
+			lister.lock.Lock()
+			lister.wait = parallel
+			lister.maximum = 0
+			lister.lock.Unlock()
+
+			var scanned []string
+			var lock sync.Mutex
+
+			r := newRunner(newScanContext(parallel*2, 2), n, logger)
+			r.OnAfterRegionScan(func(region string, _ time.Duration) {
+				lock.Lock()
+				defer lock.Unlock()
+				scanned = append(scanned, region)
+			})
+			parents := &testTraceParents{parents: map[string]context.Context{}}
+			if traced {
+				r.tracer = noop.NewTracerProvider().Tracer("test")
+				r.traceParents = parents
+			}
+
+			assert.Equal(t, parallel, r.parallelRegions)
+			assert.NoError(t, r.scan(context.Background()))
+
+			// Note: the scan of every region is the parent of the calls of the region while it is scanned
+			if traced {
+				assert.ElementsMatch(t, regions, parents.set)
+				assert.Empty(t, parents.parents)
+			}
+
+			// Note: the regions are scanned at the same time, but the hooks and the queue follow the order of the
+			// regions
+			assert.Equal(t, parallel, lister.maximum)
+			assert.Equal(t, regions, scanned)
+
+			assert.Equal(t, len(regions)*3, n.Queue.Total())
+
+			seen := map[string]bool{}
+			for i, item := range n.Queue.Items {
+				props := item.Resource.(resource.PropertyGetter).Properties()
+				key := fmt.Sprintf("%s/%s", props.Get("Region"), props.Get("Name"))
+
+				assert.False(t, seen[key], "duplicate item %s", key)
+				seen[key] = true
+
+				assert.Equal(t, regions[i/3], item.Owner)
+				assert.Equal(t, item.Owner, props.Get("Region"))
+			}
+
+			assert.Equal(t, len(regions), n.Queue.Count(queue.ItemStateFiltered))
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package nuke
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/awsutil"
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+)
+
+// registerTagEnrichment registers the hook on the runner that looks up the tags of the scanned resources with the
+// Resource Groups Tagging API and filters the resources again with those tags added to their properties.
+func registerTagEnrichment(c *cli.Context, r *runner, account *awsutil.Account, logger *logrus.Logger) {
+	if !c.Bool("tag-enrichment") {
+		return
+	}
+
+	r.OnAfterScan(func(q *queue.Queue) error {
+		return enrichTags(r.nuke, q, newTaggingSvc(account), logger)
+	})
+}
+
+// taggingSvcFunc returns the Resource Groups Tagging API client of a region.
+type taggingSvcFunc func(region string) (resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI, error)
+
+// newTaggingSvc returns a taggingSvcFunc that creates the clients with the sessions of the account.
+func newTaggingSvc(account *awsutil.Account) taggingSvcFunc {
+	return func(region string) (resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI, error) {
+		sess, err := account.NewSession(region, resourcegroupstaggingapi.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+
+		return resourcegroupstaggingapi.New(sess), nil
+	}
+}
+
+// tagsRegion returns the region in which the tags of the resources of the owner are looked up. The Resource Groups
+// Tagging API is not available on the global session, the tags of global resources are looked up in the default region.
+func tagsRegion(owner string) string {
+	if owner == awsutil.GlobalRegionID {
+		return awsutil.DefaultRegionID
+	}
+
+	return owner
+}
+
+// enrichTags adds the tags found by the Resource Groups Tagging API to the resources that would be removed, and filters
+// them again. Only the resources that end up filtered keep the added tags, the other resources are restored as they
+// were scanned, so that waiting for their removal still compares them to freshly listed resources of the same type.
+// It returns an error when the tags of a region cannot be looked up.
+func enrichTags(n *libnuke.Nuke, q *queue.Queue, newSvc taggingSvcFunc, logger *logrus.Logger) error {
+	log := logger.WithField("component", "tag-enrichment")
+
+	items := map[string][]*queue.Item{}
+	for _, item := range q.Items {
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		region := tagsRegion(item.Owner)
+		items[region] = append(items[region], item)
+	}
+
+	enriched, filtered := 0, 0
+	for region, regionItems := range items {
+		// Note: the run is aborted when the tags cannot be looked up, otherwise the resources of the region would be
+		// removed without being protected by their tags
+		svc, err := newSvc(region)
+		if err != nil {
+			return fmt.Errorf("unable to look up tags in %s: %w", region, err)
+		}
+
+		tags, err := nuke.ListTags(svc)
+		if err != nil {
+			return fmt.Errorf("unable to look up tags in %s: %w", region, err)
+		}
+
+		for _, item := range regionItems {
+			resourceTags, ok := tags.Lookup(item.Resource)
+			if !ok {
+				continue
+			}
+
+			enriched++
+
+			original := item.Resource
+			item.Resource = &nuke.TaggedResource{Resource: original, Tags: resourceTags}
+
+			if err := n.Filter(item); err != nil {
+				return err
+			}
+
+			if item.GetState() != queue.ItemStateFiltered {
+				item.Resource = original
+				continue
+			}
+
+			filtered++
+
+			if !n.Parameters.Quiet {
+				item.Print()
+			}
+		}
+	}
+
+	log.WithField("_handler", "println").
+		Infof("Tag enrichment complete: %d resources enriched, %d additionally filtered.", enriched, filtered)
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package nuke
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gotidy/ptr"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/aws-nuke/v3/mocks/mock_resourcegroupstaggingapiiface"
+	"github.com/ekristen/aws-nuke/v3/pkg/awsutil"
+)
+
+type testTagsResource struct {
+	ARN string
+}
+
+func (r *testTagsResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testTagsResource) Properties() types.Properties {
+	return types.NewProperties().Set("ARN", r.ARN)
+}
+
+func TestTagsRegion(t *testing.T) {
+	assert.Equal(t, awsutil.DefaultRegionID, tagsRegion(awsutil.GlobalRegionID))
+	assert.Equal(t, "eu-west-1", tagsRegion("eu-west-1"))
+}
+
+func TestEnrichTags_Global(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	globalARN := "arn:aws:iam::123456789012:role/protected"
+	regionalARN := "arn:aws:sqs:us-east-1:123456789012:queue"
+
+	mockSvc := mock_resourcegroupstaggingapiiface.NewMockResourceGroupsTaggingAPIAPI(ctrl)
+	mockSvc.EXPECT().GetResourcesPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ *resourcegroupstaggingapi.GetResourcesInput,
+			fn func(*resourcegroupstaggingapi.GetResourcesOutput, bool) bool) error {
+			fn(&resourcegroupstaggingapi.GetResourcesOutput{
+				ResourceTagMappingList: []*resourcegroupstaggingapi.ResourceTagMapping{
+					{
+						ResourceARN: ptr.String(globalARN),
+						Tags: []*resourcegroupstaggingapi.Tag{
+							{Key: ptr.String("protected"), Value: ptr.String("true")},
+						},
+					},
+					{
+						ResourceARN: ptr.String(regionalARN),
+						Tags: []*resourcegroupstaggingapi.Tag{
+							{Key: ptr.String("owner"), Value: ptr.String("platform")},
+						},
+					},
+				},
+			}, true)
+			return nil
+		}).Times(1)
+
+	var regions []string
+	newSvc := func(region string) (resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI, error) {
+		regions = append(regions, region)
+		return mockSvc, nil
+	}
+
+	n := libnuke.New(&libnuke.Parameters{Quiet: true}, filter.Filters{
+		"TestRole": []filter.Filter{{Property: "tag:protected", Type: filter.Exact, Value: "true"}},
+	}, nil)
+
+	global := &queue.Item{
+		Resource: &testTagsResource{ARN: globalARN},
+		State:    queue.ItemStateNew,
+		Type:     "TestRole",
+		Owner:    awsutil.GlobalRegionID,
+	}
+	regional := &queue.Item{
+		Resource: &testTagsResource{ARN: regionalARN},
+		State:    queue.ItemStateNew,
+		Type:     "TestQueue",
+		Owner:    awsutil.DefaultRegionID,
+	}
+
+	err := enrichTags(n, &queue.Queue{Items: []*queue.Item{global, regional}}, newSvc, logger)
+	assert.NoError(t, err)
+
+	// Note: the global and the default region resources are looked up with a single regional session
+	assert.Equal(t, []string{awsutil.DefaultRegionID}, regions)
+
+	assert.Equal(t, queue.ItemStateFiltered, global.GetState())
+	assert.Equal(t, "true", global.Resource.(resource.PropertyGetter).Properties().Get("tag:protected"))
+
+	assert.Equal(t, queue.ItemStateNew, regional.GetState())
+	assert.Equal(t, &testTagsResource{ARN: regionalARN}, regional.Resource)
+}
+
+func TestEnrichTags_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	mockSvc := mock_resourcegroupstaggingapiiface.NewMockResourceGroupsTaggingAPIAPI(ctrl)
+	mockSvc.EXPECT().GetResourcesPages(gomock.Any(), gomock.Any()).
+		Return(errors.New("access denied")).Times(1)
+
+	newSvc := func(region string) (resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI, error) {
+		return mockSvc, nil
+	}
+
+	n := libnuke.New(&libnuke.Parameters{Quiet: true}, filter.Filters{}, nil)
+
+	item := &queue.Item{
+		Resource: &testTagsResource{ARN: "arn:aws:sqs:us-east-1:123456789012:queue"},
+		State:    queue.ItemStateNew,
+		Type:     "TestQueue",
+		Owner:    awsutil.DefaultRegionID,
+	}
+
+	// Note: the resources must not be removed without their tags being checked
+	err := enrichTags(n, &queue.Queue{Items: []*queue.Item{item}}, newSvc, logger)
+	assert.ErrorContains(t, err, "unable to look up tags in us-east-1: access denied")
+	assert.Equal(t, queue.ItemStateNew, item.GetState())
+}
@@ -0,0 +1,74 @@
+package nuke
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/awsutil"
+	"github.com/ekristen/aws-nuke/v3/pkg/common"
+	"github.com/ekristen/aws-nuke/v3/pkg/tracing"
+)
+
+// tracingExporter exports the spans of the run to the OTLP endpoint.
+type tracingExporter struct {
+	provider *tracing.Provider
+	endpoint string
+	logger   *logrus.Logger
+}
+
+// startTracing creates the tracer of the run when --tracing-endpoint is given and sets it on the credentials, so the
+// calls to the AWS APIs are traced. Without the flag nil is returned, which is safe to stop.
+func startTracing(c *cli.Context, creds *awsutil.Credentials, logger *logrus.Logger) (*tracingExporter, error) {
+	endpoint := c.String("tracing-endpoint")
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	provider, err := tracing.New(c.Context, endpoint, common.AppVersion.Summary)
+	if err != nil {
+		return nil, err
+	}
+
+	creds.Tracer = provider.Tracer()
+
+	logger.Infof("exporting traces to %s", endpoint)
+
+	return &tracingExporter{
+		provider: provider,
+		endpoint: endpoint,
+		logger:   logger,
+	}, nil
+}
+
+// Stop exports the spans that are not exported yet. A failed export is logged and does not fail the run.
+func (e *tracingExporter) Stop() {
+	if e == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := e.provider.Shutdown(ctx); err != nil {
+		e.logger.WithError(err).Errorf("unable to export traces to %s", e.endpoint)
+	}
+}
+
+// registerTracing traces the phases of the run of the account, when the run is traced.
+func registerTracing(r *runner, account *awsutil.Account) {
+	if account.Tracer == nil {
+		return
+	}
+
+	r.tracer = account.Tracer
+	r.traceParents = account.Credentials
+	r.traceAttributes = []attribute.KeyValue{
+		semconv.CloudAccountID(account.ID()),
+		attribute.String("aws_nuke.account_alias", account.Alias()),
+	}
+}
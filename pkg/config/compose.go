@@ -0,0 +1,318 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IncludeKey is the configuration key of the files and directories that are included in the configuration file. The
+// paths are relative to the file that includes them, a directory includes its YAML files in the order of their names.
+const IncludeKey = "include"
+
+// OverrideTag is the YAML tag of a value that replaces the value of the included files instead of being merged with it.
+const OverrideTag = "!override"
+
+// interpolation matches ${NAME} and ${NAME:-default}, and the escaped $${ that is replaced by a literal ${.
+var interpolation = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Compose reads the configuration file and returns it as YAML with the environment variables interpolated and the
+// included files merged into it. The included files are merged in their order and the file itself is merged last, so
+// that it overrides them, which makes an included file a base and the file an overlay of it:
+//
+//   - mappings, such as accounts, presets, filters and settings, are merged by key
+//   - sequences, such as the filters of a resource type, are appended, without the items that are already present
+//   - scalars and values of a different kind are replaced
+//   - values tagged with !override replace the value of the included files instead of being merged with it
+//
+// The environment variables are interpolated in the keys and values of every file before the files are merged, an
+// environment variable that is not set and has no default is an error.
+func Compose(path string) ([]byte, error) {
+	node, err := compose(path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if node == nil {
+		return []byte{}, nil
+	}
+
+	stripOverrideTags(node)
+
+	return yaml.Marshal(node)
+}
+
+// compose returns the root node of the configuration file with its includes merged, the includes are the files that
+// are being composed, to detect include cycles.
+func compose(path string, includes []string) (*yaml.Node, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, include := range includes {
+		if include == abs {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(includes, " -> "), abs)
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if err := interpolate(path, root); err != nil {
+		return nil, err
+	}
+
+	// Note: anything other than a mapping is left to the loading of the configuration to report
+	if root.Kind != yaml.MappingNode {
+		return root, nil
+	}
+
+	paths, err := includePaths(path, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var base *yaml.Node
+	for _, include := range paths {
+		included, err := compose(include, append(includes, abs))
+		if err != nil {
+			return nil, err
+		}
+
+		if included == nil {
+			continue
+		}
+
+		if included.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s: included configuration must be a mapping", include)
+		}
+
+		base = merge(base, included)
+	}
+
+	return merge(base, root), nil
+}
+
+// includePaths removes the include key from the root node of the file and returns the files that it includes.
+func includePaths(path string, root *yaml.Node) ([]string, error) {
+	var value *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == IncludeKey {
+			value = root.Content[i+1]
+			root.Content = append(root.Content[:i:i], root.Content[i+2:]...)
+			break
+		}
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	var entries []*yaml.Node
+	switch value.Kind {
+	case yaml.ScalarNode:
+		entries = []*yaml.Node{value}
+	case yaml.SequenceNode:
+		entries = value.Content
+	default:
+		return nil, fmt.Errorf("%s:%d:%d: %s must be a path or a list of paths", path, value.Line, value.Column,
+			IncludeKey)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.Kind != yaml.ScalarNode || entry.Value == "" {
+			return nil, fmt.Errorf("%s:%d:%d: %s must be a path or a list of paths", path, entry.Line, entry.Column,
+				IncludeKey)
+		}
+
+		include := entry.Value
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		info, err := os.Stat(include)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d:%d: %w", path, entry.Line, entry.Column, err)
+		}
+
+		if !info.IsDir() {
+			paths = append(paths, include)
+			continue
+		}
+
+		files, err := os.ReadDir(include)
+		if err != nil {
+			return nil, err
+		}
+
+		// Note: os.ReadDir returns the files sorted by name, they are sorted again to not depend on it
+		var names []string
+		for _, file := range files {
+			if ext := filepath.Ext(file.Name()); !file.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				names = append(names, file.Name())
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			paths = append(paths, filepath.Join(include, name))
+		}
+	}
+
+	return paths, nil
+}
+
+// interpolate replaces the environment variables in the keys and values of the node. The aliases are replaced by a
+// copy of their anchor, so that the files can be merged without their anchors.
+func interpolate(path string, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.AliasNode:
+		*node = *copyNode(node.Alias)
+		return nil
+	case yaml.ScalarNode:
+		var missing string
+		value := interpolation.ReplaceAllStringFunc(node.Value, func(match string) string {
+			if match == "$${" {
+				return "${"
+			}
+
+			groups := interpolation.FindStringSubmatch(match)
+			if value, ok := os.LookupEnv(groups[1]); ok && (value != "" || groups[2] == "") {
+				return value
+			}
+
+			if groups[2] == "" && missing == "" {
+				missing = groups[1]
+			}
+
+			return groups[3]
+		})
+
+		if missing != "" {
+			return fmt.Errorf("%s:%d:%d: environment variable '%s' is not set", path, node.Line, node.Column, missing)
+		}
+
+		// Note: a plain value is resolved again, so that an interpolated number or boolean is not loaded as a string
+		if value != node.Value {
+			node.Value = value
+			if node.Style == 0 && node.Tag != OverrideTag {
+				node.Tag = ""
+			}
+		}
+	}
+
+	node.Anchor = ""
+	for _, child := range node.Content {
+		if err := interpolate(path, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// merge returns the overlay merged over the base, see Compose for the rules.
+func merge(base, overlay *yaml.Node) *yaml.Node {
+	if base == nil || overlay.Tag == OverrideTag || base.Kind != overlay.Kind {
+		return overlay
+	}
+
+	switch overlay.Kind {
+	case yaml.MappingNode:
+		merged := copyNode(base)
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+
+			found := false
+			for j := 0; j+1 < len(merged.Content); j += 2 {
+				if merged.Content[j].Value == key.Value {
+					merged.Content[j+1] = merge(merged.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				merged.Content = append(merged.Content, key, value)
+			}
+		}
+
+		return merged
+	case yaml.SequenceNode:
+		merged := copyNode(base)
+		for _, item := range overlay.Content {
+			found := false
+			for _, existing := range merged.Content {
+				if equalNodes(existing, item) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				merged.Content = append(merged.Content, item)
+			}
+		}
+
+		return merged
+	default:
+		return overlay
+	}
+}
+
+// equalNodes returns true if the nodes have the same values, regardless of their style.
+func equalNodes(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+
+	for i := range a.Content {
+		if !equalNodes(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// copyNode returns a deep copy of the node without its anchor.
+func copyNode(node *yaml.Node) *yaml.Node {
+	c := *node
+	c.Anchor = ""
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c.Content[i] = copyNode(child)
+	}
+
+	return &c
+}
+
+// stripOverrideTags removes the override tags, which are only meaningful while the files are merged.
+func stripOverrideTags(node *yaml.Node) {
+	if node.Tag == OverrideTag {
+		node.Tag = ""
+	}
+
+	for _, child := range node.Content {
+		stripOverrideTags(child)
+	}
+}
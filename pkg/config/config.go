@@ -2,32 +2,44 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/sirupsen/logrus"
+	yamlv2 "gopkg.in/yaml.v2"
 	"gopkg.in/yaml.v3"
 
 	"github.com/ekristen/libnuke/pkg/config"
+	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/settings"
 )
 
 // New creates a new extended configuration from a file. This is necessary because we are extended the default
-// libnuke configuration to contain additional attributes that are specific to the AWS Nuke tool.
+// libnuke configuration to contain additional attributes that are specific to the AWS Nuke tool. The file is composed
+// with the files it includes and its environment variables are interpolated first, see Compose.
 func New(opts config.Options) (*Config, error) {
-	// Step 1 - Create the libnuke config
-	cfg, err := config.New(opts)
+	// Step 1 - Compose the config file with the files it includes
+	raw, err := Compose(opts.Path)
 	if err != nil {
 		return nil, err
 	}
 
-	// Step 2 - Instantiate the extended config
+	// Step 2 - Create the libnuke config from the composed config
+	cfg, err := newLibnukeConfig(opts, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3 - Instantiate the extended config and load the same composed config against it
 	c := &Config{
 		CustomEndpoints: make(CustomEndpoints, 0),
 	}
 
-	// Step 3 - Load the same config file against the extended config
-	// Intentionally ignored, this will never error because we already validated the file exists
-	_ = c.Load(opts.Path)
+	if err := c.load(raw); err != nil {
+		return nil, err
+	}
 
 	// Step 4 - Set the libnuke config on the extended config
 	c.Config = cfg
@@ -35,6 +47,36 @@ func New(opts config.Options) (*Config, error) {
 	// Step 5 - Resolve any deprecated feature flags
 	c.ResolveDeprecatedFeatureFlags()
 
+	// Step 6 - Validate the minimum age, so an invalid value is caught before anything is scanned
+	if _, err := c.MinAgeDuration(); err != nil {
+		return nil, err
+	}
+
+	// Step 7 - Validate the rate limits
+	if err := c.RateLimits.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Step 8 - Validate the notifications
+	if err := c.Notifications.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Step 9 - Validate the organization guard
+	if err := c.OrganizationGuard.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Step 10 - Validate the protect profiles
+	if err := c.ValidateProtectProfiles(); err != nil {
+		return nil, err
+	}
+
+	// Step 11 - Validate the settings of the resource types that validate them
+	if err := c.ValidateSettings(); err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
 
@@ -64,17 +106,85 @@ type Config struct {
 
 	// CustomEndpoints is a collection of custom endpoints that can be used to override the default AWS endpoints.
 	CustomEndpoints CustomEndpoints `yaml:"endpoints"`
+
+	// ProtectTags is a collection of tag keys and values that protect a resource from being removed. Any resource
+	// that exposes tags and has one of these tags is filtered, regardless of its resource type. An empty value
+	// protects any resource that has the tag with a non-empty value.
+	ProtectTags map[string]string `yaml:"protect-tags"`
+
+	// ProtectProfiles are the names of the built-in protect profiles, each protects the resources that a service, for
+	// example AWS Control Tower, creates in the accounts it manages.
+	ProtectProfiles []string `yaml:"protect-profiles"`
+
+	// MinAge is the minimum age of a resource before it is removed, as a duration, for example 24h. Resources that are
+	// younger, or whose creation time is not known, are filtered.
+	MinAge string `yaml:"min-age"`
+
+	// RateLimits is the number of requests per second that are sent to a service in a region. The rate is lowered when
+	// requests are throttled and raised back up while they succeed.
+	RateLimits RateLimits `yaml:"rate-limits"`
+
+	// Notifications configures the webhooks that the summary of a run is posted to once the run finishes or aborts.
+	Notifications Notifications `yaml:"notifications"`
+
+	// OrganizationGuard refuses to run against the management account, the delegated administrator accounts and the
+	// accounts in protected organizational units of the AWS Organization that the account belongs to.
+	OrganizationGuard OrganizationGuard `yaml:"organization-guard"`
 }
 
-// Load loads a configuration from a file and parses it into a Config struct.
-func (c *Config) Load(path string) error {
-	var err error
+// newLibnukeConfig creates the libnuke config from the composed config the same way that libnuke creates it from a
+// file, which it can only read itself, without the includes and the interpolation.
+func newLibnukeConfig(opts config.Options, raw []byte) (*config.Config, error) {
+	c := &config.Config{
+		Accounts:     make(map[string]*config.Account),
+		Presets:      make(map[string]config.Preset),
+		Deprecations: make(map[string]string),
+		Settings:     &settings.Settings{},
+	}
+
+	if opts.Log != nil {
+		c.Log = opts.Log
+	} else {
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+		c.Log = logger.WithField("component", "config")
+	}
 
-	raw, err := os.ReadFile(path)
+	if len(opts.Deprecations) > 0 {
+		c.Deprecations = opts.Deprecations
+	}
+
+	// Note: libnuke parses the config with yaml.v2, the filters only implement its unmarshaler
+	if err := yamlv2.Unmarshal(raw, c); err != nil {
+		return nil, err
+	}
+
+	if !opts.NoResolveBlacklist {
+		c.Blocklist = c.ResolveBlocklist()
+	}
+
+	if !opts.NoResolveDeprecations {
+		if err := c.ResolveDeprecations(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Load loads a configuration from a file and parses it into a Config struct. The file is composed with the files it
+// includes and its environment variables are interpolated first, see Compose.
+func (c *Config) Load(path string) error {
+	raw, err := Compose(path)
 	if err != nil {
 		return err
 	}
 
+	return c.load(raw)
+}
+
+// load parses the composed configuration into the Config struct.
+func (c *Config) load(raw []byte) error {
 	if err := yaml.Unmarshal(raw, c); err != nil {
 		return err
 	}
@@ -86,6 +196,61 @@ func (c *Config) Load(path string) error {
 	return nil
 }
 
+// SettingsValidator is implemented by the resources that validate their settings when the configuration is loaded, so
+// that an invalid setting is caught before anything is scanned instead of failing the removal of every resource.
+type SettingsValidator interface {
+	ValidateSettings(setting *settings.Setting) error
+}
+
+// ValidateSettings validates the settings of every registered resource type whose resource is a SettingsValidator.
+func (c *Config) ValidateSettings() error {
+	if c.Config == nil || c.Settings == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(*c.Settings))
+	for name := range *c.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		reg := registry.GetRegistration(name)
+		if reg == nil {
+			continue
+		}
+
+		validator, ok := reg.Resource.(SettingsValidator)
+		if !ok {
+			continue
+		}
+
+		if err := validator.ValidateSettings(c.Settings.Get(name)); err != nil {
+			return fmt.Errorf("invalid settings of %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// MinAgeDuration returns the minimum age of a resource before it is removed, or zero when no minimum age is set.
+func (c *Config) MinAgeDuration() (time.Duration, error) {
+	if c.MinAge == "" {
+		return 0, nil
+	}
+
+	minAge, err := time.ParseDuration(c.MinAge)
+	if err != nil {
+		return 0, fmt.Errorf("invalid min-age '%s': %w", c.MinAge, err)
+	}
+
+	if minAge < 0 {
+		return 0, fmt.Errorf("invalid min-age '%s': must not be negative", c.MinAge)
+	}
+
+	return minAge, nil
+}
+
 // InBypassAliasCheckAccounts returns true if the specified account ID is in the bypass alias check accounts list.
 func (c *Config) InBypassAliasCheckAccounts(accountID string) bool {
 	for _, id := range c.BypassAliasCheckAccounts {
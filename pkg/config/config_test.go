@@ -1,17 +1,22 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 
 	libconfig "github.com/ekristen/libnuke/pkg/config"
 	"github.com/ekristen/libnuke/pkg/filter"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
 	"github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 )
@@ -494,3 +499,549 @@ func TestConfig_ValidateAccount_Blocklist(t *testing.T) {
 		})
 	}
 }
+
+type protectTagsTestResource struct {
+	tags map[string]string
+}
+
+type protectTagsTestLister struct{}
+
+func (l *protectTagsTestLister) List(_ context.Context, _ interface{}) ([]resource.Resource, error) {
+	return nil, nil
+}
+
+func (r *protectTagsTestResource) GetProperty(key string) (string, error) {
+	return r.tags[strings.TrimPrefix(key, "tag:")], nil
+}
+
+func TestConfig_ProtectTags(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg, err := New(libconfig.Options{
+		Path: "testdata/protect-tags.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"owner": "platform", "protected": ""}, cfg.ProtectTags)
+
+	accountFilters, err := cfg.Filters("555133742")
+	assert.NoError(t, err)
+
+	registry.ClearRegistry()
+	registry.Register(&registry.Registration{
+		Name:     "ProtectTagsTagged",
+		Resource: &protectTagsTestResource{},
+		Lister:   &protectTagsTestLister{},
+	})
+	registry.Register(&registry.Registration{
+		Name:     "ProtectTagsUntagged",
+		Resource: &struct{ Name string }{},
+		Lister:   &protectTagsTestLister{},
+	})
+	defer registry.ClearRegistry()
+
+	resourceTypes := []string{"IAMRole", "ProtectTagsTagged", "ProtectTagsUntagged"}
+
+	filters := cfg.WithProtectTags(accountFilters, resourceTypes)
+	assert.Len(t, filters["IAMRole"], 1)
+	assert.Len(t, filters["ProtectTagsTagged"], 2)
+	assert.Len(t, filters["ProtectTagsUntagged"], 0)
+	assert.Len(t, accountFilters["ProtectTagsTagged"], 0)
+
+	assert.Equal(t, []string{"IAMRole", "ProtectTagsUntagged"}, cfg.ProtectTagsUnsupported(resourceTypes))
+	assert.Equal(t, []string{"IAMRole", "ProtectTagsUntagged"},
+		cfg.ProtectTagsUnsupportedWithEnrichment(resourceTypes))
+
+	registry.Register(&registry.Registration{
+		Name:     "ProtectTagsARN",
+		Resource: &struct{ ARN *string }{},
+		Lister:   &protectTagsTestLister{},
+	})
+
+	assert.Equal(t, []string{"ProtectTagsARN"}, cfg.ProtectTagsUnsupported([]string{"ProtectTagsARN"}))
+	assert.Empty(t, cfg.ProtectTagsUnsupportedWithEnrichment([]string{"ProtectTagsARN"}))
+
+	allFilters := cfg.WithProtectTagsForAll(accountFilters, resourceTypes)
+	assert.Len(t, allFilters["IAMRole"], 3)
+	assert.Len(t, allFilters["ProtectTagsTagged"], 2)
+	assert.Len(t, allFilters["ProtectTagsUntagged"], 2)
+
+	for _, useGroups := range []bool{false, true} {
+		cases := []struct {
+			tags     map[string]string
+			expected bool
+		}{
+			{tags: map[string]string{"owner": "platform"}, expected: true},
+			{tags: map[string]string{"owner": "someone"}, expected: false},
+			{tags: map[string]string{"protected": "true"}, expected: true},
+			{tags: map[string]string{"protected": ""}, expected: false},
+			{tags: map[string]string{}, expected: false},
+		}
+
+		for _, tc := range cases {
+			res := &protectTagsTestResource{tags: tc.tags}
+
+			var matched bool
+			if useGroups {
+				matched, err = filters.Match("ProtectTagsTagged", res)
+				assert.NoError(t, err)
+			} else {
+				for _, f := range filters.Get("ProtectTagsTagged") {
+					prop, _ := res.GetProperty(f.Property)
+					match, matchErr := f.Match(prop)
+					assert.NoError(t, matchErr)
+					matched = matched || match
+				}
+			}
+
+			assert.Equal(t, tc.expected, matched, fmt.Sprintf("groups=%t tags=%v", useGroups, tc.tags))
+		}
+	}
+}
+
+func TestConfig_MinAge(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg, err := New(libconfig.Options{
+		Path: "testdata/min-age.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+
+	minAge, err := cfg.MinAgeDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, minAge)
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/min-age-invalid.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.ErrorContains(t, err, "invalid min-age '2 days'")
+
+	cfg.MinAge = ""
+	minAge, err = cfg.MinAgeDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), minAge)
+}
+
+func TestConfig_RateLimits(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg, err := New(libconfig.Options{
+		Path: "testdata/rate-limits.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5.0, cfg.RateLimits.Get("iam"))
+	assert.Equal(t, 2.0, cfg.RateLimits.Get("route53"))
+	assert.Equal(t, 0.0, cfg.RateLimits.Get("ecs"))
+	assert.Equal(t, 20.0, cfg.RateLimits.Get("ec2"))
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/rate-limits-invalid.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.ErrorContains(t, err, "invalid rate-limit '-1' of service 'iam'")
+}
+
+type settingsTestResource struct{}
+
+func (r *settingsTestResource) ValidateSettings(setting *settings.Setting) error {
+	if window, ok := setting.Get("Window").(int); ok && window < 7 {
+		return fmt.Errorf("window must be at least 7")
+	}
+
+	return nil
+}
+
+func TestConfig_ValidateSettings(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	registry.ClearRegistry()
+	defer registry.ClearRegistry()
+
+	// Note: the settings of resource types that are not registered are not validated
+	_, err := New(libconfig.Options{
+		Path: "testdata/settings-invalid.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+
+	registry.Register(&registry.Registration{
+		Name:     "SettingsTestResource",
+		Resource: &settingsTestResource{},
+		Lister:   &protectTagsTestLister{},
+		Settings: []string{"Window"},
+	})
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/settings-invalid.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.EqualError(t, err, "invalid settings of SettingsTestResource: window must be at least 7")
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/example.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+}
+
+func TestConfig_Notifications(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg, err := New(libconfig.Options{
+		Path: "testdata/notifications.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Webhook{
+		{
+			URL:    "https://hooks.slack.com/services/T000/B000/XXXX",
+			Format: WebhookFormatSlack,
+		},
+		{
+			URL:     "https://example.com/aws-nuke",
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+		},
+	}, cfg.Notifications.Webhooks)
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/notifications-invalid.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.ErrorContains(t, err, "invalid webhook 0: unknown format 'discord'")
+
+	assert.ErrorContains(t, Notifications{Webhooks: []Webhook{{}}}.Validate(), "url is required")
+	assert.ErrorContains(t, Notifications{Webhooks: []Webhook{{URL: "hooks.slack.com"}}}.Validate(),
+		"url must be an http or https url")
+}
+
+func TestExposesTags(t *testing.T) {
+	type withTags struct {
+		Tags []string
+	}
+	type withTagList struct {
+		tagList map[string]string
+	}
+	type withoutTags struct {
+		Name string
+	}
+	type tag struct {
+		Key   *string
+		Value *string
+	}
+	type withTagElements struct {
+		resourceTags []*tag
+	}
+	type withTagMap struct {
+		Tags map[string]*string
+	}
+	type withPrefixedTags struct {
+		vpcTags []*tag
+	}
+	type withTagPrefix struct {
+		Labels []*tag `property:"tagPrefix=label"`
+	}
+	// Note: the fields of CloudFrontFunction, CloudFrontDistributionDeployment and
+	// ServiceCatalogTagOptionPortfolioAttachment, which contain "tag" in their name but do not hold tags
+	type functionStage string
+	type cloudFrontFunction struct {
+		name  *string
+		stage functionStage
+	}
+	type cloudFrontDistributionDeployment struct {
+		distributionID *string
+		eTag           *string
+	}
+	type serviceCatalogTagOptionPortfolioAttachment struct {
+		tagOptionID    *string
+		portfolioID    *string
+		tagOptionKey   *string
+		tagOptionValue *string
+		portfolioName  *string
+	}
+
+	assert.True(t, ExposesTags(&withTags{}))
+	assert.True(t, ExposesTags(withTagList{}))
+	assert.True(t, ExposesTags(&withTagElements{}))
+	assert.True(t, ExposesTags(&withTagMap{}))
+	assert.True(t, ExposesTags(&withPrefixedTags{}))
+	assert.True(t, ExposesTags(&withTagPrefix{}))
+	assert.False(t, ExposesTags(&withoutTags{}))
+	assert.False(t, ExposesTags(&cloudFrontFunction{}))
+	assert.False(t, ExposesTags(&cloudFrontDistributionDeployment{}))
+	assert.False(t, ExposesTags(&serviceCatalogTagOptionPortfolioAttachment{}))
+	assert.False(t, ExposesTags(nil))
+}
+
+func TestExposesARN(t *testing.T) {
+	type withARN struct {
+		ARN *string
+	}
+
+	type withArn struct {
+		Name string
+		Arn  *string
+	}
+
+	type withUnexportedARN struct {
+		arn string
+	}
+
+	type withoutARN struct {
+		RoleARN *string
+		Name    string
+	}
+
+	assert.True(t, ExposesARN(&withARN{}))
+	assert.True(t, ExposesARN(withArn{}))
+	assert.True(t, ExposesARN(&withUnexportedARN{arn: ""}))
+	assert.False(t, ExposesARN(&withoutARN{}))
+	assert.False(t, ExposesARN(nil))
+}
+
+func TestConfig_OrganizationGuard(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg, err := New(libconfig.Options{
+		Path: "testdata/organization-guard.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, cfg.OrganizationGuard.IsEnabled())
+	assert.False(t, cfg.OrganizationGuard.Enabled)
+	assert.Equal(t, []string{"ou-ab12-cd34ef56", "r-ab12"}, cfg.OrganizationGuard.ProtectedOrganizationalUnits)
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/organization-guard-invalid.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.ErrorContains(t, err, "invalid protected organizational unit 'Sandbox'")
+
+	cfg, err = New(libconfig.Options{
+		Path: "testdata/rate-limits.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+	assert.False(t, cfg.OrganizationGuard.IsEnabled())
+}
+
+type protectProfilesTestProperties map[string]string
+
+func (p protectProfilesTestProperties) GetProperty(key string) (string, error) {
+	return p[key], nil
+}
+
+func TestConfig_ProtectProfiles(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg, err := New(libconfig.Options{
+		Path: "testdata/protect-profiles.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"control-tower", "sso"}, cfg.ProtectProfiles)
+
+	accountFilters, err := cfg.Filters("555133742")
+	assert.NoError(t, err)
+
+	filters := cfg.WithProtectProfiles(accountFilters, []string{"IAMRole", "CloudTrailTrail", "S3Bucket"})
+	assert.Len(t, filters["IAMRole"], 5)
+	assert.Len(t, filters["CloudTrailTrail"], 1)
+	assert.Len(t, filters["SNSTopic"], 0)
+	assert.Len(t, filters["S3Bucket"], 0)
+	assert.Len(t, accountFilters["IAMRole"], 1)
+
+	cases := []struct {
+		resourceType string
+		properties   protectProfilesTestProperties
+		protected    bool
+	}{
+		{"IAMRole", protectProfilesTestProperties{"Name": "AWSControlTowerExecution"}, true},
+		{"IAMRole", protectProfilesTestProperties{"Name": "stacksets-exec-0123456789abcdef"}, true},
+		{"IAMRole", protectProfilesTestProperties{"Name": "AWSReservedSSO_AdministratorAccess_0123456789abcdef"}, true},
+		{"IAMRole", protectProfilesTestProperties{"Name": "application-role"}, false},
+		{"CloudTrailTrail", protectProfilesTestProperties{"Name": "aws-controltower-BaselineCloudTrail"}, true},
+		{"CloudTrailTrail", protectProfilesTestProperties{"Name": "application-trail"}, false},
+	}
+
+	for _, tc := range cases {
+		matched, err := filters.Match(tc.resourceType, tc.properties)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.protected, matched, "%s %v", tc.resourceType, tc.properties)
+	}
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/protect-profiles-invalid.yaml",
+		Log:  logger.WithField("test", true),
+	})
+	assert.ErrorContains(t, err, "invalid protect-profile 'landing-zone': must be one of control-tower, security-hub, sso")
+}
+
+type validateTestRole struct {
+	Name *string
+	Path *string
+	Tags map[string]string
+}
+
+func TestValidate(t *testing.T) {
+	registry.ClearRegistry()
+	registry.Register(&registry.Registration{
+		Name:              "ValidateRole",
+		Resource:          &validateTestRole{},
+		Lister:            &protectTagsTestLister{},
+		DeprecatedAliases: []string{"ValidateLegacyRole"},
+	})
+	registry.Register(&registry.Registration{
+		Name:     "ValidateBucket",
+		Resource: &struct{}{},
+		Lister:   &protectTagsTestLister{},
+		Settings: []string{"BypassGovernanceRetention"},
+	})
+	defer registry.ClearRegistry()
+
+	problems, err := Validate("testdata/validate.yaml")
+	assert.NoError(t, err)
+
+	var lines []string
+	for _, p := range problems {
+		lines = append(lines, p.String())
+	}
+
+	assert.Equal(t, []string{
+		"testdata/validate.yaml:8:1: error: unknown configuration key 'blocklist-term', did you mean 'blocklist-terms'?",
+		"testdata/validate.yaml:14:7: error: unknown resource type 'ValidateBuckett', did you mean 'ValidateBucket'?",
+		"testdata/validate.yaml:16:7: warning: 'Missing*' matches no resource type",
+		"testdata/validate.yaml:28:21: warning: property 'Nmae' is not documented for resource type 'ValidateRole', " +
+			"did you mean 'Name'?",
+		"testdata/validate.yaml:35:7: error: unknown resource type 'ValidateRoel', did you mean 'ValidateRole'?",
+		"testdata/validate.yaml:37:7: warning: resource type 'ValidateLegacyRole' is deprecated, use 'ValidateRole' instead",
+		"testdata/validate.yaml:49:5: error: unknown setting 'BypassGovernanceRetentoin' of resource type " +
+			"'ValidateBucket', did you mean 'BypassGovernanceRetention'?",
+		"testdata/validate.yaml:51:5: error: resource type 'ValidateRole' has no settings",
+	}, lines)
+
+	_, err = Validate("testdata/missing.yaml")
+	assert.Error(t, err)
+}
+
+func TestSchema(t *testing.T) {
+	registry.ClearRegistry()
+	registry.Register(&registry.Registration{
+		Name:              "ValidateRole",
+		Resource:          &validateTestRole{},
+		Lister:            &protectTagsTestLister{},
+		DeprecatedAliases: []string{"ValidateLegacyRole"},
+	})
+	registry.Register(&registry.Registration{
+		Name:     "ValidateBucket",
+		Resource: &struct{}{},
+		Lister:   &protectTagsTestLister{},
+		Settings: []string{"BypassGovernanceRetention"},
+	})
+	defer registry.ClearRegistry()
+
+	schema := Schema()
+	assert.Equal(t, SchemaVersion, schema.Schema)
+
+	// Note: every configuration key has to be part of the schema, otherwise editors report it as a mistake
+	var keys []string
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, append(yamlKeys(reflect.TypeOf(Config{})), IncludeKey), keys)
+
+	resourceType := schema.Definitions["resourceType"]
+	assert.Equal(t, []string{"ValidateBucket", "ValidateRole"}, resourceType.AnyOf[0].Enum)
+	assert.Equal(t, cloudControlPattern, resourceType.AnyOf[1].Pattern)
+	assert.True(t, resourceType.AnyOf[2].Deprecated)
+	assert.Equal(t, []string{"ValidateLegacyRole"}, resourceType.AnyOf[2].Enum)
+
+	settingsSchema := schema.Properties["settings"]
+	assert.Len(t, settingsSchema.Properties, 1)
+	assert.Contains(t, settingsSchema.Properties["ValidateBucket"].Properties, "BypassGovernanceRetention")
+	assert.Equal(t, false, settingsSchema.Properties["ValidateBucket"].AdditionalProperties)
+
+	filters := schema.Definitions["filters"]
+	assert.NotContains(t, filters.Properties, "ValidateBucket")
+	assert.Equal(t, []string{"CreatedAt", "Name", "Path"},
+		filters.Properties["ValidateRole"].Items.AllOf[1].AnyOf[1].Properties["property"].Examples)
+
+	assert.Equal(t, ProtectProfileNames(), schema.Properties["protect-profiles"].Items.Enum)
+
+	data, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"additionalProperties":false`)
+	assert.Contains(t, string(data), `"$ref":"#/definitions/filters"`)
+}
+
+func TestConfig_Compose(t *testing.T) {
+	t.Setenv("PROD_ACCOUNT", "000000000001")
+	t.Setenv("TEAM", "platform")
+	t.Setenv("GUARD_ENABLED", "true")
+
+	cfg, err := New(libconfig.Options{
+		Path: "testdata/compose/overlay.yaml",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"us-west-2"}, cfg.Regions)
+	assert.Equal(t, []string{"123456789012"}, cfg.Blocklist)
+	assert.Equal(t, []string{"live", "prod"}, cfg.BlocklistTerms)
+	assert.Equal(t, map[string]string{"team": "platform"}, cfg.ProtectTags)
+	assert.True(t, cfg.OrganizationGuard.Enabled)
+
+	assert.Len(t, cfg.Presets["common"].Filters["IAMRole"], 2)
+	assert.Equal(t, filter.Prefix, cfg.Presets["common"].Filters["IAMRole"][1].Type)
+
+	account := cfg.Accounts["000000000001"]
+	if assert.NotNil(t, account) {
+		assert.Equal(t, []string{"common"}, account.Presets)
+
+		var values []string
+		for _, f := range account.Filters["IAMRole"] {
+			values = append(values, f.Value)
+		}
+		assert.Equal(t, []string{"OrganizationAccountAccessRole", "deploy", "${NOT_INTERPOLATED}"}, values)
+	}
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/compose/cycle-a.yaml",
+	})
+	assert.ErrorContains(t, err, "include cycle")
+
+	_, err = New(libconfig.Options{
+		Path: "testdata/compose/missing-env.yaml",
+	})
+	assert.ErrorContains(t, err,
+		"testdata/compose/missing-env.yaml:2:5: environment variable 'AWS_NUKE_TEST_UNSET_ACCOUNT' is not set")
+
+	registry.ClearRegistry()
+	registry.Register(&registry.Registration{
+		Name:     "IAMRole",
+		Resource: &validateTestRole{},
+		Lister:   &protectTagsTestLister{},
+	})
+	defer registry.ClearRegistry()
+
+	problems, err := Validate("testdata/compose/overlay.yaml")
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+
+	problems, err = Validate("testdata/compose/cycle-a.yaml")
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+}
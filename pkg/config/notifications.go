@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	// WebhookFormatJSON posts the summary of the run as JSON, it is the default format.
+	WebhookFormatJSON = "json"
+
+	// WebhookFormatSlack posts the summary of the run as a Slack message.
+	WebhookFormatSlack = "slack"
+
+	// WebhookFormatTeams posts the summary of the run as a Microsoft Teams adaptive card.
+	WebhookFormatTeams = "teams"
+)
+
+// Notifications configures where the summary of a run is sent once the run finishes or aborts.
+type Notifications struct {
+	// Webhooks are the HTTP endpoints the summary is posted to.
+	Webhooks []Webhook `yaml:"webhooks"`
+}
+
+// Webhook is an HTTP endpoint that the summary of a run is posted to.
+type Webhook struct {
+	// URL is the URL the summary is posted to.
+	URL string `yaml:"url"`
+
+	// Format is the payload that is posted, either json, slack or teams. It defaults to json.
+	Format string `yaml:"format"`
+
+	// Template is a Go template of the payload that is posted, it overrides the format.
+	Template string `yaml:"template"`
+
+	// Headers are additional HTTP headers of the request, for example for authentication.
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Validate returns an error when one of the webhooks has no valid URL or an unknown format.
+func (n Notifications) Validate() error {
+	for i, webhook := range n.Webhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("invalid webhook %d: url is required", i)
+		}
+
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook %d: url must be an http or https url", i)
+		}
+
+		switch webhook.Format {
+		case "", WebhookFormatJSON, WebhookFormatSlack, WebhookFormatTeams:
+		default:
+			return fmt.Errorf("invalid webhook %d: unknown format '%s', must be one of: %s, %s, %s",
+				i, webhook.Format, WebhookFormatJSON, WebhookFormatSlack, WebhookFormatTeams)
+		}
+	}
+
+	return nil
+}
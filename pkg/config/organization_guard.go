@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrganizationGuard refuses to run against the accounts of an AWS Organization that must never be nuked. When it is
+// enabled, the management account and the delegated administrator accounts of the organization are refused, as well
+// as the accounts within one of the protected organizational units.
+type OrganizationGuard struct {
+	// Enabled enables the guard against the management account and the delegated administrator accounts.
+	Enabled bool `yaml:"enabled"`
+
+	// ProtectedOrganizationalUnits are the IDs of the organizational units whose accounts, including the accounts in
+	// nested organizational units, are refused. Setting them enables the guard.
+	ProtectedOrganizationalUnits []string `yaml:"protected-organizational-units"`
+}
+
+// IsEnabled returns true if the guard is enabled or organizational units are protected.
+func (g OrganizationGuard) IsEnabled() bool {
+	return g.Enabled || len(g.ProtectedOrganizationalUnits) > 0
+}
+
+// Validate returns an error when one of the protected organizational units is not the ID of an organizational unit
+// or of the root of an organization.
+func (g OrganizationGuard) Validate() error {
+	for _, ou := range g.ProtectedOrganizationalUnits {
+		if !strings.HasPrefix(ou, "ou-") && !strings.HasPrefix(ou, "r-") {
+			return fmt.Errorf("invalid protected organizational unit '%s': must be an ID like ou-ab12-cd34ef56", ou)
+		}
+	}
+
+	return nil
+}
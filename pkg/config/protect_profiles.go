@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+)
+
+// ProtectProfilesGroup is the prefix of the filter group of every protect profile filter. Every filter gets its own
+// group, so that when filter groups are used a single matching filter is enough to protect a resource.
+const ProtectProfilesGroup = "protect-profile"
+
+const (
+	// ProtectProfileControlTower protects the resources that AWS Control Tower deploys to the accounts it governs.
+	ProtectProfileControlTower = "control-tower"
+
+	// ProtectProfileSSO protects the roles and identity providers that IAM Identity Center creates in every account.
+	ProtectProfileSSO = "sso"
+
+	// ProtectProfileSecurityHub protects Security Hub and the Config rules of its security standards.
+	ProtectProfileSecurityHub = "security-hub"
+)
+
+// ProtectRule protects the resources of a resource type whose property matches the value with the filter type.
+type ProtectRule struct {
+	ResourceType string
+	Property     string
+	Type         filter.Type
+	Value        string
+}
+
+// protectProfiles are the rules of the built-in protect profiles by their name.
+var protectProfiles = map[string][]ProtectRule{
+	ProtectProfileControlTower: {
+		{"IAMRole", "Name", filter.Prefix, "AWSControlTower"},
+		{"IAMRole", "Name", filter.Prefix, "aws-controltower-"},
+		{"IAMRole", "Name", filter.Prefix, "stacksets-exec-"},
+		{"IAMRolePolicy", "role:RoleName", filter.Prefix, "AWSControlTower"},
+		{"IAMRolePolicy", "role:RoleName", filter.Prefix, "aws-controltower-"},
+		{"IAMRolePolicy", "role:RoleName", filter.Prefix, "stacksets-exec-"},
+		{"IAMRolePolicyAttachment", "RoleName", filter.Prefix, "AWSControlTower"},
+		{"IAMRolePolicyAttachment", "RoleName", filter.Prefix, "aws-controltower-"},
+		{"IAMRolePolicyAttachment", "RoleName", filter.Prefix, "stacksets-exec-"},
+		{"IAMPolicy", "Name", filter.Prefix, "AWSControlTower"},
+		{"CloudTrailTrail", "Name", filter.Prefix, "aws-controltower-"},
+		{"CloudWatchLogsLogGroup", "Name", filter.Prefix, "aws-controltower/"},
+		{"CloudWatchLogsLogGroup", "Name", filter.Prefix, "/aws/lambda/aws-controltower-"},
+		{"ConfigServiceConfigurationRecorder", "Name", filter.Prefix, "aws-controltower-"},
+		{"ConfigServiceDeliveryChannel", "Name", filter.Prefix, "aws-controltower-"},
+		{"ConfigServiceConfigRule", "Name", filter.Prefix, "AWSControlTower_"},
+		{"CloudFormationStack", "Name", filter.Prefix, "StackSet-AWSControlTower"},
+		{"CloudFormationStackSet", "Name", filter.Prefix, "AWSControlTower"},
+		{"SNSTopic", "TopicARN", filter.Contains, ":aws-controltower-"},
+		{"SNSSubscription", "TopicARN", filter.Contains, ":aws-controltower-"},
+		{"LambdaFunction", "Name", filter.Prefix, "aws-controltower-"},
+		{"CloudWatchEventsRule", "Name", filter.Prefix, "aws-controltower-"},
+		{"CloudWatchEventsTarget", "Name", filter.Prefix, "aws-controltower-"},
+	},
+	ProtectProfileSSO: {
+		{"IAMRole", "Name", filter.Prefix, "AWSReservedSSO_"},
+		{"IAMRolePolicy", "role:RoleName", filter.Prefix, "AWSReservedSSO_"},
+		{"IAMRolePolicyAttachment", "RoleName", filter.Prefix, "AWSReservedSSO_"},
+		{"IAMSAMLProvider", "ARN", filter.Contains, ":saml-provider/AWSSSO_"},
+	},
+	ProtectProfileSecurityHub: {
+		{"SecurityHub", "Arn", filter.Regex, ".+"},
+		{"ConfigServiceConfigRule", "Name", filter.Prefix, "securityhub-"},
+		{"ConfigServiceConfigRule", "CreatedBy", filter.Exact, "securityhub.amazonaws.com"},
+	},
+}
+
+// ProtectProfileNames returns the sorted names of the built-in protect profiles.
+func ProtectProfileNames() []string {
+	names := make([]string, 0, len(protectProfiles))
+	for name := range protectProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ProtectProfileRules returns the rules of the protect profile with the name, or nothing if there is no such profile.
+func ProtectProfileRules(name string) []ProtectRule {
+	return protectProfiles[name]
+}
+
+// ValidateProtectProfiles returns an error when one of the protect profiles is not a built-in protect profile.
+func (c *Config) ValidateProtectProfiles() error {
+	for _, name := range c.ProtectProfiles {
+		if _, ok := protectProfiles[name]; !ok {
+			return fmt.Errorf("invalid protect-profile '%s': must be one of %s",
+				name, strings.Join(ProtectProfileNames(), ", "))
+		}
+	}
+
+	return nil
+}
+
+// WithProtectProfiles returns a copy of the filters with the filters of the protect profiles added to the resource
+// types, out of the given resource types, that the profiles have rules for.
+func (c *Config) WithProtectProfiles(filters filter.Filters, resourceTypes []string) filter.Filters {
+	if len(c.ProtectProfiles) == 0 {
+		return filters
+	}
+
+	// Note: the filters belong to the account configuration, copy them to avoid modifying the configuration
+	withProtectProfiles := filter.Filters{}
+	withProtectProfiles.Append(filters)
+
+	for _, name := range c.ProtectProfiles {
+		for i, rule := range protectProfiles[name] {
+			if !slices.Contains(resourceTypes, rule.ResourceType) {
+				continue
+			}
+
+			withProtectProfiles[rule.ResourceType] = append(withProtectProfiles[rule.ResourceType], filter.Filter{
+				Group:    fmt.Sprintf("%s:%s:%d", ProtectProfilesGroup, name, i),
+				Property: rule.Property,
+				Type:     rule.Type,
+				Value:    rule.Value,
+			})
+		}
+	}
+
+	return withProtectProfiles
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	"github.com/ekristen/libnuke/pkg/registry"
+)
+
+// ProtectTagsGroup is the prefix of the filter group of every protect tag filter. Every tag gets its own group, so
+// that when filter groups are used a single matching tag is enough to protect a resource.
+const ProtectTagsGroup = "protect-tags"
+
+// WithProtectTags returns a copy of the filters with a filter for every protect tag added to every resource type, out
+// of the given resource types, that exposes tags. The filters are added per resource type instead of as global
+// filters, since global filters are not applied when filter groups are used.
+func (c *Config) WithProtectTags(filters filter.Filters, resourceTypes []string) filter.Filters {
+	return c.withProtectTags(filters, resourceTypes, true)
+}
+
+// WithProtectTagsForAll returns a copy of the filters with a filter for every protect tag added to every one of the
+// given resource types, including the ones that do not expose tags themselves. It is used when the tags of every
+// resource are looked up with the Resource Groups Tagging API.
+func (c *Config) WithProtectTagsForAll(filters filter.Filters, resourceTypes []string) filter.Filters {
+	return c.withProtectTags(filters, resourceTypes, false)
+}
+
+func (c *Config) withProtectTags(filters filter.Filters, resourceTypes []string, onlyTagged bool) filter.Filters {
+	protectFilters := c.ProtectTagsFilters()
+	if len(protectFilters) == 0 {
+		return filters
+	}
+
+	// Note: the filters belong to the account configuration, copy them to avoid modifying the configuration
+	withProtectTags := filter.Filters{}
+	withProtectTags.Append(filters)
+
+	for _, resourceType := range resourceTypes {
+		if onlyTagged {
+			reg := registry.GetRegistration(resourceType)
+			if reg == nil || !ExposesTags(reg.Resource) {
+				continue
+			}
+		}
+
+		withProtectTags[resourceType] = append(withProtectTags[resourceType], protectFilters...)
+	}
+
+	return withProtectTags
+}
+
+// ProtectTagsFilters returns a filter for every protect tag.
+func (c *Config) ProtectTagsFilters() []filter.Filter {
+	keys := make([]string, 0, len(c.ProtectTags))
+	for key := range c.ProtectTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := make([]filter.Filter, 0, len(keys))
+	for _, key := range keys {
+		f := filter.Filter{
+			Group:    fmt.Sprintf("%s:%s", ProtectTagsGroup, key),
+			Property: fmt.Sprintf("tag:%s", key),
+			Type:     filter.Exact,
+			Value:    c.ProtectTags[key],
+		}
+
+		if f.Value == "" {
+			f.Type = filter.Regex
+			f.Value = ".+"
+		}
+
+		filters = append(filters, f)
+	}
+
+	return filters
+}
+
+// ProtectTagsUnsupported returns the resource types, out of the given resource types, that do not expose any tags and
+// therefore cannot be protected by the protect tags. It returns nothing when no protect tags are configured.
+func (c *Config) ProtectTagsUnsupported(resourceTypes []string) []string {
+	if len(c.ProtectTags) == 0 {
+		return nil
+	}
+
+	var unsupported []string
+	for _, resourceType := range resourceTypes {
+		reg := registry.GetRegistration(resourceType)
+		if reg == nil || !ExposesTags(reg.Resource) {
+			unsupported = append(unsupported, resourceType)
+		}
+	}
+
+	return unsupported
+}
+
+// ProtectTagsUnsupportedWithEnrichment returns the resource types, out of the given resource types, that can neither
+// be protected by the tags they expose nor by the tags that tag enrichment looks up for them, because they expose
+// neither tags nor an ARN. It returns nothing when no protect tags are configured.
+func (c *Config) ProtectTagsUnsupportedWithEnrichment(resourceTypes []string) []string {
+	if len(c.ProtectTags) == 0 {
+		return nil
+	}
+
+	var unsupported []string
+	for _, resourceType := range resourceTypes {
+		reg := registry.GetRegistration(resourceType)
+		if reg == nil || (!ExposesTags(reg.Resource) && !ExposesARN(reg.Resource)) {
+			unsupported = append(unsupported, resourceType)
+		}
+	}
+
+	return unsupported
+}
+
+// ExposesTags returns true if the resource struct has a field holding tags. Resources expose their tags as properties
+// from such a field, either through the struct tags or in their own Properties method.
+func ExposesTags(resource interface{}) bool {
+	if resource == nil {
+		return false
+	}
+
+	t := reflect.TypeOf(resource)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if isTagField(t.Field(i)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTagField returns true if the field holds tags, which is a slice or map that is either named after tags, such as
+// Tags, tagList or vpcTags, or whose elements are tags, or a field exposed as properties with a tag prefix. Fields that
+// only contain "tag" in their name, such as stage, eTag or tagOptionID, are not tags.
+func isTagField(field reflect.StructField) bool {
+	if strings.Contains(field.Tag.Get("property"), "tagPrefix") {
+		return true
+	}
+
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+		return false
+	}
+
+	name := strings.ToLower(field.Name)
+	if name == "tag" || name == "taglist" || strings.HasSuffix(name, "tags") {
+		return true
+	}
+
+	elem := t.Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	return elem.Kind() == reflect.Struct && strings.Contains(elem.Name(), "Tag")
+}
+
+// ExposesARN returns true if the resource struct has a field holding its ARN, which is exposed as the ARN property that
+// tag enrichment matches the resource by. Resources that only have an ARN as their legacy string are not detected.
+func ExposesARN(resource interface{}) bool {
+	if resource == nil {
+		return false
+	}
+
+	t := reflect.TypeOf(resource)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if strings.EqualFold(t.Field(i).Name, "arn") {
+			return true
+		}
+	}
+
+	return false
+}
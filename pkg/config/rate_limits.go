@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+)
+
+// RateLimits is the maximum number of requests per second that are sent to a service in a region, per account. The
+// services are identified by their endpoints ID, for example iam, route53 or ecs. A rate of zero disables the rate
+// limiting.
+type RateLimits struct {
+	// Default is the rate of every service that is not listed in Services.
+	Default float64 `yaml:"default"`
+
+	// Services is the rate of individual services, it overrides the default rate.
+	Services map[string]float64 `yaml:"services"`
+}
+
+// Get returns the rate of the service, or zero when the requests to the service are not rate limited.
+func (r RateLimits) Get(service string) float64 {
+	if rate, ok := r.Services[service]; ok {
+		return rate
+	}
+
+	return r.Default
+}
+
+// Validate returns an error when one of the rates is negative.
+func (r RateLimits) Validate() error {
+	if r.Default < 0 {
+		return fmt.Errorf("invalid default rate-limit '%g': must not be negative", r.Default)
+	}
+
+	for service, rate := range r.Services {
+		if rate < 0 {
+			return fmt.Errorf("invalid rate-limit '%g' of service '%s': must not be negative", rate, service)
+		}
+	}
+
+	return nil
+}
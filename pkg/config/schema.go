@@ -0,0 +1,430 @@
+package config
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	"github.com/ekristen/libnuke/pkg/registry"
+)
+
+// SchemaVersion is the JSON Schema draft of the schema of the configuration file, it is the draft that the YAML
+// language servers of the common editors support best.
+const SchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// cloudControlPattern matches the names of the Cloud Control resource types, which are only registered once they are
+// used, so they cannot be part of the enum of the resource types.
+const cloudControlPattern = "^AWS::"
+
+// wildcardPattern matches the names of resource types with a wildcard, which are expanded to the resource types that
+// they match.
+const wildcardPattern = "[*?\\[]"
+
+// JSONSchema is a JSON Schema, it only has the keywords that the schema of the configuration file uses. The additional
+// properties are either a schema or false, which forbids the keys that are not properties.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Deprecated           bool                   `json:"deprecated,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Examples             []string               `json:"examples,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	PropertyNames        *JSONSchema            `json:"propertyNames,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	Definitions          map[string]*JSONSchema `json:"definitions,omitempty"`
+}
+
+// Schema returns the JSON Schema of the configuration file. The resource types, their settings and the properties that
+// can be filtered on are taken from the registry, so the resources have to be registered.
+func Schema() *JSONSchema {
+	names := registry.GetNames()
+	sort.Strings(names)
+
+	return &JSONSchema{
+		Schema:      SchemaVersion,
+		Title:       "aws-nuke configuration",
+		Description: "The configuration file of aws-nuke, see https://ekristen.github.io/aws-nuke/config/",
+		Type:        "object",
+		Properties: map[string]*JSONSchema{
+			IncludeKey: {
+				Description: "The files and directories that are merged into the configuration, which overrides them.",
+				AnyOf: []*JSONSchema{
+					{Type: "string"},
+					{Type: "array", Items: &JSONSchema{Type: "string"}},
+				},
+			},
+			"regions": {
+				Description: "The regions to nuke, global is the region of the resources that are not regional.",
+				Type:        "array",
+				Items:       &JSONSchema{Type: "string", Examples: []string{"global", "us-east-1"}},
+			},
+			"blocklist": {
+				Description: "The accounts that are never nuked.",
+				Type:        "array",
+				Items:       accountIDSchema(),
+			},
+			"account-blocklist": {
+				Description: "Deprecated: use blocklist instead.",
+				Deprecated:  true,
+				Type:        "array",
+				Items:       accountIDSchema(),
+			},
+			"account-blacklist": {
+				Description: "Deprecated: use blocklist instead.",
+				Deprecated:  true,
+				Type:        "array",
+				Items:       accountIDSchema(),
+			},
+			"blocklist-terms": {
+				Description: "The terms that are not allowed in the alias of an account that is nuked.",
+				Type:        "array",
+				Items:       &JSONSchema{Type: "string"},
+			},
+			"no-blocklist-terms-default": {
+				Description: "Do not add the default terms to the blocklist terms.",
+				Type:        "boolean",
+			},
+			"bypass-alias-check-accounts": {
+				Description: "The accounts that are nuked without an alias when run with --no-alias-check.",
+				Type:        "array",
+				Items:       accountIDSchema(),
+			},
+			"accounts": {
+				Description: "The accounts that can be nuked by their ID.",
+				Type:        "object",
+				AdditionalProperties: &JSONSchema{
+					Type: "object",
+					Properties: map[string]*JSONSchema{
+						"filters":        {Ref: "#/definitions/filters"},
+						"resource-types": {Ref: "#/definitions/resourceTypes"},
+						"presets": {
+							Description: "The presets whose filters are added to the filters of the account.",
+							Type:        "array",
+							Items:       &JSONSchema{Type: "string"},
+						},
+					},
+					AdditionalProperties: false,
+				},
+			},
+			"presets": {
+				Description: "The named collections of filters that accounts can use.",
+				Type:        "object",
+				AdditionalProperties: &JSONSchema{
+					Type: "object",
+					Properties: map[string]*JSONSchema{
+						"filters": {Ref: "#/definitions/filters"},
+					},
+					AdditionalProperties: false,
+				},
+			},
+			"resource-types": {Ref: "#/definitions/resourceTypes"},
+			"settings":       settingsSchema(names),
+			"feature-flags": {
+				Description: "Deprecated: use settings instead.",
+				Deprecated:  true,
+				Type:        "object",
+				Properties: map[string]*JSONSchema{
+					"disable-deletion-protection": {
+						Type: "object",
+						Properties: map[string]*JSONSchema{
+							"RDSInstance":         {Type: "boolean"},
+							"EC2Instance":         {Type: "boolean"},
+							"CloudformationStack": {Type: "boolean"},
+							"ELBv2":               {Type: "boolean"},
+							"QLDBLedger":          {Type: "boolean"},
+						},
+						AdditionalProperties: false,
+					},
+					"disable-ec2-instance-stop-protection": {Type: "boolean"},
+					"force-delete-lightsail-addons":        {Type: "boolean"},
+				},
+				AdditionalProperties: false,
+			},
+			"endpoints": {
+				Description: "The custom endpoints of the services by region.",
+				Type:        "array",
+				Items: &JSONSchema{
+					Type: "object",
+					Properties: map[string]*JSONSchema{
+						"region": {Type: "string"},
+						"services": {
+							Type: "array",
+							Items: &JSONSchema{
+								Type: "object",
+								Properties: map[string]*JSONSchema{
+									"service":                  {Type: "string"},
+									"url":                      {Type: "string"},
+									"tls_insecure_skip_verify": {Type: "boolean"},
+								},
+								AdditionalProperties: false,
+							},
+						},
+						"tls_insecure_skip_verify": {Type: "boolean"},
+					},
+					AdditionalProperties: false,
+				},
+			},
+			"protect-tags": {
+				Description:          "The tags that protect any resource, an empty value protects any value of the tag.",
+				Type:                 "object",
+				AdditionalProperties: &JSONSchema{AnyOf: []*JSONSchema{{Type: "string"}, {Type: "null"}}},
+			},
+			"protect-profiles": {
+				Description: "The built-in protect profiles that protect the resources of a service.",
+				Type:        "array",
+				Items:       &JSONSchema{Type: "string", Enum: ProtectProfileNames()},
+			},
+			"min-age": {
+				Description: "The minimum age of a resource before it is removed, as a duration.",
+				Type:        "string",
+				Examples:    []string{"24h", "168h"},
+			},
+			"rate-limits": {
+				Description: "The requests per second that are sent to a service in a region.",
+				Type:        "object",
+				Properties: map[string]*JSONSchema{
+					"default": {Type: "number", Minimum: zero()},
+					"services": {
+						Type:                 "object",
+						AdditionalProperties: &JSONSchema{Type: "number", Minimum: zero()},
+					},
+				},
+				AdditionalProperties: false,
+			},
+			"notifications": {
+				Description: "The webhooks that the summary of a run is posted to.",
+				Type:        "object",
+				Properties: map[string]*JSONSchema{
+					"webhooks": {
+						Type: "array",
+						Items: &JSONSchema{
+							Type: "object",
+							Properties: map[string]*JSONSchema{
+								"url": {Type: "string", Pattern: "^https?://"},
+								"format": {
+									Type: "string",
+									Enum: []string{WebhookFormatJSON, WebhookFormatSlack, WebhookFormatTeams},
+								},
+								"template": {Type: "string"},
+								"headers": {
+									Type:                 "object",
+									AdditionalProperties: &JSONSchema{Type: "string"},
+								},
+							},
+							AdditionalProperties: false,
+						},
+					},
+				},
+				AdditionalProperties: false,
+			},
+			"organization-guard": {
+				Description: "Refuse to nuke the management, delegated administrator and protected accounts.",
+				Type:        "object",
+				Properties: map[string]*JSONSchema{
+					"enabled": {Type: "boolean"},
+					"protected-organizational-units": {
+						Type:  "array",
+						Items: &JSONSchema{Type: "string", Pattern: "^(ou|r)-"},
+					},
+				},
+				AdditionalProperties: false,
+			},
+		},
+		AdditionalProperties: false,
+		Definitions: map[string]*JSONSchema{
+			"resourceType":  resourceTypeSchema(names),
+			"resourceTypes": resourceTypesSchema(),
+			"filter":        filterSchema(),
+			"filters":       filtersSchema(names),
+		},
+	}
+}
+
+// accountIDSchema returns the schema of an AWS account ID, which is often written as a number in YAML.
+func accountIDSchema() *JSONSchema {
+	return &JSONSchema{AnyOf: []*JSONSchema{{Type: "string"}, {Type: "integer"}}}
+}
+
+// resourceTypeSchema returns the schema of the name of a resource type, which is a registered resource type, one of
+// their deprecated aliases or a Cloud Control resource type.
+func resourceTypeSchema(names []string) *JSONSchema {
+	var aliases []string
+	for alias := range registry.GetDeprecatedResourceTypeMapping() {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	schema := &JSONSchema{
+		AnyOf: []*JSONSchema{
+			{Type: "string", Enum: names},
+			{Type: "string", Pattern: cloudControlPattern},
+		},
+	}
+
+	if len(aliases) > 0 {
+		schema.AnyOf = append(schema.AnyOf, &JSONSchema{
+			Description: "Deprecated: use the resource type that replaces the alias instead.",
+			Deprecated:  true,
+			Type:        "string",
+			Enum:        aliases,
+		})
+	}
+
+	return schema
+}
+
+// resourceTypesSchema returns the schema of the resource types that are included or excluded, which are expanded
+// when they have a wildcard.
+func resourceTypesSchema() *JSONSchema {
+	list := func(description string, deprecated bool) *JSONSchema {
+		return &JSONSchema{
+			Description: description,
+			Deprecated:  deprecated,
+			Type:        "array",
+			Items: &JSONSchema{
+				AnyOf: []*JSONSchema{
+					{Ref: "#/definitions/resourceType"},
+					{Type: "string", Pattern: wildcardPattern},
+				},
+			},
+		}
+	}
+
+	return &JSONSchema{
+		Description: "The resource types that are nuked.",
+		Type:        "object",
+		Properties: map[string]*JSONSchema{
+			"includes":      list("Only nuke these resource types.", false),
+			"excludes":      list("Never nuke these resource types.", false),
+			"alternatives":  list("The Cloud Control resource types that replace the resource types.", false),
+			"targets":       list("Deprecated: use includes instead.", true),
+			"cloud-control": list("Deprecated: use alternatives instead.", true),
+		},
+		AdditionalProperties: false,
+	}
+}
+
+// filterSchema returns the schema of a filter, which is either the value that the name of the resource has to equal,
+// or an object.
+func filterSchema() *JSONSchema {
+	return &JSONSchema{
+		AnyOf: []*JSONSchema{
+			{Type: "string"},
+			{
+				Type: "object",
+				Properties: map[string]*JSONSchema{
+					"property": {Type: "string"},
+					"type": {
+						Type: "string",
+						Enum: []string{
+							string(filter.Exact), string(filter.Glob), string(filter.Regex), string(filter.Contains),
+							string(filter.Prefix), string(filter.Suffix), string(filter.DateOlderThan),
+							string(filter.DateOlderThanNow), string(filter.In), string(filter.NotIn),
+						},
+					},
+					"value":  {Type: "string"},
+					"values": {Type: "array", Items: &JSONSchema{Type: "string"}},
+					"invert": {AnyOf: []*JSONSchema{{Type: "boolean"}, {Type: "string", Enum: []string{"true", "false"}}}},
+					"group":  {Type: "string"},
+				},
+				AdditionalProperties: false,
+			},
+		},
+	}
+}
+
+// filtersSchema returns the schema of the filters by resource type, the filters of a registered resource type suggest
+// the properties that the resource type documents.
+func filtersSchema(names []string) *JSONSchema {
+	schema := &JSONSchema{
+		Description: "The filters of the resources that are never nuked, by resource type.",
+		Type:        "object",
+		Properties:  map[string]*JSONSchema{},
+		PropertyNames: &JSONSchema{
+			AnyOf: []*JSONSchema{{Ref: "#/definitions/resourceType"}, {Enum: []string{filter.Global}}},
+		},
+		AdditionalProperties: &JSONSchema{
+			Type:  "array",
+			Items: &JSONSchema{Ref: "#/definitions/filter"},
+		},
+	}
+
+	for _, name := range names {
+		properties := documentedProperties(registry.GetRegistration(name).Resource)
+		if len(properties) == 0 {
+			continue
+		}
+
+		if !slices.Contains(properties, createdAtProperty) {
+			properties = append(properties, createdAtProperty)
+			sort.Strings(properties)
+		}
+
+		schema.Properties[name] = &JSONSchema{
+			Type: "array",
+			Items: &JSONSchema{
+				AllOf: []*JSONSchema{
+					{Ref: "#/definitions/filter"},
+					{
+						AnyOf: []*JSONSchema{
+							{Type: "string"},
+							{
+								Type: "object",
+								Properties: map[string]*JSONSchema{
+									"property": {Type: "string", Examples: properties},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return schema
+}
+
+// settingsSchema returns the schema of the settings by resource type, only the resource types with settings can be
+// configured and only with their own settings.
+func settingsSchema(names []string) *JSONSchema {
+	schema := &JSONSchema{
+		Description:          "The settings of the resource types.",
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{},
+		AdditionalProperties: false,
+	}
+
+	for _, name := range names {
+		reg := registry.GetRegistration(name)
+		if len(reg.Settings) == 0 {
+			continue
+		}
+
+		settings := &JSONSchema{
+			Type:                 "object",
+			Properties:           map[string]*JSONSchema{},
+			AdditionalProperties: false,
+		}
+
+		for _, setting := range reg.Settings {
+			settings.Properties[setting] = &JSONSchema{}
+		}
+
+		schema.Properties[name] = settings
+	}
+
+	return schema
+}
+
+// zero returns a pointer to zero, the minimum of the numbers that cannot be negative.
+func zero() *float64 {
+	return new(float64)
+}
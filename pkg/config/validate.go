@@ -0,0 +1,363 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ekristen/libnuke/pkg/docs"
+	"github.com/ekristen/libnuke/pkg/filter"
+	"github.com/ekristen/libnuke/pkg/registry"
+)
+
+// Severity is the severity of a problem of a configuration file.
+type Severity string
+
+const (
+	// SeverityError is the severity of the problems that are certainly mistakes.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is the severity of the problems that are likely mistakes, but can be intended.
+	SeverityWarning Severity = "warning"
+)
+
+// createdAtProperty is the normalized creation time property that is added to every resource with a creation time.
+const createdAtProperty = "CreatedAt"
+
+// Problem is a mistake at a position of a configuration file.
+type Problem struct {
+	Path     string
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+// String returns the problem with its position, in the format that editors and CI systems understand.
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", p.Path, p.Line, p.Column, p.Severity, p.Message)
+}
+
+// Validate checks the configuration file for mistakes that loading it does not catch, since they are valid YAML:
+// unknown configuration keys, filters and settings of unknown resource types, settings that the resource type does
+// not support, and filters on properties that the resource type does not document. The files that the file includes
+// are checked after it, the problems of every file are sorted by their position in the file. Only the resource types
+// of the registry are known, so the resources have to be registered.
+func Validate(path string) ([]Problem, error) {
+	v := &validator{
+		names:        registry.GetNames(),
+		deprecations: registry.GetDeprecatedResourceTypeMapping(),
+		validated:    map[string]bool{},
+	}
+
+	if err := v.validateFile(path); err != nil {
+		return nil, err
+	}
+
+	return v.problems, nil
+}
+
+type validator struct {
+	path         string
+	names        []string
+	deprecations map[string]string
+	validated    map[string]bool
+	problems     []Problem
+}
+
+// validateFile checks the file and the files that it includes, every file is only checked once.
+func (v *validator) validateFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if v.validated[abs] {
+		return nil
+	}
+	v.validated[abs] = true
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+
+	v.path = path
+	start := len(v.problems)
+
+	v.validateRoot(doc.Content[0])
+
+	problems := v.problems[start:]
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+
+	includes, err := includePaths(path, doc.Content[0])
+	if err != nil {
+		return err
+	}
+
+	for _, include := range includes {
+		if err := v.validateFile(include); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) report(node *yaml.Node, severity Severity, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{
+		Path:     v.path,
+		Line:     node.Line,
+		Column:   node.Column,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateRoot(node *yaml.Node) {
+	known := yamlKeys(reflect.TypeOf(Config{}))
+
+	forEachPair(node, func(key, value *yaml.Node) {
+		switch key.Value {
+		case "accounts":
+			forEachPair(value, func(_, account *yaml.Node) {
+				forEachPair(account, func(key, value *yaml.Node) {
+					switch key.Value {
+					case "filters":
+						v.validateFilters(value)
+					case "resource-types":
+						v.validateResourceTypes(value)
+					}
+				})
+			})
+		case "presets":
+			forEachPair(value, func(_, preset *yaml.Node) {
+				forEachPair(preset, func(key, value *yaml.Node) {
+					if key.Value == "filters" {
+						v.validateFilters(value)
+					}
+				})
+			})
+		case "resource-types":
+			v.validateResourceTypes(value)
+		case "settings":
+			v.validateSettings(value)
+		case IncludeKey:
+		default:
+			if !slices.Contains(known, key.Value) {
+				v.report(key, SeverityError, "unknown configuration key '%s'%s", key.Value, suggest(key.Value, known))
+			}
+		}
+	})
+}
+
+// validateResourceType reports an unknown or deprecated resource type and returns the registration of the resource
+// type, or nothing when it is not known or is a Cloud Control resource type, whose properties are not known.
+func (v *validator) validateResourceType(node *yaml.Node) *registry.Registration {
+	name := node.Value
+
+	if replacement, ok := v.deprecations[name]; ok {
+		v.report(node, SeverityWarning, "resource type '%s' is deprecated, use '%s' instead", name, replacement)
+		name = replacement
+	}
+
+	if reg := registry.GetRegistration(name); reg != nil {
+		if strings.HasPrefix(name, "AWS::") {
+			return nil
+		}
+		return reg
+	}
+
+	// Note: Cloud Control resource types are only registered once they are used
+	if !strings.HasPrefix(name, "AWS::") {
+		v.report(node, SeverityError, "unknown resource type '%s'%s", name, suggest(name, v.names))
+	}
+
+	return nil
+}
+
+func (v *validator) validateResourceTypes(node *yaml.Node) {
+	forEachPair(node, func(_, value *yaml.Node) {
+		for _, resourceType := range value.Content {
+			if resourceType.Kind != yaml.ScalarNode {
+				continue
+			}
+
+			// Note: the resource types are expanded when they have a wildcard, so the pattern only has to match one
+			if strings.ContainsAny(resourceType.Value, "*?[") {
+				if expanded := registry.ExpandNames([]string{resourceType.Value}); len(expanded) == 1 &&
+					expanded[0] == resourceType.Value {
+					v.report(resourceType, SeverityWarning, "'%s' matches no resource type", resourceType.Value)
+				}
+				continue
+			}
+
+			v.validateResourceType(resourceType)
+		}
+	})
+}
+
+func (v *validator) validateFilters(node *yaml.Node) {
+	forEachPair(node, func(key, value *yaml.Node) {
+		if key.Value == filter.Global {
+			return
+		}
+
+		reg := v.validateResourceType(key)
+		if reg == nil {
+			return
+		}
+
+		properties := documentedProperties(reg.Resource)
+		if len(properties) == 0 {
+			return
+		}
+
+		for _, f := range value.Content {
+			forEachPair(f, func(key, value *yaml.Node) {
+				if key.Value == "property" {
+					v.validateProperty(value, reg.Name, properties)
+				}
+			})
+		}
+	})
+}
+
+func (v *validator) validateProperty(node *yaml.Node, resourceType string, properties []string) {
+	property := node.Value
+	if property == "" || property == createdAtProperty || strings.HasPrefix(property, "tag:") {
+		return
+	}
+
+	if !slices.Contains(properties, property) {
+		v.report(node, SeverityWarning, "property '%s' is not documented for resource type '%s'%s",
+			property, resourceType, suggest(property, properties))
+	}
+}
+
+func (v *validator) validateSettings(node *yaml.Node) {
+	forEachPair(node, func(key, value *yaml.Node) {
+		reg := v.validateResourceType(key)
+		if reg == nil {
+			return
+		}
+
+		forEachPair(value, func(key, _ *yaml.Node) {
+			if !slices.Contains(reg.Settings, key.Value) {
+				if len(reg.Settings) == 0 {
+					v.report(key, SeverityError, "resource type '%s' has no settings", reg.Name)
+					return
+				}
+
+				v.report(key, SeverityError, "unknown setting '%s' of resource type '%s'%s",
+					key.Value, reg.Name, suggest(key.Value, reg.Settings))
+			}
+		})
+	})
+}
+
+// documentedProperties returns the properties that the resource documents with its struct fields, without the tags.
+func documentedProperties(resource interface{}) []string {
+	var properties []string
+	for property := range docs.GeneratePropertiesMap(resource) {
+		if !strings.HasPrefix(property, "tag:") {
+			properties = append(properties, property)
+		}
+	}
+
+	sort.Strings(properties)
+
+	return properties
+}
+
+// forEachPair calls the function with every key and value of the mapping node, it does nothing for other nodes.
+func forEachPair(node *yaml.Node, fn func(key, value *yaml.Node)) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fn(node.Content[i], node.Content[i+1])
+	}
+}
+
+// yamlKeys returns the YAML keys of the struct type, including the keys of inlined structs.
+func yamlKeys(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("yaml")
+		name, options, _ := strings.Cut(tag, ",")
+
+		switch {
+		case options == "inline":
+			keys = append(keys, yamlKeys(t.Field(i).Type)...)
+		case name != "" && name != "-":
+			keys = append(keys, name)
+		}
+	}
+
+	return keys
+}
+
+// suggest returns a hint with the candidate that is closest to the value, when one is close enough to be a typo.
+func suggest(value string, candidates []string) string {
+	best, bestDistance := "", len(value)/3+2
+	for _, candidate := range candidates {
+		if distance := levenshtein(strings.ToLower(value), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", did you mean '%s'?", best)
+}
+
+// levenshtein returns the number of edits that turn one string into the other.
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,177 @@
+// Package cost estimates the monthly cost of resources from an offline pricing table, so that a dry run shows what
+// removing the resources would save. The estimates are approximate, the prices are looked up by the properties of a
+// resource, such as the instance type, without calling any pricing API.
+package cost
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+//go:embed pricing.yaml
+var bundledTable []byte
+
+// Table is a pricing table with the rules that price the resources, by resource type.
+type Table struct {
+	Resources map[string]*Rule `yaml:"resources"`
+}
+
+// Rule prices the resources of a resource type. The monthly price is either the price of the value of Property in
+// Prices, or the flat Price when the rule has no property, and is multiplied by the value of the Quantity property.
+type Rule struct {
+	// Property is the property of the resource whose value selects the price, for example InstanceType.
+	Property string `yaml:"property"`
+
+	// Prices are the monthly prices by value of the property.
+	Prices map[string]float64 `yaml:"prices"`
+
+	// Price is the monthly price of every resource, when the rule has no property.
+	Price float64 `yaml:"price"`
+
+	// Quantity is the numeric property that the price is multiplied by, for example the size of a volume in GiB.
+	Quantity string `yaml:"quantity"`
+
+	// When are the values that properties must have for the resource to cost anything, a stopped instance is not
+	// billed for example.
+	When map[string]string `yaml:"when"`
+}
+
+// Bundled returns the pricing table that is bundled with aws-nuke.
+func Bundled() (*Table, error) {
+	t := &Table{}
+	if err := yaml.Unmarshal(bundledTable, t); err != nil {
+		return nil, fmt.Errorf("invalid bundled pricing table: %w", err)
+	}
+
+	return t, nil
+}
+
+// Load returns the bundled pricing table with the rules of the pricing table file at the path added. A rule of the
+// file replaces the bundled rule of the same resource type. Without a path the bundled pricing table is returned.
+func Load(path string) (*Table, error) {
+	t, err := Bundled()
+	if err != nil || path == "" {
+		return t, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pricing table: %w", err)
+	}
+
+	file := &Table{}
+	if err := yaml.Unmarshal(raw, file); err != nil {
+		return nil, fmt.Errorf("invalid pricing table %s: %w", path, err)
+	}
+
+	for resourceType, rule := range file.Resources {
+		if rule == nil {
+			delete(t.Resources, resourceType)
+			continue
+		}
+
+		t.Resources[resourceType] = rule
+	}
+
+	return t, nil
+}
+
+// Estimate returns the estimated monthly cost of a resource, and false when the table cannot price the resource.
+func (t *Table) Estimate(resourceType string, props types.Properties) (float64, bool) {
+	rule, ok := t.Resources[resourceType]
+	if !ok || rule == nil {
+		return 0, false
+	}
+
+	for key, value := range rule.When {
+		if props.Get(key) != value {
+			return 0, true
+		}
+	}
+
+	price := rule.Price
+	if rule.Property != "" {
+		price, ok = rule.Prices[props.Get(rule.Property)]
+		if !ok {
+			return 0, false
+		}
+	}
+
+	if rule.Quantity != "" {
+		quantity, err := strconv.ParseFloat(props.Get(rule.Quantity), 64)
+		if err != nil {
+			return 0, false
+		}
+
+		price *= quantity
+	}
+
+	return price, true
+}
+
+// Estimate is the estimated monthly cost of the resources of a resource type in a region.
+type Estimate struct {
+	Region       string
+	ResourceType string
+	Count        int
+	Monthly      float64
+}
+
+// Summary adds up the estimated monthly costs of resources by region and resource type.
+type Summary struct {
+	estimates map[[2]string]*Estimate
+}
+
+// NewSummary returns an empty summary.
+func NewSummary() *Summary {
+	return &Summary{estimates: make(map[[2]string]*Estimate)}
+}
+
+// Add adds the estimated monthly cost of a resource.
+func (s *Summary) Add(region, resourceType string, monthly float64) {
+	key := [2]string{region, resourceType}
+
+	e, ok := s.estimates[key]
+	if !ok {
+		e = &Estimate{Region: region, ResourceType: resourceType}
+		s.estimates[key] = e
+	}
+
+	e.Count++
+	e.Monthly += monthly
+}
+
+// Estimates returns the estimates sorted by region and resource type.
+func (s *Summary) Estimates() []*Estimate {
+	estimates := make([]*Estimate, 0, len(s.estimates))
+	for _, e := range s.estimates {
+		estimates = append(estimates, e)
+	}
+
+	sort.Slice(estimates, func(i, j int) bool {
+		if estimates[i].Region != estimates[j].Region {
+			return estimates[i].Region < estimates[j].Region
+		}
+
+		return estimates[i].ResourceType < estimates[j].ResourceType
+	})
+
+	return estimates
+}
+
+// Total returns the estimated monthly cost of all resources.
+func (s *Summary) Total() float64 {
+	total := 0.0
+	for _, e := range s.estimates {
+		total += e.Monthly
+	}
+
+	return total
+}
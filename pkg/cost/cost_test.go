@@ -0,0 +1,114 @@
+package cost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+func TestTable_Estimate(t *testing.T) {
+	table, err := Bundled()
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name         string
+		resourceType string
+		props        types.Properties
+		monthly      float64
+		ok           bool
+	}{
+		{
+			name:         "property",
+			resourceType: "EC2Instance",
+			props:        types.NewProperties().Set("InstanceType", "t3.large").Set("InstanceState", "running"),
+			monthly:      60.74,
+			ok:           true,
+		},
+		{
+			name:         "when",
+			resourceType: "EC2Instance",
+			props:        types.NewProperties().Set("InstanceType", "t3.large").Set("InstanceState", "stopped"),
+			ok:           true,
+		},
+		{
+			name:         "unknown value",
+			resourceType: "EC2Instance",
+			props:        types.NewProperties().Set("InstanceType", "x9.huge").Set("InstanceState", "running"),
+		},
+		{
+			name:         "quantity",
+			resourceType: "RedshiftCluster",
+			props:        types.NewProperties().Set("NodeType", "dc2.large").Set("NumberOfNodes", 2),
+			monthly:      365,
+			ok:           true,
+		},
+		{
+			name:         "missing quantity",
+			resourceType: "EC2Volume",
+			props:        types.NewProperties().Set("VolumeType", "gp3"),
+		},
+		{
+			name:         "flat",
+			resourceType: "EC2NATGateway",
+			props:        types.NewProperties(),
+			monthly:      32.85,
+			ok:           true,
+		},
+		{
+			name:         "unknown resource type",
+			resourceType: "IAMRole",
+			props:        types.NewProperties(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			monthly, ok := table.Estimate(tc.resourceType, tc.props)
+			assert.Equal(t, tc.ok, ok)
+			assert.InDelta(t, tc.monthly, monthly, 0.001)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	table, err := Load("testdata/pricing.yaml")
+	assert.NoError(t, err)
+
+	monthly, ok := table.Estimate("EC2Instance", types.NewProperties().Set("InstanceType", "t3.micro"))
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, monthly)
+
+	_, ok = table.Estimate("EC2Instance", types.NewProperties().Set("InstanceType", "t3.large"))
+	assert.False(t, ok, "the rule of the file replaces the bundled rule")
+
+	_, ok = table.Estimate("EC2NATGateway", types.NewProperties())
+	assert.False(t, ok, "an empty rule removes the bundled rule")
+
+	monthly, ok = table.Estimate("CustomResource", types.NewProperties())
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, monthly)
+
+	monthly, ok = table.Estimate("EKSCluster", types.NewProperties())
+	assert.True(t, ok)
+	assert.Equal(t, 73.0, monthly)
+
+	_, err = Load("testdata/missing.yaml")
+	assert.ErrorContains(t, err, "unable to read pricing table")
+}
+
+func TestSummary(t *testing.T) {
+	s := NewSummary()
+	s.Add("us-east-1", "EC2Instance", 10)
+	s.Add("global", "EKSCluster", 73)
+	s.Add("us-east-1", "EC2Instance", 20)
+	s.Add("us-east-1", "EC2Address", 3.65)
+
+	assert.Equal(t, []*Estimate{
+		{Region: "global", ResourceType: "EKSCluster", Count: 1, Monthly: 73},
+		{Region: "us-east-1", ResourceType: "EC2Address", Count: 1, Monthly: 3.65},
+		{Region: "us-east-1", ResourceType: "EC2Instance", Count: 2, Monthly: 30},
+	}, s.Estimates())
+	assert.InDelta(t, 106.65, s.Total(), 0.001)
+}
@@ -0,0 +1,193 @@
+// Package diff compares two snapshots of the nukeable inventory of an account. It shows which resources appeared or
+// disappeared between the snapshots, and which resources changed their properties, so that the changes made since the
+// last sweep can be reviewed before approving deletion.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/plan"
+	"github.com/ekristen/aws-nuke/v3/pkg/report"
+)
+
+// Result is the difference between two snapshots.
+type Result struct {
+	Appeared    []*plan.Item
+	Disappeared []*plan.Item
+	Changed     []*Change
+}
+
+// Empty returns true if there is no difference between the snapshots.
+func (r *Result) Empty() bool {
+	return len(r.Appeared) == 0 && len(r.Disappeared) == 0 && len(r.Changed) == 0
+}
+
+// Change is a resource that exists in both snapshots but whose properties changed.
+type Change struct {
+	Item       *plan.Item
+	Properties []*PropertyChange
+}
+
+// PropertyChange is a single property that changed, was added or was removed. Added properties have an empty Old
+// value and removed properties have an empty New value.
+type PropertyChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// Compare returns the difference between the old and the new snapshot. When properties are given, only changes to
+// those properties are considered, a property ending with an asterisk matches every property with that prefix, for
+// example tag:* matches all tags.
+func Compare(oldSnapshot, newSnapshot *plan.Plan, properties []string) *Result {
+	oldItems := make(map[string]*plan.Item, len(oldSnapshot.Items))
+	for _, item := range oldSnapshot.Items {
+		oldItems[item.Key()] = item
+	}
+
+	newItems := make(map[string]*plan.Item, len(newSnapshot.Items))
+	for _, item := range newSnapshot.Items {
+		newItems[item.Key()] = item
+	}
+
+	result := &Result{}
+
+	for _, item := range newSnapshot.Items {
+		oldItem, ok := oldItems[item.Key()]
+		if !ok {
+			result.Appeared = append(result.Appeared, item)
+			continue
+		}
+
+		if changes := compareProperties(oldItem.Properties, item.Properties, properties); len(changes) > 0 {
+			result.Changed = append(result.Changed, &Change{Item: item, Properties: changes})
+		}
+	}
+
+	for _, item := range oldSnapshot.Items {
+		if _, ok := newItems[item.Key()]; !ok {
+			result.Disappeared = append(result.Disappeared, item)
+		}
+	}
+
+	sortItems(result.Appeared)
+	sortItems(result.Disappeared)
+	sort.SliceStable(result.Changed, func(i, j int) bool {
+		return result.Changed[i].Item.Key() < result.Changed[j].Item.Key()
+	})
+
+	return result
+}
+
+// compareProperties returns the properties that differ between the old and the new properties.
+func compareProperties(oldProps, newProps map[string]string, properties []string) []*PropertyChange {
+	keys := map[string]bool{}
+	for k := range oldProps {
+		keys[k] = true
+	}
+	for k := range newProps {
+		keys[k] = true
+	}
+
+	var changes []*PropertyChange
+	for k := range keys {
+		if !matchesProperty(k, properties) {
+			continue
+		}
+
+		if oldProps[k] == newProps[k] {
+			continue
+		}
+
+		changes = append(changes, &PropertyChange{
+			Key: k,
+			Old: oldProps[k],
+			New: newProps[k],
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// matchesProperty returns true if the property key is one of the properties to compare.
+func matchesProperty(key string, properties []string) bool {
+	if len(properties) == 0 {
+		return true
+	}
+
+	for _, property := range properties {
+		if prefix, ok := strings.CutSuffix(property, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+			continue
+		}
+
+		if key == property {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortItems(items []*plan.Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Key() < items[j].Key()
+	})
+}
+
+// LoadSnapshot reads a snapshot from the file at the given path. Both plan files and JSON run reports are accepted,
+// for a run report only the resources that were not filtered are part of the snapshot.
+func LoadSnapshot(path string) (*plan.Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Records json.RawMessage `json:"records"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse snapshot %s: %w", path, err)
+	}
+
+	if doc.Records == nil {
+		return plan.Load(path)
+	}
+
+	r := &report.Report{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("unable to parse report %s: %w", path, err)
+	}
+
+	snapshot := &plan.Plan{
+		AccountID:    r.AccountID,
+		AccountAlias: r.AccountAlias,
+		CreatedAt:    r.StartedAt,
+		Items:        make([]*plan.Item, 0),
+	}
+
+	for _, rec := range r.Records {
+		if rec.Filtered {
+			continue
+		}
+
+		snapshot.Items = append(snapshot.Items, &plan.Item{
+			Region:       rec.Region,
+			ResourceType: rec.ResourceType,
+			Identity:     rec.Identity,
+			Properties:   rec.Properties,
+		})
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,105 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/plan"
+)
+
+func testSnapshot(items ...*plan.Item) *plan.Plan {
+	return &plan.Plan{
+		AccountID: "123456789012",
+		Items:     items,
+	}
+}
+
+func TestCompare(t *testing.T) {
+	oldSnapshot := testSnapshot(
+		&plan.Item{Region: "global", ResourceType: "IAMRole", Identity: "kept",
+			Properties: map[string]string{"LastUsedDate": "2024-01-01", "tag:owner": "alice"}},
+		&plan.Item{Region: "global", ResourceType: "IAMRole", Identity: "removed"},
+	)
+
+	newSnapshot := testSnapshot(
+		&plan.Item{Region: "global", ResourceType: "IAMRole", Identity: "kept",
+			Properties: map[string]string{"LastUsedDate": "2024-02-01", "tag:team": "platform"}},
+		&plan.Item{Region: "us-east-1", ResourceType: "EC2Instance", Identity: "added"},
+	)
+
+	result := Compare(oldSnapshot, newSnapshot, nil)
+	assert.False(t, result.Empty())
+	assert.Len(t, result.Appeared, 1)
+	assert.Equal(t, "added", result.Appeared[0].Identity)
+	assert.Len(t, result.Disappeared, 1)
+	assert.Equal(t, "removed", result.Disappeared[0].Identity)
+	assert.Len(t, result.Changed, 1)
+	assert.Equal(t, []*PropertyChange{
+		{Key: "LastUsedDate", Old: "2024-01-01", New: "2024-02-01"},
+		{Key: "tag:owner", Old: "alice", New: ""},
+		{Key: "tag:team", Old: "", New: "platform"},
+	}, result.Changed[0].Properties)
+
+	tagsOnly := Compare(oldSnapshot, newSnapshot, []string{"tag:*"})
+	assert.Len(t, tagsOnly.Changed, 1)
+	assert.Len(t, tagsOnly.Changed[0].Properties, 2)
+
+	unrelated := Compare(oldSnapshot, newSnapshot, []string{"CreateDate"})
+	assert.Len(t, unrelated.Changed, 0)
+
+	assert.True(t, Compare(oldSnapshot, oldSnapshot, nil).Empty())
+}
+
+func TestCompare_WithoutIdentity(t *testing.T) {
+	oldSnapshot := testSnapshot(
+		&plan.Item{Region: "us-east-1", ResourceType: "ECSTask",
+			Properties: map[string]string{"TaskARN": "arn:task", "ClusterARN": "arn:cluster", "tag:owner": "alice"}},
+	)
+
+	newSnapshot := testSnapshot(
+		&plan.Item{Region: "us-east-1", ResourceType: "ECSTask",
+			Properties: map[string]string{"TaskARN": "arn:task", "ClusterARN": "arn:cluster", "tag:owner": "bob"}},
+	)
+
+	result := Compare(oldSnapshot, newSnapshot, nil)
+	assert.Len(t, result.Appeared, 0)
+	assert.Len(t, result.Disappeared, 0)
+	assert.Len(t, result.Changed, 1)
+	assert.Equal(t, []*PropertyChange{
+		{Key: "tag:owner", Old: "alice", New: "bob"},
+	}, result.Changed[0].Properties)
+}
+
+func TestLoadSnapshot_Report(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+  "accountId": "123456789012",
+  "records": [
+    {"region": "global", "resourceType": "IAMRole", "identity": "a", "filtered": false, "state": "new"},
+    {"region": "global", "resourceType": "IAMRole", "identity": "b", "filtered": true, "state": "filtered"}
+  ]
+}`), 0o600))
+
+	snapshot, err := LoadSnapshot(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012", snapshot.AccountID)
+	assert.Len(t, snapshot.Items, 1)
+	assert.Equal(t, "a", snapshot.Items[0].Identity)
+}
+
+func TestLoadSnapshot_Plan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+  "accountId": "123456789012",
+  "items": [
+    {"region": "global", "resourceType": "IAMRole", "identity": "a"}
+  ]
+}`), 0o600))
+
+	snapshot, err := LoadSnapshot(path)
+	assert.NoError(t, err)
+	assert.Len(t, snapshot.Items, 1)
+}
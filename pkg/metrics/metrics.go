@@ -0,0 +1,191 @@
+// Package metrics provides Prometheus metrics of a nuke run, so that runs that are scheduled as jobs can be monitored
+// without parsing their logs. The metrics are either served while the run is in progress, or pushed to a Pushgateway
+// once the run is complete.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+)
+
+const namespace = "aws_nuke"
+
+const (
+	// ResultSuccess is the result of an API call that succeeded.
+	ResultSuccess = "success"
+
+	// ResultThrottled is the result of an API call that was throttled.
+	ResultThrottled = "throttled"
+
+	// ResultError is the result of an API call that failed for any other reason.
+	ResultError = "error"
+)
+
+// Metrics holds the collectors of a nuke run and the registry they are registered with.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	scanned  *prometheus.CounterVec
+	filtered *prometheus.CounterVec
+	removed  *prometheus.CounterVec
+	failed   *prometheus.CounterVec
+	waiting  *prometheus.GaugeVec
+
+	apiCallDuration *prometheus.HistogramVec
+	listDuration    *prometheus.HistogramVec
+	removalDuration *prometheus.HistogramVec
+}
+
+// New returns the metrics of a nuke run, registered with a registry of their own.
+func New() *Metrics {
+	resourceLabels := []string{"account", "region", "resource_type"}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		scanned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resources_scanned_total",
+			Help:      "Number of resources that were discovered by the scan.",
+		}, resourceLabels),
+		filtered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resources_filtered_total",
+			Help:      "Number of resources that were filtered and are not removed.",
+		}, resourceLabels),
+		removed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resources_removed_total",
+			Help:      "Number of resources that were confirmed as removed.",
+		}, resourceLabels),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resources_failed_total",
+			Help:      "Number of times that the removal of a resource failed.",
+		}, resourceLabels),
+		waiting: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "resources_waiting",
+			Help:      "Number of resources whose removal was requested, but is not confirmed yet.",
+		}, resourceLabels),
+		apiCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_call_duration_seconds",
+			Help:      "Latency of the attempts of the calls to the AWS APIs.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "operation", "region", "result"}),
+		listDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "list_duration_seconds",
+			Help:      "Duration of listing every resource type of a region.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}, []string{"account", "region"}),
+		removalDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "removal_duration_seconds",
+			Help:      "Duration from the removal request of a resource until it was confirmed as removed.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+		}, resourceLabels),
+	}
+
+	m.registry.MustRegister(m.scanned, m.filtered, m.removed, m.failed, m.waiting,
+		m.apiCallDuration, m.listDuration, m.removalDuration)
+
+	return m
+}
+
+// Registry returns the registry that the metrics are registered with.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler returns the HTTP handler that serves the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Push pushes the metrics to the Pushgateway at the URL under the job name.
+func (m *Metrics) Push(url, job string) error {
+	return push.New(url, job).Gatherer(m.registry).Push()
+}
+
+// ObserveRequest records the latency of an attempt of an API call.
+func (m *Metrics) ObserveRequest(service, operation, region, result string, duration time.Duration) {
+	m.apiCallDuration.WithLabelValues(service, operation, region, result).Observe(duration.Seconds())
+}
+
+// ObserveList records how long it took to list every resource type of a region.
+func (m *Metrics) ObserveList(accountID, region string, duration time.Duration) {
+	m.listDuration.WithLabelValues(accountID, region).Observe(duration.Seconds())
+}
+
+// Tracker records the metrics of the resources of an account. The queue is compared with the state every item had the
+// previous time it was seen, so the counters are only increased when the state of an item changes.
+type Tracker struct {
+	metrics   *Metrics
+	accountID string
+
+	states    map[*queue.Item]queue.ItemState
+	requested map[*queue.Item]time.Time
+
+	now func() time.Time
+}
+
+// NewTracker returns the tracker of the resources of the account.
+func (m *Metrics) NewTracker(accountID string) *Tracker {
+	return &Tracker{
+		metrics:   m,
+		accountID: accountID,
+		states:    make(map[*queue.Item]queue.ItemState),
+		requested: make(map[*queue.Item]time.Time),
+		now:       time.Now,
+	}
+}
+
+// Update records the changes of the items in the queue since the last update. It is called after the scan and after
+// every pass over the queue, so the removal duration is measured between passes.
+func (t *Tracker) Update(q *queue.Queue) {
+	now := t.now()
+
+	t.metrics.waiting.DeletePartialMatch(prometheus.Labels{"account": t.accountID})
+
+	for _, item := range q.GetItems() {
+		labels := prometheus.Labels{"account": t.accountID, "region": item.Owner, "resource_type": item.Type}
+		state := item.GetState()
+
+		if state == queue.ItemStatePending || state == queue.ItemStateWaiting {
+			t.metrics.waiting.With(labels).Inc()
+		}
+
+		previous, seen := t.states[item]
+		if !seen {
+			t.metrics.scanned.With(labels).Inc()
+		} else if previous == state {
+			continue
+		}
+
+		t.states[item] = state
+
+		switch state {
+		case queue.ItemStateFiltered:
+			t.metrics.filtered.With(labels).Inc()
+		case queue.ItemStatePending, queue.ItemStateWaiting:
+			if _, ok := t.requested[item]; !ok {
+				t.requested[item] = now
+			}
+		case queue.ItemStateFailed:
+			t.metrics.failed.With(labels).Inc()
+		case queue.ItemStateFinished:
+			t.metrics.removed.With(labels).Inc()
+			if requested, ok := t.requested[item]; ok {
+				t.metrics.removalDuration.With(labels).Observe(now.Sub(requested).Seconds())
+			}
+		default:
+		}
+	}
+}
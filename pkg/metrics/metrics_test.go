@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+)
+
+type TestResource struct{}
+
+func (r *TestResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func testItem(state queue.ItemState, owner string) *queue.Item {
+	return &queue.Item{
+		Resource: &TestResource{},
+		State:    state,
+		Type:     "TestResource",
+		Owner:    owner,
+	}
+}
+
+func TestTracker_Update(t *testing.T) {
+	m := New()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := m.NewTracker("123456789012")
+	tracker.now = func() time.Time { return now }
+
+	removed := testItem(queue.ItemStateNew, "us-east-1")
+	failed := testItem(queue.ItemStateNew, "us-east-1")
+	filtered := testItem(queue.ItemStateFiltered, "global")
+
+	q := queue.New()
+	q.Items = append(q.Items, removed, failed, filtered)
+
+	// after the scan
+	tracker.Update(q)
+
+	// after the first pass
+	removed.State = queue.ItemStatePending
+	failed.State = queue.ItemStateFailed
+	tracker.Update(q)
+
+	// after the second pass
+	now = now.Add(30 * time.Second)
+	removed.State = queue.ItemStateWaiting
+	failed.State = queue.ItemStateNew
+	tracker.Update(q)
+
+	// after the third pass
+	now = now.Add(30 * time.Second)
+	removed.State = queue.ItemStateFinished
+	failed.State = queue.ItemStateFailed
+	tracker.Update(q)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.scanned.WithLabelValues("123456789012", "us-east-1", "TestResource")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.scanned.WithLabelValues("123456789012", "global", "TestResource")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.filtered.WithLabelValues("123456789012", "global", "TestResource")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.removed.WithLabelValues("123456789012", "us-east-1", "TestResource")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.failed.WithLabelValues("123456789012", "us-east-1", "TestResource")))
+	assert.Equal(t, 0, testutil.CollectAndCount(m.waiting))
+
+	expected := `
+# HELP aws_nuke_removal_duration_seconds Duration from the removal request of a resource until it was confirmed as removed.
+# TYPE aws_nuke_removal_duration_seconds histogram
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="1"} 0
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="2"} 0
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="4"} 0
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="8"} 0
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="16"} 0
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="32"} 0
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="64"} 1
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="128"} 1
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="256"} 1
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="512"} 1
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="1024"} 1
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="2048"} 1
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="4096"} 1
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="8192"} 1
+aws_nuke_removal_duration_seconds_bucket{account="123456789012",region="us-east-1",resource_type="TestResource",le="+Inf"} 1
+aws_nuke_removal_duration_seconds_sum{account="123456789012",region="us-east-1",resource_type="TestResource"} 60
+aws_nuke_removal_duration_seconds_count{account="123456789012",region="us-east-1",resource_type="TestResource"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(m.removalDuration, strings.NewReader(expected)))
+}
+
+func TestTracker_Waiting(t *testing.T) {
+	m := New()
+	tracker := m.NewTracker("123456789012")
+
+	q := queue.New()
+	q.Items = append(q.Items,
+		testItem(queue.ItemStatePending, "us-east-1"),
+		testItem(queue.ItemStateWaiting, "us-east-1"),
+		testItem(queue.ItemStateNew, "us-east-1"))
+
+	tracker.Update(q)
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.waiting.WithLabelValues("123456789012", "us-east-1", "TestResource")))
+
+	q.Items[0].State = queue.ItemStateFinished
+	tracker.Update(q)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.waiting.WithLabelValues("123456789012", "us-east-1", "TestResource")))
+}
+
+func TestMetrics_Push(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := New()
+	m.ObserveRequest("iam", "ListRoles", "us-east-1", ResultSuccess, time.Second)
+
+	assert.NoError(t, m.Push(server.URL, "aws-nuke"))
+	assert.Equal(t, "/metrics/job/aws-nuke", path)
+}
@@ -0,0 +1,262 @@
+// Package notify posts a summary of a nuke run to webhooks once the run finishes or aborts, so that runs that are
+// scheduled as jobs are noticed without reading their logs. The summary is either posted as JSON, or rendered with a
+// template into the payload of a chat service such as Slack or Microsoft Teams.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+	"github.com/ekristen/aws-nuke/v3/pkg/report"
+)
+
+const (
+	// StatusFinished is the status of a run that finished.
+	StatusFinished = "finished"
+
+	// StatusAborted is the status of a run that aborted with an error.
+	StatusAborted = "aborted"
+)
+
+// MaxFailures is the maximum number of failures that are listed in a summary, chat services limit the size of a
+// message.
+const MaxFailures = 25
+
+// Summary is the summary of a run that is posted to the webhooks.
+type Summary struct {
+	AccountID     string                 `json:"accountId"`
+	AccountAlias  string                 `json:"accountAlias,omitempty"`
+	DryRun        bool                   `json:"dryRun"`
+	Status        string                 `json:"status"`
+	Error         string                 `json:"error,omitempty"`
+	StartedAt     time.Time              `json:"startedAt"`
+	FinishedAt    time.Time              `json:"finishedAt"`
+	Duration      string                 `json:"duration"`
+	Total         int                    `json:"total"`
+	Filtered      int                    `json:"filtered"`
+	Removed       int                    `json:"removed"`
+	Failed        int                    `json:"failed"`
+	Waiting       int                    `json:"waiting"`
+	ResourceTypes []*ResourceTypeSummary `json:"resourceTypes"`
+	Failures      []*Failure             `json:"failures"`
+
+	// MoreFailures is the number of failures that are not listed, beyond MaxFailures.
+	MoreFailures int `json:"moreFailures,omitempty"`
+}
+
+// ResourceTypeSummary contains the counts of the resources of a resource type.
+type ResourceTypeSummary struct {
+	ResourceType string `json:"resourceType"`
+	Total        int    `json:"total"`
+	Filtered     int    `json:"filtered"`
+	Removed      int    `json:"removed"`
+	Failed       int    `json:"failed"`
+	Waiting      int    `json:"waiting"`
+}
+
+// Failure is a resource that could not be removed.
+type Failure struct {
+	Region       string `json:"region"`
+	ResourceType string `json:"resourceType"`
+	Identity     string `json:"identity,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// NewSummary summarizes the report of a run. Only the resource types that have resources which are not filtered are
+// part of the summary.
+func NewSummary(r *report.Report) *Summary {
+	s := &Summary{
+		AccountID:     r.AccountID,
+		AccountAlias:  r.AccountAlias,
+		DryRun:        r.DryRun,
+		Status:        StatusFinished,
+		StartedAt:     r.StartedAt,
+		FinishedAt:    r.FinishedAt,
+		ResourceTypes: make([]*ResourceTypeSummary, 0),
+		Failures:      make([]*Failure, 0),
+	}
+
+	if r.Summary != nil {
+		s.Duration = r.Summary.Duration
+		s.Total = r.Summary.Total
+		s.Filtered = r.Summary.Filtered
+		s.Removed = r.Summary.Removed
+		s.Failed = r.Summary.Failed
+		s.Waiting = r.Summary.Waiting
+
+		if r.Summary.Error != "" {
+			s.Status = StatusAborted
+			s.Error = r.Summary.Error
+		}
+	}
+
+	resourceTypes := make(map[string]*ResourceTypeSummary)
+
+	for _, rec := range r.Records {
+		rt, ok := resourceTypes[rec.ResourceType]
+		if !ok {
+			rt = &ResourceTypeSummary{ResourceType: rec.ResourceType}
+			resourceTypes[rec.ResourceType] = rt
+		}
+
+		rt.Total++
+
+		switch rec.State {
+		case queue.ItemStateFiltered.String():
+			rt.Filtered++
+		case queue.ItemStateFinished.String():
+			rt.Removed++
+		case queue.ItemStateFailed.String():
+			rt.Failed++
+
+			if len(s.Failures) < MaxFailures {
+				s.Failures = append(s.Failures, &Failure{
+					Region:       rec.Region,
+					ResourceType: rec.ResourceType,
+					Identity:     rec.Identity,
+					Error:        rec.Error,
+				})
+			} else {
+				s.MoreFailures++
+			}
+		case queue.ItemStateWaiting.String(), queue.ItemStatePending.String(),
+			queue.ItemStatePendingDependency.String(), queue.ItemStateHold.String():
+			rt.Waiting++
+		}
+	}
+
+	for _, rt := range resourceTypes {
+		if rt.Filtered < rt.Total {
+			s.ResourceTypes = append(s.ResourceTypes, rt)
+		}
+	}
+
+	sort.Slice(s.ResourceTypes, func(i, j int) bool {
+		return s.ResourceTypes[i].ResourceType < s.ResourceTypes[j].ResourceType
+	})
+
+	return s
+}
+
+// Notifier posts the summary of a run to the configured webhooks.
+type Notifier struct {
+	webhooks []*webhook
+	client   *http.Client
+}
+
+// webhook is a configured webhook with its parsed template, the template is nil for the JSON format.
+type webhook struct {
+	url      string
+	headers  map[string]string
+	template *template.Template
+}
+
+// New returns a notifier for the configured webhooks. The templates are parsed right away, so an invalid template
+// fails before the run starts.
+func New(cfg config.Notifications) (*Notifier, error) {
+	n := &Notifier{
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	for i, wh := range cfg.Webhooks {
+		text := wh.Template
+		if text == "" {
+			text = builtinTemplates[wh.Format]
+		}
+
+		var tmpl *template.Template
+		if text != "" {
+			var err error
+			tmpl, err = parseTemplate(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template of webhook %d: %w", i, err)
+			}
+		}
+
+		n.webhooks = append(n.webhooks, &webhook{
+			url:      wh.URL,
+			headers:  wh.Headers,
+			template: tmpl,
+		})
+	}
+
+	return n, nil
+}
+
+// Enabled returns whether any webhook is configured.
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.webhooks) > 0
+}
+
+// Notify posts the summary to every webhook. A failed webhook does not stop the summary from being posted to the
+// others, the errors of all webhooks are returned.
+func (n *Notifier) Notify(ctx context.Context, s *Summary) error {
+	if !n.Enabled() {
+		return nil
+	}
+
+	var errs []error
+	for _, wh := range n.webhooks {
+		if err := n.post(ctx, wh, s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// post renders the payload of the webhook and posts it.
+func (n *Notifier) post(ctx context.Context, wh *webhook, s *Summary) error {
+	var body bytes.Buffer
+	if wh.template != nil {
+		if err := wh.template.Execute(&body, s); err != nil {
+			return fmt.Errorf("unable to render notification for %s: %w", redact(wh.url), err)
+		}
+	} else if err := json.NewEncoder(&body).Encode(s); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.url, &body)
+	if err != nil {
+		return fmt.Errorf("unable to post notification to %s: %w", redact(wh.url), withoutURL(err))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range wh.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to post notification to %s: %w", redact(wh.url), withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unable to post notification to %s: %s", redact(wh.url), resp.Status)
+	}
+
+	return nil
+}
+
+// withoutURL returns the error without the URL of the request. The errors of the HTTP client contain the full URL, which
+// includes the secret path of webhooks such as the ones of Slack and Microsoft Teams.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+	"github.com/ekristen/aws-nuke/v3/pkg/report"
+)
+
+func testReport() *report.Report {
+	return &report.Report{
+		AccountID:    "123456789012",
+		AccountAlias: "sandbox",
+		StartedAt:    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		FinishedAt:   time.Date(2024, 3, 1, 12, 5, 0, 0, time.UTC),
+		Records: []*report.Record{
+			{Region: "global", ResourceType: "IAMRole", Identity: "admin", State: "filtered", Filtered: true},
+			{Region: "global", ResourceType: "IAMRole", Identity: "ci", State: "finished"},
+			{Region: "us-east-1", ResourceType: "EC2Instance", Identity: "i-1", State: "failed",
+				Error: "UnauthorizedOperation: \"denied\""},
+			{Region: "us-east-1", ResourceType: "EC2Instance", Identity: "i-2", State: "waiting"},
+			{Region: "us-east-1", ResourceType: "EC2VPC", Identity: "vpc-1", State: "filtered", Filtered: true},
+		},
+		Summary: &report.Summary{
+			Total:    5,
+			Filtered: 2,
+			Removed:  1,
+			Failed:   1,
+			Waiting:  1,
+			Duration: "5m0s",
+			Error:    "failed",
+		},
+	}
+}
+
+func TestNewSummary(t *testing.T) {
+	s := NewSummary(testReport())
+
+	assert.Equal(t, StatusAborted, s.Status)
+	assert.Equal(t, "failed", s.Error)
+	assert.Equal(t, "5m0s", s.Duration)
+	assert.Equal(t, 1, s.Removed)
+
+	assert.Equal(t, []*ResourceTypeSummary{
+		{ResourceType: "EC2Instance", Total: 2, Failed: 1, Waiting: 1},
+		{ResourceType: "IAMRole", Total: 2, Filtered: 1, Removed: 1},
+	}, s.ResourceTypes)
+
+	assert.Equal(t, []*Failure{
+		{Region: "us-east-1", ResourceType: "EC2Instance", Identity: "i-1", Error: "UnauthorizedOperation: \"denied\""},
+	}, s.Failures)
+}
+
+func TestNewSummary_MaxFailures(t *testing.T) {
+	r := &report.Report{Summary: &report.Summary{}}
+	for i := 0; i < MaxFailures+3; i++ {
+		r.Records = append(r.Records, &report.Record{ResourceType: "EC2Instance", State: "failed"})
+	}
+
+	s := NewSummary(r)
+	assert.Equal(t, StatusFinished, s.Status)
+	assert.Len(t, s.Failures, MaxFailures)
+	assert.Equal(t, 3, s.MoreFailures)
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	cases := []struct {
+		name   string
+		format string
+		check  func(t *testing.T, body map[string]interface{})
+	}{
+		{
+			name: "json",
+			check: func(t *testing.T, body map[string]interface{}) {
+				assert.Equal(t, "123456789012", body["accountId"])
+				assert.Equal(t, "aborted", body["status"])
+				assert.Len(t, body["resourceTypes"], 2)
+			},
+		},
+		{
+			name:   "slack",
+			format: config.WebhookFormatSlack,
+			check: func(t *testing.T, body map[string]interface{}) {
+				assert.Contains(t, body["text"], "*aws-nuke run aborted* for account `123456789012` (sandbox)")
+				assert.Contains(t, body["text"], "• EC2Instance i-1 in us-east-1: UnauthorizedOperation: \"denied\"")
+			},
+		},
+		{
+			name:   "teams",
+			format: config.WebhookFormatTeams,
+			check: func(t *testing.T, body map[string]interface{}) {
+				assert.Equal(t, "message", body["type"])
+				assert.Len(t, body["attachments"], 1)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body map[string]interface{}
+			var authorization string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				raw, _ := io.ReadAll(r.Body)
+				assert.NoError(t, json.Unmarshal(raw, &body), string(raw))
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			n, err := New(config.Notifications{Webhooks: []config.Webhook{{
+				URL:     server.URL,
+				Format:  tc.format,
+				Headers: map[string]string{"Authorization": "Bearer secret"},
+			}}})
+			assert.NoError(t, err)
+
+			assert.NoError(t, n.Notify(context.TODO(), NewSummary(testReport())))
+			assert.Equal(t, "Bearer secret", authorization)
+			tc.check(t, body)
+		})
+	}
+}
+
+func TestNotifier_Template(t *testing.T) {
+	var raw []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n, err := New(config.Notifications{Webhooks: []config.Webhook{{
+		URL:      server.URL,
+		Template: `{"message": {{ printf "%s removed %d" .AccountID .Removed | json }}}`,
+	}}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, n.Notify(context.TODO(), NewSummary(testReport())))
+	assert.JSONEq(t, `{"message": "123456789012 removed 1"}`, string(raw))
+}
+
+func TestNotifier_Errors(t *testing.T) {
+	_, err := New(config.Notifications{Webhooks: []config.Webhook{{URL: "https://example.com", Template: "{{ .Foo"}}})
+	assert.ErrorContains(t, err, "invalid template of webhook 0")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	n, err := New(config.Notifications{Webhooks: []config.Webhook{{URL: server.URL + "/services/secret"}}})
+	assert.NoError(t, err)
+
+	err = n.Notify(context.TODO(), NewSummary(testReport()))
+	assert.ErrorContains(t, err, "403 Forbidden")
+	assert.NotContains(t, err.Error(), "secret")
+
+	// Note: the errors of the HTTP client contain the URL, the secret path of the webhook must not be logged
+	server.Close()
+
+	err = n.Notify(context.TODO(), NewSummary(testReport()))
+	assert.ErrorContains(t, err, "unable to post notification to http://"+server.Listener.Addr().String())
+	assert.NotContains(t, err.Error(), "/services")
+	assert.NotContains(t, err.Error(), "secret")
+
+	n, err = New(config.Notifications{Webhooks: []config.Webhook{{URL: "https://example.com/services/secret\x7f"}}})
+	assert.NoError(t, err)
+
+	err = n.Notify(context.TODO(), NewSummary(testReport()))
+	assert.ErrorContains(t, err, "unable to post notification")
+	assert.NotContains(t, err.Error(), "secret")
+
+	var disabled *Notifier
+	assert.False(t, disabled.Enabled())
+	assert.NoError(t, disabled.Notify(context.TODO(), NewSummary(testReport())))
+}
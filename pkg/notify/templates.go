@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"text/template"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+)
+
+// slackTemplate renders the summary as a Slack message with mrkdwn formatting.
+const slackTemplate = `
+{{- define "text" -}}
+*aws-nuke {{ if .DryRun }}dry run{{ else }}run{{ end }} {{ .Status }}* for account ` + "`{{ .AccountID }}`" +
+	`{{ with .AccountAlias }} ({{ . }}){{ end }} after {{ .Duration }}
+{{ .Removed }} removed, {{ .Failed }} failed, {{ .Waiting }} waiting, {{ .Filtered }} filtered, {{ .Total }} total
+{{- with .Error }}
+*Error:* {{ . }}
+{{- end }}
+{{- if .ResourceTypes }}
+
+*Resource types*
+{{- range .ResourceTypes }}
+• {{ .ResourceType }}: {{ .Removed }} removed, {{ .Failed }} failed, {{ .Waiting }} waiting, {{ .Total }} total
+{{- end }}
+{{- end }}
+{{- if .Failures }}
+
+*Failures*
+{{- range .Failures }}
+• {{ .ResourceType }} {{ .Identity }} in {{ .Region }}: {{ .Error }}
+{{- end }}
+{{- with .MoreFailures }}
+… and {{ . }} more
+{{- end }}
+{{- end }}
+{{- end -}}
+{"text": {{ include "text" . | json }}}
+`
+
+// teamsTemplate renders the summary as a Microsoft Teams adaptive card, as accepted by the webhooks of Teams
+// workflows.
+const teamsTemplate = `
+{{- define "title" -}}
+aws-nuke {{ if .DryRun }}dry run{{ else }}run{{ end }} {{ .Status }} for account {{ .AccountID }}
+{{- with .AccountAlias }} ({{ . }}){{ end }}
+{{- end -}}
+{{- define "text" -}}
+{{ .Removed }} removed, {{ .Failed }} failed, {{ .Waiting }} waiting, {{ .Filtered }} filtered, {{ .Total }} total ` +
+	`after {{ .Duration }}
+{{- with .Error }}
+
+**Error:** {{ . }}
+{{- end }}
+{{- end -}}
+{{- define "failures" -}}
+{{- range $i, $f := .Failures }}{{ if $i }}{{ "\n" }}{{ end -}}
+- {{ $f.ResourceType }} {{ $f.Identity }} in {{ $f.Region }}: {{ $f.Error }}
+{{- end }}
+{{- with .MoreFailures }}
+- … and {{ . }} more
+{{- end }}
+{{- end -}}
+{
+  "type": "message",
+  "attachments": [
+    {
+      "contentType": "application/vnd.microsoft.card.adaptive",
+      "content": {
+        "$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+        "type": "AdaptiveCard",
+        "version": "1.4",
+        "body": [
+          {
+            "type": "TextBlock",
+            "size": "Medium",
+            "weight": "Bolder",
+            "color": {{ if eq .Status "aborted" }}"Attention"{{ else }}"Good"{{ end }},
+            "wrap": true,
+            "text": {{ include "title" . | json }}
+          },
+          {
+            "type": "TextBlock",
+            "wrap": true,
+            "text": {{ include "text" . | json }}
+          }
+          {{- if .ResourceTypes }},
+          {
+            "type": "FactSet",
+            "facts": [
+              {{- range $i, $rt := .ResourceTypes }}{{ if $i }},{{ end }}
+              {
+                "title": {{ json $rt.ResourceType }},
+                "value": {{ printf "%d removed, %d failed, %d waiting, %d total" $rt.Removed $rt.Failed $rt.Waiting $rt.Total | json }}
+              }
+              {{- end }}
+            ]
+          }
+          {{- end }}
+          {{- if .Failures }},
+          {
+            "type": "TextBlock",
+            "wrap": true,
+            "weight": "Bolder",
+            "text": "Failures"
+          },
+          {
+            "type": "TextBlock",
+            "wrap": true,
+            "text": {{ include "failures" . | json }}
+          }
+          {{- end }}
+        ]
+      }
+    }
+  ]
+}
+`
+
+// builtinTemplates are the templates of the formats of the webhooks, the JSON format has no template.
+var builtinTemplates = map[string]string{
+	config.WebhookFormatSlack: slackTemplate,
+	config.WebhookFormatTeams: teamsTemplate,
+}
+
+// parseTemplate parses the template of a payload. Besides the functions of text/template the template can use json,
+// which encodes a value as JSON, and include, which renders a template that is defined within the template to a
+// string so that it can be encoded.
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl := template.New("webhook")
+
+	tmpl.Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"include": func(name string, data interface{}) (string, error) {
+			var buf bytes.Buffer
+			err := tmpl.ExecuteTemplate(&buf, name, data)
+			return buf.String(), err
+		},
+	})
+
+	return tmpl.Parse(text)
+}
+
+// redact returns the URL without its path and query, the URLs of chat webhooks contain their secret.
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "webhook"
+	}
+
+	return u.Scheme + "://" + u.Host
+}
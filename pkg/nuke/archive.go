@@ -0,0 +1,37 @@
+package nuke
+
+import (
+	"path"
+
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
+)
+
+const (
+	// ArchiveBucketSetting is the setting of the S3 buckets and DynamoDB tables with the bucket that their data is
+	// archived to before they are removed.
+	ArchiveBucketSetting = "ArchiveBucket"
+
+	// ArchivePrefixSetting is the setting with the prefix of the archived data in the archive bucket.
+	ArchivePrefixSetting = "ArchivePrefix"
+)
+
+// ArchiveBucket returns the bucket that the data is archived to, or an empty name when the settings do not enable
+// archival.
+func ArchiveBucket(settings *libsettings.Setting) string {
+	if settings == nil {
+		return ""
+	}
+
+	return settings.GetString(ArchiveBucketSetting)
+}
+
+// ArchiveLocation returns the bucket and the prefix that the data of the resource with the name is archived to, or an
+// empty bucket when the settings do not enable archival. The data of every resource is archived below its name.
+func ArchiveLocation(settings *libsettings.Setting, name string) (bucket, prefix string) {
+	bucket = ArchiveBucket(settings)
+	if bucket == "" {
+		return "", ""
+	}
+
+	return bucket, path.Join(settings.GetString(ArchivePrefixSetting), name) + "/"
+}
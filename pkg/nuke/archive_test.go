@@ -0,0 +1,30 @@
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
+)
+
+func TestArchiveLocation(t *testing.T) {
+	bucket, prefix := ArchiveLocation(nil, "data")
+	assert.Empty(t, bucket)
+	assert.Empty(t, prefix)
+
+	bucket, prefix = ArchiveLocation(&libsettings.Setting{ArchivePrefixSetting: "archive"}, "data")
+	assert.Empty(t, bucket)
+	assert.Empty(t, prefix)
+
+	bucket, prefix = ArchiveLocation(&libsettings.Setting{ArchiveBucketSetting: "archive-bucket"}, "data")
+	assert.Equal(t, "archive-bucket", bucket)
+	assert.Equal(t, "data/", prefix)
+
+	bucket, prefix = ArchiveLocation(&libsettings.Setting{
+		ArchiveBucketSetting: "archive-bucket",
+		ArchivePrefixSetting: "sandbox/",
+	}, "data")
+	assert.Equal(t, "archive-bucket", bucket)
+	assert.Equal(t, "sandbox/data/", prefix)
+}
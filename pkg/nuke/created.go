@@ -0,0 +1,110 @@
+package nuke
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+// CreatedAtProperty is the normalized property that holds the creation time of a resource in RFC3339 format.
+const CreatedAtProperty = "CreatedAt"
+
+// CreatedAtProperties are the properties that resources use for their creation time, in order of preference. Each
+// API names the creation time differently, for example LaunchTime on EC2Instance, InstanceCreateTime on RDSInstance
+// and StartTime on EC2Snapshot.
+var CreatedAtProperties = []string{
+	CreatedAtProperty,
+	"CreationDate",
+	"CreateDate",
+	"CreationTime",
+	"CreatedTime",
+	"CreateTime",
+	"CreatedDate",
+	"CreationDateTime",
+	"InstanceCreateTime",
+	"LaunchTime",
+	"StartTime",
+}
+
+// createdAtFormats are the formats that the creation time properties are set in by the resources.
+var createdAtFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST", // time.Time.String, when a *time.Time is set as a property
+	"2006-01-02T15:04:05.999Z0700",
+	"2006-01-02",
+}
+
+// CreatedAt returns the creation time of the resource from the first of its properties that holds one. It returns
+// false when the resource does not expose a creation time.
+func CreatedAt(r resource.Resource) (time.Time, bool) {
+	getter, ok := r.(resource.PropertyGetter)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	props := getter.Properties()
+	for _, key := range CreatedAtProperties {
+		value := props.Get(key)
+		if value == "" {
+			continue
+		}
+
+		if t, ok := parseCreatedAt(value); ok {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parseCreatedAt(value string) (time.Time, bool) {
+	for _, format := range createdAtFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, true
+		}
+	}
+
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(epoch, 0), true
+	}
+
+	return time.Time{}, false
+}
+
+// CreatedAtResource wraps a resource and adds its normalized creation time as the CreatedAt property.
+type CreatedAtResource struct {
+	resource.Resource
+	CreatedAt time.Time
+}
+
+// Filter calls the filter of the wrapped resource, if it has one.
+func (r *CreatedAtResource) Filter() error {
+	if checker, ok := r.Resource.(resource.Filter); ok {
+		return checker.Filter()
+	}
+
+	return nil
+}
+
+// Properties returns the properties of the wrapped resource with the CreatedAt property set.
+func (r *CreatedAtResource) Properties() types.Properties {
+	props := types.NewProperties()
+	if getter, ok := r.Resource.(resource.PropertyGetter); ok {
+		for key, value := range getter.Properties() {
+			props[key] = value
+		}
+	}
+
+	return props.Set(CreatedAtProperty, r.CreatedAt.UTC())
+}
+
+// String returns the legacy string of the wrapped resource.
+func (r *CreatedAtResource) String() string {
+	if stringer, ok := r.Resource.(resource.LegacyStringer); ok {
+		return stringer.String()
+	}
+
+	return ""
+}
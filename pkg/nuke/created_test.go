@@ -0,0 +1,112 @@
+package nuke
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+type testCreatedResource struct {
+	props types.Properties
+}
+
+func (r *testCreatedResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testCreatedResource) Properties() types.Properties {
+	return r.props
+}
+
+func TestCreatedAt(t *testing.T) {
+	launchTime := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		props    types.Properties
+		expected time.Time
+		ok       bool
+	}{
+		{
+			name:     "rfc3339",
+			props:    types.NewProperties().Set("LaunchTime", launchTime),
+			expected: launchTime,
+			ok:       true,
+		},
+		{
+			name:     "pointer",
+			props:    types.NewProperties().Set("CreationDate", &launchTime),
+			expected: launchTime,
+			ok:       true,
+		},
+		{
+			name:     "milliseconds",
+			props:    types.NewProperties().Set("CreationDate", "2024-03-01T12:30:00.000Z"),
+			expected: launchTime,
+			ok:       true,
+		},
+		{
+			name:     "epoch",
+			props:    types.NewProperties().Set("CreateTime", "1709296200"),
+			expected: launchTime,
+			ok:       true,
+		},
+		{
+			name:     "preference",
+			props:    types.NewProperties().Set("CreatedAt", launchTime).Set("LaunchTime", "2020-01-01"),
+			expected: launchTime,
+			ok:       true,
+		},
+		{
+			name:  "invalid",
+			props: types.NewProperties().Set("CreateDate", "yesterday"),
+		},
+		{
+			name:  "missing",
+			props: types.NewProperties().Set("Name", "test"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			createdAt, ok := CreatedAt(&testCreatedResource{props: tc.props})
+			assert.Equal(t, tc.ok, ok)
+			assert.True(t, tc.expected.Equal(createdAt))
+		})
+	}
+
+	_, ok := CreatedAt(&testLegacyResource{arn: "test"})
+	assert.False(t, ok)
+}
+
+func TestCreatedAt_Properties(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	for _, key := range CreatedAtProperties {
+		t.Run(key, func(t *testing.T) {
+			props := types.NewProperties().Set("Name", "test").Set(key, createdAt.Format(time.RFC3339))
+
+			actual, ok := CreatedAt(&testCreatedResource{props: props})
+			assert.True(t, ok)
+			assert.True(t, createdAt.Equal(actual))
+		})
+	}
+}
+
+func TestCreatedAtResource_Properties(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	r := &CreatedAtResource{
+		Resource:  &testCreatedResource{props: types.NewProperties().Set("LaunchTime", createdAt).Set("Name", "test")},
+		CreatedAt: createdAt,
+	}
+
+	props := r.Properties()
+	assert.Equal(t, "test", props.Get("Name"))
+	assert.Equal(t, "2024-03-01T11:30:00Z", props.Get(CreatedAtProperty))
+	assert.NoError(t, r.Filter())
+}
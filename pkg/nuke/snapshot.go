@@ -0,0 +1,125 @@
+package nuke
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	liberrors "github.com/ekristen/libnuke/pkg/errors"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
+)
+
+const (
+	// CreateFinalSnapshotSetting is the setting of the data stores that takes a final snapshot before they are removed.
+	CreateFinalSnapshotSetting = "CreateFinalSnapshot"
+
+	// FinalSnapshotNameSetting is the setting with the name template of the final snapshots.
+	FinalSnapshotNameSetting = "FinalSnapshotName"
+
+	// DefaultFinalSnapshotName is the name template of the final snapshots when none is set.
+	DefaultFinalSnapshotName = "{{ .Name }}-{{ .Timestamp }}"
+
+	// DeleteFinalSnapshotsSetting is the setting of the snapshots that removes the final snapshots as well.
+	DeleteFinalSnapshotsSetting = "DeleteFinalSnapshots"
+
+	// FinalSnapshotPrefix is the prefix of the name of every final snapshot. It marks the snapshot as a final snapshot,
+	// so that it is kept by later runs, see KeepFinalSnapshot. The services do not support tagging the final snapshot on the removal
+	// of the data store, the name is the only mark that is there as soon as the snapshot is.
+	FinalSnapshotPrefix = "aws-nuke-final-"
+)
+
+// FinalSnapshotName returns the name of the final snapshot of the data store with the name, or an empty name when the
+// settings do not enable final snapshots. The name template of the settings can use the Name of the data store and a
+// Timestamp, the name is always prefixed with FinalSnapshotPrefix.
+func FinalSnapshotName(settings *libsettings.Setting, name string) (string, error) {
+	if settings == nil || !settings.GetBool(CreateFinalSnapshotSetting) {
+		return "", nil
+	}
+
+	text := settings.GetString(FinalSnapshotNameSetting)
+	if text == "" {
+		text = DefaultFinalSnapshotName
+	}
+
+	tmpl, err := template.New("snapshot").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", FinalSnapshotNameSetting, err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]string{
+		"Name":      name,
+		"Timestamp": time.Now().UTC().Format("20060102150405"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", FinalSnapshotNameSetting, err)
+	}
+
+	return FinalSnapshotPrefix + buf.String(), nil
+}
+
+// IsFinalSnapshot returns whether the snapshot with the name is a final snapshot of a removed data store. The services
+// store some identifiers in lowercase, so the prefix is matched regardless of the case.
+func IsFinalSnapshot(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), FinalSnapshotPrefix)
+}
+
+// KeepFinalSnapshot returns whether the snapshot with the name is a final snapshot that is kept. Final snapshots are
+// kept by every run, unless the settings of the snapshot resource enable DeleteFinalSnapshotsSetting.
+func KeepFinalSnapshot(settings *libsettings.Setting, name string) bool {
+	if settings != nil && settings.GetBool(DeleteFinalSnapshotsSetting) {
+		return false
+	}
+
+	return IsFinalSnapshot(name)
+}
+
+// FinalSnapshot is the final snapshot of a data store. The name is rendered once, so that a removal that is retried
+// requests the snapshot with the same name, and the removal waits for that snapshot.
+type FinalSnapshot struct {
+	name     string
+	rendered bool
+}
+
+// Name returns the name of the final snapshot of the data store with the name, see FinalSnapshotName. The name is
+// rendered on the first call, the later calls return the same name.
+func (s *FinalSnapshot) Name(settings *libsettings.Setting, name string) (string, error) {
+	if s.rendered {
+		return s.name, nil
+	}
+
+	snapshotName, err := FinalSnapshotName(settings, name)
+	if err != nil {
+		return "", err
+	}
+
+	s.name = snapshotName
+	s.rendered = true
+
+	return snapshotName, nil
+}
+
+// Requested returns the name of the final snapshot that was requested by the removal, or an empty name when none was.
+func (s *FinalSnapshot) Requested() string {
+	return s.name
+}
+
+// Wait returns nil when no final snapshot was requested or once it is available, ErrWaitResource while it is created
+// and an error when it failed. The status is the status of the snapshot as returned by the service, or empty when the
+// snapshot does not exist yet.
+func (s *FinalSnapshot) Wait(status string) error {
+	if s.name == "" {
+		return nil
+	}
+
+	switch strings.ToLower(status) {
+	case "available":
+		return nil
+	case "failed", "cancelled", "deleted", "deleting":
+		return fmt.Errorf("final snapshot %s is %s", s.name, status)
+	default:
+		return liberrors.ErrWaitResource(fmt.Sprintf("waiting for final snapshot %s", s.name))
+	}
+}
@@ -0,0 +1,123 @@
+package nuke
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	liberrors "github.com/ekristen/libnuke/pkg/errors"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
+)
+
+func TestFinalSnapshotName(t *testing.T) {
+	name, err := FinalSnapshotName(nil, "db-1")
+	assert.NoError(t, err)
+	assert.Empty(t, name)
+
+	name, err = FinalSnapshotName(&libsettings.Setting{"DisableDeletionProtection": true}, "db-1")
+	assert.NoError(t, err)
+	assert.Empty(t, name)
+
+	name, err = FinalSnapshotName(&libsettings.Setting{CreateFinalSnapshotSetting: true}, "db-1")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^aws-nuke-final-db-1-\d{14}$`, name)
+	assert.True(t, IsFinalSnapshot(name))
+
+	name, err = FinalSnapshotName(&libsettings.Setting{
+		CreateFinalSnapshotSetting: true,
+		FinalSnapshotNameSetting:   "backup-{{ .Name }}",
+	}, "db-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "aws-nuke-final-backup-db-2", name)
+
+	_, err = FinalSnapshotName(&libsettings.Setting{
+		CreateFinalSnapshotSetting: true,
+		FinalSnapshotNameSetting:   "{{ .Identifier }}",
+	}, "db-1")
+	assert.ErrorContains(t, err, "invalid FinalSnapshotName")
+}
+
+func TestIsFinalSnapshot(t *testing.T) {
+	assert.True(t, IsFinalSnapshot("aws-nuke-final-db-1-20240301123000"))
+	assert.True(t, IsFinalSnapshot("AWS-Nuke-Final-db-1"))
+	assert.False(t, IsFinalSnapshot("db-1-final-20240301123000"))
+	assert.False(t, IsFinalSnapshot("rds:db-1-2024-03-01-12-30"))
+}
+
+func TestKeepFinalSnapshot(t *testing.T) {
+	assert.True(t, KeepFinalSnapshot(nil, "aws-nuke-final-db-1-20240301123000"))
+	assert.True(t, KeepFinalSnapshot(&libsettings.Setting{}, "aws-nuke-final-db-1-20240301123000"))
+	assert.False(t, KeepFinalSnapshot(&libsettings.Setting{}, "db-1-20240301123000"))
+	assert.False(t, KeepFinalSnapshot(&libsettings.Setting{DeleteFinalSnapshotsSetting: true},
+		"aws-nuke-final-db-1-20240301123000"))
+}
+
+func TestFinalSnapshot_Name(t *testing.T) {
+	settings := &libsettings.Setting{CreateFinalSnapshotSetting: true}
+
+	var snapshot FinalSnapshot
+	assert.Empty(t, snapshot.Requested())
+
+	name, err := snapshot.Name(settings, "db-1")
+	assert.NoError(t, err)
+	assert.Equal(t, name, snapshot.Requested())
+
+	// Note: a retried removal requests the snapshot with the same name, even when the template would render another
+	again, err := snapshot.Name(&libsettings.Setting{
+		CreateFinalSnapshotSetting: true,
+		FinalSnapshotNameSetting:   "other-{{ .Name }}",
+	}, "db-1")
+	assert.NoError(t, err)
+	assert.Equal(t, name, again)
+
+	var disabled FinalSnapshot
+	name, err = disabled.Name(nil, "db-1")
+	assert.NoError(t, err)
+	assert.Empty(t, name)
+	assert.NoError(t, disabled.Wait(""))
+
+	var invalid FinalSnapshot
+	_, err = invalid.Name(&libsettings.Setting{
+		CreateFinalSnapshotSetting: true,
+		FinalSnapshotNameSetting:   "{{ .Identifier }}",
+	}, "db-1")
+	assert.Error(t, err)
+	assert.Empty(t, invalid.Requested())
+}
+
+func TestFinalSnapshot_Wait(t *testing.T) {
+	snapshot := FinalSnapshot{}
+	_, err := snapshot.Name(&libsettings.Setting{CreateFinalSnapshotSetting: true}, "db-1")
+	assert.NoError(t, err)
+
+	cases := []struct {
+		status string
+		wait   bool
+		err    string
+	}{
+		{status: "available"},
+		{status: "Available"},
+		{status: "", wait: true},
+		{status: "creating", wait: true},
+		{status: "CreatingSnapshot", wait: true},
+		{status: "failed", err: "is failed"},
+		{status: "deleted", err: "is deleted"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.status, func(t *testing.T) {
+			err := snapshot.Wait(tc.status)
+
+			var waitErr liberrors.ErrWaitResource
+			assert.Equal(t, tc.wait, errors.As(err, &waitErr))
+
+			switch {
+			case tc.err != "":
+				assert.ErrorContains(t, err, tc.err)
+			case !tc.wait:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package nuke
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+// TagsByARN maps the ARN of a resource to its tags.
+type TagsByARN map[string]map[string]string
+
+// ListTags returns the tags of every tagged resource in the region of the client, as known to the Resource Groups
+// Tagging API. Resources without any tags are not part of the result.
+func ListTags(svc resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI) (TagsByARN, error) {
+	tags := TagsByARN{}
+
+	err := svc.GetResourcesPages(&resourcegroupstaggingapi.GetResourcesInput{},
+		func(page *resourcegroupstaggingapi.GetResourcesOutput, _ bool) bool {
+			for _, mapping := range page.ResourceTagMappingList {
+				if mapping.ResourceARN == nil || len(mapping.Tags) == 0 {
+					continue
+				}
+
+				resourceTags := make(map[string]string, len(mapping.Tags))
+				for _, tag := range mapping.Tags {
+					if tag.Key == nil {
+						continue
+					}
+
+					value := ""
+					if tag.Value != nil {
+						value = *tag.Value
+					}
+
+					resourceTags[*tag.Key] = value
+				}
+
+				tags[*mapping.ResourceARN] = resourceTags
+			}
+
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// Lookup returns the tags of the resource, matched by the ARN of the resource. The ARN is taken from the ARN property
+// of the resource, or from its legacy string when that is an ARN.
+func (t TagsByARN) Lookup(r resource.Resource) (map[string]string, bool) {
+	for _, arn := range ResourceARNs(r) {
+		if tags, ok := t[arn]; ok {
+			return tags, true
+		}
+	}
+
+	return nil, false
+}
+
+// ResourceARNs returns the candidate ARNs of the resource.
+func ResourceARNs(r resource.Resource) []string {
+	var arns []string
+
+	if getter, ok := r.(resource.PropertyGetter); ok {
+		props := getter.Properties()
+		for _, key := range []string{"ARN", "Arn", "arn"} {
+			if value := props.Get(key); strings.HasPrefix(value, "arn:") {
+				arns = append(arns, value)
+			}
+		}
+	}
+
+	if stringer, ok := r.(resource.LegacyStringer); ok {
+		if value := stringer.String(); strings.HasPrefix(value, "arn:") {
+			arns = append(arns, value)
+		}
+	}
+
+	return arns
+}
+
+// TaggedResource wraps a resource and adds the tags found by the Resource Groups Tagging API to its properties as
+// tag:<key> properties. Tags that the resource already exposes itself take precedence.
+type TaggedResource struct {
+	resource.Resource
+	Tags map[string]string
+}
+
+// Remove removes the wrapped resource.
+func (r *TaggedResource) Remove(ctx context.Context) error {
+	return r.Resource.Remove(ctx)
+}
+
+// Filter calls the filter of the wrapped resource, if it has one.
+func (r *TaggedResource) Filter() error {
+	if checker, ok := r.Resource.(resource.Filter); ok {
+		return checker.Filter()
+	}
+
+	return nil
+}
+
+// Properties returns the properties of the wrapped resource with the tags added.
+func (r *TaggedResource) Properties() types.Properties {
+	props := types.NewProperties()
+	if getter, ok := r.Resource.(resource.PropertyGetter); ok {
+		for key, value := range getter.Properties() {
+			props.Set(key, value)
+		}
+	}
+
+	for key, value := range r.Tags {
+		if _, ok := props[fmt.Sprintf("%s:%s", props.Get("_tagPrefix"), key)]; ok {
+			continue
+		}
+
+		props.SetTag(ptr.String(key), value)
+	}
+
+	return props
+}
+
+// String returns the legacy string of the wrapped resource.
+func (r *TaggedResource) String() string {
+	if stringer, ok := r.Resource.(resource.LegacyStringer); ok {
+		return stringer.String()
+	}
+
+	return ""
+}
@@ -0,0 +1,124 @@
+package nuke
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/aws-nuke/v3/mocks/mock_resourcegroupstaggingapiiface"
+)
+
+type testARNResource struct {
+	ARN  string
+	Name string
+}
+
+func (r *testARNResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testARNResource) Properties() types.Properties {
+	return types.NewProperties().Set("ARN", r.ARN).Set("Name", r.Name).Set("tag:owner", "lister")
+}
+
+type testLegacyResource struct {
+	arn string
+}
+
+func (r *testLegacyResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testLegacyResource) String() string {
+	return r.arn
+}
+
+func Test_Mock_ListTags(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mock_resourcegroupstaggingapiiface.NewMockResourceGroupsTaggingAPIAPI(ctrl)
+
+	mockSvc.EXPECT().GetResourcesPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ *resourcegroupstaggingapi.GetResourcesInput,
+			fn func(*resourcegroupstaggingapi.GetResourcesOutput, bool) bool) error {
+			fn(&resourcegroupstaggingapi.GetResourcesOutput{
+				ResourceTagMappingList: []*resourcegroupstaggingapi.ResourceTagMapping{
+					{
+						ResourceARN: ptr.String("arn:aws:sqs:us-east-1:123456789012:queue"),
+						Tags: []*resourcegroupstaggingapi.Tag{
+							{Key: ptr.String("owner"), Value: ptr.String("platform")},
+						},
+					},
+					{
+						ResourceARN: ptr.String("arn:aws:sns:us-east-1:123456789012:topic"),
+					},
+				},
+			}, false)
+			fn(&resourcegroupstaggingapi.GetResourcesOutput{
+				ResourceTagMappingList: []*resourcegroupstaggingapi.ResourceTagMapping{
+					{
+						ResourceARN: ptr.String("arn:aws:lambda:us-east-1:123456789012:function:fn"),
+						Tags: []*resourcegroupstaggingapi.Tag{
+							{Key: ptr.String("protected"), Value: ptr.String("")},
+						},
+					},
+				},
+			}, true)
+			return nil
+		})
+
+	tags, err := ListTags(mockSvc)
+	a.NoError(err)
+	a.Len(tags, 2)
+	a.Equal(map[string]string{"owner": "platform"}, tags["arn:aws:sqs:us-east-1:123456789012:queue"])
+	a.Equal(map[string]string{"protected": ""}, tags["arn:aws:lambda:us-east-1:123456789012:function:fn"])
+}
+
+func TestTagsByARN_Lookup(t *testing.T) {
+	tags := TagsByARN{
+		"arn:aws:sqs:us-east-1:123456789012:queue":          {"owner": "platform"},
+		"arn:aws:lambda:us-east-1:123456789012:function:fn": {"team": "data"},
+	}
+
+	found, ok := tags.Lookup(&testARNResource{ARN: "arn:aws:sqs:us-east-1:123456789012:queue"})
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"owner": "platform"}, found)
+
+	found, ok = tags.Lookup(&testLegacyResource{arn: "arn:aws:lambda:us-east-1:123456789012:function:fn"})
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"team": "data"}, found)
+
+	_, ok = tags.Lookup(&testLegacyResource{arn: "queue"})
+	assert.False(t, ok)
+}
+
+func TestTaggedResource_Properties(t *testing.T) {
+	r := &TaggedResource{
+		Resource: &testARNResource{ARN: "arn:aws:sqs:us-east-1:123456789012:queue", Name: "queue"},
+		Tags:     map[string]string{"owner": "platform", "team": "data"},
+	}
+
+	props := r.Properties()
+	assert.Equal(t, "queue", props.Get("Name"))
+	assert.Equal(t, "lister", props.Get("tag:owner"))
+	assert.Equal(t, "data", props.Get("tag:team"))
+	assert.Equal(t, "", r.String())
+
+	legacy := &TaggedResource{
+		Resource: &testLegacyResource{arn: "arn:aws:lambda:us-east-1:123456789012:function:fn"},
+		Tags:     map[string]string{"team": "data"},
+	}
+
+	assert.Equal(t, "data", legacy.Properties().Get("tag:team"))
+	assert.Equal(t, "arn:aws:lambda:us-east-1:123456789012:function:fn", legacy.String())
+	assert.NoError(t, legacy.Remove(context.TODO()))
+}
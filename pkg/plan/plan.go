@@ -0,0 +1,277 @@
+// Package plan provides a saved set of resources that a dry-run determined would be removed. A plan can be reviewed
+// and later applied, in which case only the resources in the plan are removed, and only if they still exist and still
+// match what was planned.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	liblog "github.com/ekristen/libnuke/pkg/log"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+)
+
+const (
+	// ReasonNotPlanned is the reason given for resources that are skipped when applying because they are not part of
+	// the plan.
+	ReasonNotPlanned = "not part of the plan"
+
+	// ReasonChanged is the reason given for resources that are skipped when applying because their properties changed
+	// since the plan was created.
+	ReasonChanged = "changed since the plan was created"
+)
+
+// Plan is the set of resources that would be removed from a single account.
+type Plan struct {
+	AccountID    string    `json:"accountId"`
+	AccountAlias string    `json:"accountAlias,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Items        []*Item   `json:"items"`
+}
+
+// Item is a single resource that would be removed.
+type Item struct {
+	Region       string            `json:"region"`
+	ResourceType string            `json:"resourceType"`
+	Identity     string            `json:"identity,omitempty"`
+	Properties   map[string]string `json:"properties,omitempty"`
+}
+
+// Key returns the key that identifies the resource, see Key.
+func (i *Item) Key() string {
+	return Key(i.Region, i.ResourceType, i.Identity, i.Properties)
+}
+
+// Key returns the key that identifies a resource across runs, resources that do not have an identity are identified by
+// their identity properties, see IdentityProperties. Plans and checkpoints identify resources by the same key.
+func Key(region, resourceType, identity string, properties map[string]string) string {
+	if identity == "" {
+		identity = liblog.Sorted(IdentityProperties(properties))
+	}
+
+	return fmt.Sprintf("%s|%s|%s", region, resourceType, identity)
+}
+
+// identityNames are the names of the properties that identify a resource on their own, in order of preference.
+var identityNames = []string{"arn", "id", "identifier", "uuid"}
+
+// IdentityProperties returns the properties that identify a resource that does not have an identity, so that it is
+// recognized across runs even when its tags or other properties change. The names are compared case-insensitively:
+//
+//   - the first property named ARN, ID, Identifier or UUID
+//   - otherwise the properties whose name ends with ARN, for example TaskARN and ClusterARN
+//   - otherwise the property named Name, if it is the only property whose name ends with Name
+//   - otherwise all properties except the tags, since a parent ID or name alone does not identify a resource
+func IdentityProperties(properties map[string]string) map[string]string {
+	for _, name := range identityNames {
+		for k, v := range properties {
+			if strings.EqualFold(k, name) {
+				return map[string]string{k: v}
+			}
+		}
+	}
+
+	arns := map[string]string{}
+	names := map[string]string{}
+	untagged := map[string]string{}
+	for k, v := range properties {
+		if strings.HasPrefix(k, "tag:") {
+			continue
+		}
+
+		untagged[k] = v
+
+		switch lower := strings.ToLower(k); {
+		case strings.HasSuffix(lower, "arn"):
+			arns[k] = v
+		case strings.HasSuffix(lower, "name"):
+			names[k] = v
+		}
+	}
+
+	if len(arns) > 0 {
+		return arns
+	}
+
+	if len(names) == 1 {
+		for k := range names {
+			if strings.EqualFold(k, "name") {
+				return names
+			}
+		}
+	}
+
+	return untagged
+}
+
+// Matches returns true if the other item has the same properties as the planned item.
+func (i *Item) Matches(o *Item) bool {
+	if len(i.Properties) != len(o.Properties) {
+		return false
+	}
+
+	for k, v := range i.Properties {
+		if ov, ok := o.Properties[k]; !ok || ov != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewItem converts a queue item into a plan item.
+func NewItem(item *queue.Item) *Item {
+	planItem := &Item{
+		Region:       item.Owner,
+		ResourceType: item.Type,
+	}
+
+	if stringer, ok := item.Resource.(resource.LegacyStringer); ok {
+		planItem.Identity = stringer.String()
+	}
+
+	if getter, ok := item.Resource.(resource.PropertyGetter); ok {
+		planItem.Properties = make(map[string]string)
+		for k, v := range getter.Properties() {
+			// Note: keys prefixed with an underscore are internal to libnuke and not actual properties
+			if strings.HasPrefix(k, "_") {
+				continue
+			}
+			planItem.Properties[k] = v
+		}
+	}
+
+	return planItem
+}
+
+// New creates a plan for the given account from the items in the queue that would be removed.
+func New(accountID, accountAlias string, q *queue.Queue) *Plan {
+	p := &Plan{
+		AccountID:    accountID,
+		AccountAlias: accountAlias,
+		CreatedAt:    time.Now().UTC(),
+		Items:        make([]*Item, 0),
+	}
+
+	for _, item := range q.GetItems() {
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		p.Items = append(p.Items, NewItem(item))
+	}
+
+	sort.SliceStable(p.Items, func(i, j int) bool {
+		return p.Items[i].Key() < p.Items[j].Key()
+	})
+
+	return p
+}
+
+// Load reads a plan from the file at the given path.
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("unable to parse plan %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// WriteFile writes the plan to a file at the given path.
+func (p *Plan) WriteFile(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+// ResourceTypes returns the distinct resource types of the planned items.
+func (p *Plan) ResourceTypes() []string {
+	var resourceTypes []string
+	seen := map[string]bool{}
+
+	for _, item := range p.Items {
+		if seen[item.ResourceType] {
+			continue
+		}
+
+		seen[item.ResourceType] = true
+		resourceTypes = append(resourceTypes, item.ResourceType)
+	}
+
+	sort.Strings(resourceTypes)
+
+	return resourceTypes
+}
+
+// Result is the outcome of verifying the plan against the items of a new scan.
+type Result struct {
+	// Matched is the number of planned resources that still exist unchanged and will be removed.
+	Matched int
+
+	// Changed are the planned resources whose properties changed since the plan was created.
+	Changed []*Item
+
+	// Missing are the planned resources that no longer exist or are no longer eligible for removal.
+	Missing []*Item
+}
+
+// Verify restricts the queue to the planned resources. Resources that are not part of the plan, or whose properties
+// changed since the plan was created, are marked as filtered so they are not removed.
+func (p *Plan) Verify(q *queue.Queue) *Result {
+	planned := make(map[string]*Item, len(p.Items))
+	for _, item := range p.Items {
+		planned[item.Key()] = item
+	}
+
+	result := &Result{}
+	found := map[string]bool{}
+
+	for _, item := range q.GetItems() {
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		current := NewItem(item)
+		key := current.Key()
+
+		plannedItem, ok := planned[key]
+		if !ok {
+			item.State = queue.ItemStateFiltered
+			item.Reason = ReasonNotPlanned
+			continue
+		}
+
+		found[key] = true
+
+		if !plannedItem.Matches(current) {
+			item.State = queue.ItemStateFiltered
+			item.Reason = ReasonChanged
+			result.Changed = append(result.Changed, plannedItem)
+			continue
+		}
+
+		result.Matched++
+	}
+
+	for _, item := range p.Items {
+		if !found[item.Key()] {
+			result.Missing = append(result.Missing, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,189 @@
+package plan
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+type TestResource struct {
+	id    string
+	owner string
+}
+
+func (r *TestResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *TestResource) String() string {
+	return r.id
+}
+
+func (r *TestResource) Properties() types.Properties {
+	return types.NewProperties().Set("Name", r.id).Set("Owner", r.owner)
+}
+
+func testItem(id, owner string, state queue.ItemState) *queue.Item {
+	return &queue.Item{
+		Resource: &TestResource{id: id, owner: owner},
+		State:    state,
+		Type:     "TestResource",
+		Owner:    "us-east-1",
+	}
+}
+
+func TestPlan_New(t *testing.T) {
+	q := queue.New()
+	q.Items = append(q.Items,
+		testItem("b", "alice", queue.ItemStateNew),
+		testItem("a", "alice", queue.ItemStateNewDependency),
+		testItem("filtered", "alice", queue.ItemStateFiltered),
+	)
+
+	p := New("123456789012", "sandbox", q)
+	assert.Len(t, p.Items, 2)
+	assert.Equal(t, "a", p.Items[0].Identity)
+	assert.Equal(t, "b", p.Items[1].Identity)
+	assert.Equal(t, map[string]string{"Name": "a", "Owner": "alice"}, p.Items[0].Properties)
+	assert.Equal(t, []string{"TestResource"}, p.ResourceTypes())
+}
+
+func TestPlan_Verify(t *testing.T) {
+	planned := queue.New()
+	planned.Items = append(planned.Items,
+		testItem("unchanged", "alice", queue.ItemStateNew),
+		testItem("changed", "alice", queue.ItemStateNew),
+		testItem("gone", "alice", queue.ItemStateNew),
+	)
+
+	p := New("123456789012", "sandbox", planned)
+
+	q := queue.New()
+	q.Items = append(q.Items,
+		testItem("unchanged", "alice", queue.ItemStateNew),
+		testItem("changed", "bob", queue.ItemStateNew),
+		testItem("new", "alice", queue.ItemStateNew),
+	)
+
+	result := p.Verify(q)
+	assert.Equal(t, 1, result.Matched)
+	assert.Len(t, result.Changed, 1)
+	assert.Equal(t, "changed", result.Changed[0].Identity)
+	assert.Len(t, result.Missing, 1)
+	assert.Equal(t, "gone", result.Missing[0].Identity)
+
+	assert.Equal(t, queue.ItemStateNew, q.Items[0].State)
+	assert.Equal(t, queue.ItemStateFiltered, q.Items[1].State)
+	assert.Equal(t, ReasonChanged, q.Items[1].Reason)
+	assert.Equal(t, queue.ItemStateFiltered, q.Items[2].State)
+	assert.Equal(t, ReasonNotPlanned, q.Items[2].Reason)
+}
+
+func TestPlan_WriteAndLoad(t *testing.T) {
+	q := queue.New()
+	q.Items = append(q.Items, testItem("a", "alice", queue.ItemStateNew))
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	assert.NoError(t, New("123456789012", "sandbox", q).WriteFile(path))
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012", loaded.AccountID)
+	assert.Equal(t, "sandbox", loaded.AccountAlias)
+	assert.Len(t, loaded.Items, 1)
+}
+
+// TestUnnamedResource is a resource without an identity, like the resources that do not implement String.
+type TestUnnamedResource struct {
+	arn string
+	tag string
+}
+
+func (r *TestUnnamedResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *TestUnnamedResource) Properties() types.Properties {
+	return types.NewProperties().Set("TaskARN", r.arn).SetTag(ptr.String("team"), ptr.String(r.tag))
+}
+
+func TestPlan_VerifyWithoutIdentity(t *testing.T) {
+	unnamedItem := func(arn, tag string) *queue.Item {
+		return &queue.Item{
+			Resource: &TestUnnamedResource{arn: arn, tag: tag},
+			State:    queue.ItemStateNew,
+			Type:     "TestUnnamedResource",
+			Owner:    "us-east-1",
+		}
+	}
+
+	planned := queue.New()
+	planned.Items = append(planned.Items, unnamedItem("arn:task/a", "alice"))
+
+	p := New("123456789012", "sandbox", planned)
+
+	q := queue.New()
+	q.Items = append(q.Items, unnamedItem("arn:task/a", "bob"))
+
+	result := p.Verify(q)
+	assert.Equal(t, 0, result.Matched)
+	assert.Len(t, result.Changed, 1)
+	assert.Len(t, result.Missing, 0)
+	assert.Equal(t, ReasonChanged, q.Items[0].Reason)
+}
+
+func TestIdentityProperties(t *testing.T) {
+	cases := []struct {
+		name       string
+		properties map[string]string
+		want       map[string]string
+	}{
+		{
+			name:       "arn",
+			properties: map[string]string{"Arn": "arn:hub", "tag:team": "platform"},
+			want:       map[string]string{"Arn": "arn:hub"},
+		},
+		{
+			name:       "id before name",
+			properties: map[string]string{"ApplicationID": "app", "ID": "env", "Name": "prod"},
+			want:       map[string]string{"ID": "env"},
+		},
+		{
+			name:       "uuid before arns",
+			properties: map[string]string{"UUID": "1234", "EventSourceArn": "arn:queue", "State": "Enabled"},
+			want:       map[string]string{"UUID": "1234"},
+		},
+		{
+			name:       "arns",
+			properties: map[string]string{"TaskARN": "arn:task", "ClusterARN": "arn:cluster", "tag:team": "platform"},
+			want:       map[string]string{"TaskARN": "arn:task", "ClusterARN": "arn:cluster"},
+		},
+		{
+			name:       "name",
+			properties: map[string]string{"name": "function", "stage": "LIVE"},
+			want:       map[string]string{"name": "function"},
+		},
+		{
+			name:       "names of parents",
+			properties: map[string]string{"MeshName": "mesh", "VirtualRouterName": "router", "Name": "route"},
+			want:       map[string]string{"MeshName": "mesh", "VirtualRouterName": "router", "Name": "route"},
+		},
+		{
+			name:       "id of parent",
+			properties: map[string]string{"IPSetID": "set", "Type": "IPV4", "Value": "10.0.0.0/8", "tag:a": "b"},
+			want:       map[string]string{"IPSetID": "set", "Type": "IPV4", "Value": "10.0.0.0/8"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, IdentityProperties(tc.properties))
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Package report provides a machine-readable representation of a nuke run. It captures every resource that was
+// discovered during the scan along with the filter decision and the final state, so that the outcome of a dry-run or
+// a real run can be consumed by other tooling without having to parse the log output.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+// Format is the output format of the report.
+type Format string
+
+const (
+	// FormatJSON writes the report as a single JSON document.
+	FormatJSON Format = "json"
+
+	// FormatNDJSON writes the report as newline delimited JSON, one record per line followed by the summary.
+	FormatNDJSON Format = "ndjson"
+)
+
+const (
+	// KindRecord identifies a resource record line in the NDJSON output.
+	KindRecord = "record"
+
+	// KindSummary identifies the summary line in the NDJSON output.
+	KindSummary = "summary"
+)
+
+// Report is the complete machine-readable representation of a nuke run against a single account.
+type Report struct {
+	AccountID    string    `json:"accountId"`
+	AccountAlias string    `json:"accountAlias,omitempty"`
+	DryRun       bool      `json:"dryRun"`
+	StartedAt    time.Time `json:"startedAt"`
+	FinishedAt   time.Time `json:"finishedAt"`
+	Records      []*Record `json:"records"`
+	Summary      *Summary  `json:"summary"`
+
+	// Estimator estimates the monthly cost of the resources when Build is called, it is nil when costs are not
+	// estimated.
+	Estimator Estimator `json:"-"`
+}
+
+// Estimator estimates the monthly cost of a resource by its properties, and returns false when it cannot.
+type Estimator interface {
+	Estimate(resourceType string, props types.Properties) (float64, bool)
+}
+
+// Record is a single resource that was discovered during the scan.
+type Record struct {
+	Region       string            `json:"region"`
+	ResourceType string            `json:"resourceType"`
+	Identity     string            `json:"identity,omitempty"`
+	Properties   map[string]string `json:"properties,omitempty"`
+	Filtered     bool              `json:"filtered"`
+	FilterReason string            `json:"filterReason,omitempty"`
+	State        string            `json:"state"`
+	Error        string            `json:"error,omitempty"`
+
+	// EstimatedMonthlyCost is the estimated monthly cost of the resource, when costs are estimated.
+	EstimatedMonthlyCost float64 `json:"estimatedMonthlyCost,omitempty"`
+}
+
+// Summary contains the aggregated counts of the run.
+type Summary struct {
+	Total    int    `json:"total"`
+	Nukeable int    `json:"nukeable"`
+	Filtered int    `json:"filtered"`
+	Removed  int    `json:"removed"`
+	Failed   int    `json:"failed"`
+	Waiting  int    `json:"waiting"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+
+	// EstimatedMonthlySavings is the estimated monthly cost of the resources that are not filtered, when costs are
+	// estimated.
+	EstimatedMonthlySavings float64 `json:"estimatedMonthlySavings,omitempty"`
+}
+
+// ndjsonRecord is a Record with the kind discriminator used by the NDJSON output.
+type ndjsonRecord struct {
+	Kind string `json:"kind"`
+	*Record
+}
+
+// ndjsonSummary is the final line of the NDJSON output, it carries the account details since there is no enclosing
+// document to hold them.
+type ndjsonSummary struct {
+	Kind         string    `json:"kind"`
+	AccountID    string    `json:"accountId"`
+	AccountAlias string    `json:"accountAlias,omitempty"`
+	DryRun       bool      `json:"dryRun"`
+	StartedAt    time.Time `json:"startedAt"`
+	FinishedAt   time.Time `json:"finishedAt"`
+	*Summary
+}
+
+// New creates a new report for the given account, the report is not populated until Build is called.
+func New(accountID, accountAlias string, dryRun bool) *Report {
+	return &Report{
+		AccountID:    accountID,
+		AccountAlias: accountAlias,
+		DryRun:       dryRun,
+		StartedAt:    time.Now().UTC(),
+		Records:      make([]*Record, 0),
+	}
+}
+
+// Build populates the report from the items in the queue. The error is the error that was returned by the run, if
+// any, and is recorded in the summary.
+func (r *Report) Build(q *queue.Queue, runErr error) {
+	r.FinishedAt = time.Now().UTC()
+	r.Records = make([]*Record, 0)
+	r.Summary = &Summary{
+		Duration: r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond).String(),
+	}
+
+	if runErr != nil {
+		r.Summary.Error = runErr.Error()
+	}
+
+	if q == nil {
+		return
+	}
+
+	for _, item := range q.GetItems() {
+		rec := NewRecord(item)
+		r.estimate(rec)
+		r.Records = append(r.Records, rec)
+	}
+
+	sort.SliceStable(r.Records, func(i, j int) bool {
+		if r.Records[i].Region != r.Records[j].Region {
+			return r.Records[i].Region < r.Records[j].Region
+		}
+		if r.Records[i].ResourceType != r.Records[j].ResourceType {
+			return r.Records[i].ResourceType < r.Records[j].ResourceType
+		}
+		return r.Records[i].Identity < r.Records[j].Identity
+	})
+
+	r.Summary.Total = q.Total()
+	r.Summary.Nukeable = q.Count(queue.ItemStateNew, queue.ItemStateNewDependency)
+	r.Summary.Filtered = q.Count(queue.ItemStateFiltered)
+	r.Summary.Removed = q.Count(queue.ItemStateFinished)
+	r.Summary.Failed = q.Count(queue.ItemStateFailed)
+	r.Summary.Waiting = q.Count(queue.ItemStateWaiting, queue.ItemStatePending, queue.ItemStatePendingDependency,
+		queue.ItemStateHold)
+}
+
+// estimate sets the estimated monthly cost of the record and adds it to the estimated savings, unless the resource is
+// filtered.
+func (r *Report) estimate(rec *Record) {
+	if r.Estimator == nil {
+		return
+	}
+
+	monthly, ok := r.Estimator.Estimate(rec.ResourceType, types.Properties(rec.Properties))
+	if !ok {
+		return
+	}
+
+	// Note: the costs are rounded to cents, the sum of floats would otherwise show rounding errors
+	rec.EstimatedMonthlyCost = math.Round(monthly*100) / 100
+	if !rec.Filtered {
+		r.Summary.EstimatedMonthlySavings = math.Round((r.Summary.EstimatedMonthlySavings+monthly)*100) / 100
+	}
+}
+
+// NewRecord converts a queue item into a report record.
+func NewRecord(item *queue.Item) *Record {
+	rec := &Record{
+		Region:       item.Owner,
+		ResourceType: item.Type,
+		State:        item.GetState().String(),
+	}
+
+	if stringer, ok := item.Resource.(resource.LegacyStringer); ok {
+		rec.Identity = stringer.String()
+	}
+
+	if getter, ok := item.Resource.(resource.PropertyGetter); ok {
+		rec.Properties = make(map[string]string)
+		for k, v := range getter.Properties() {
+			// Note: keys prefixed with an underscore are internal to libnuke and not actual properties
+			if strings.HasPrefix(k, "_") {
+				continue
+			}
+			rec.Properties[k] = v
+		}
+	}
+
+	switch item.GetState() {
+	case queue.ItemStateFiltered:
+		rec.Filtered = true
+		rec.FilterReason = item.GetReason()
+	case queue.ItemStateFailed:
+		rec.Error = item.GetReason()
+	}
+
+	return rec
+}
+
+// Write writes the report to the writer in the requested format.
+func (r *Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON, "":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, rec := range r.Records {
+			if err := enc.Encode(&ndjsonRecord{Kind: KindRecord, Record: rec}); err != nil {
+				return err
+			}
+		}
+
+		return enc.Encode(&ndjsonSummary{
+			Kind:         KindSummary,
+			AccountID:    r.AccountID,
+			AccountAlias: r.AccountAlias,
+			DryRun:       r.DryRun,
+			StartedAt:    r.StartedAt,
+			FinishedAt:   r.FinishedAt,
+			Summary:      r.Summary,
+		})
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// WriteFile writes the report to a file at the given path in the requested format.
+func (r *Report) WriteFile(path string, format Format) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+
+	// Note: the file is only complete once it is closed, a failed close means the report was not written
+	return f.Close()
+}
+
+// PathForAccount returns the path of the report for a single account when running against multiple accounts. The
+// account ID is inserted before the extension, for example report.json becomes report-123456789012.json.
+func PathForAccount(path, accountID string) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, ext), accountID, ext)
+}
@@ -0,0 +1,151 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+type TestResource struct {
+	id string
+}
+
+func (r *TestResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *TestResource) String() string {
+	return r.id
+}
+
+func (r *TestResource) Properties() types.Properties {
+	return types.NewProperties().Set("Name", r.id)
+}
+
+func testQueue() *queue.Queue {
+	q := queue.New()
+	q.Items = append(q.Items,
+		&queue.Item{
+			Resource: &TestResource{id: "b"},
+			State:    queue.ItemStateFiltered,
+			Reason:   "filtered by config",
+			Type:     "TestResource",
+			Owner:    "us-east-1",
+		},
+		&queue.Item{
+			Resource: &TestResource{id: "a"},
+			State:    queue.ItemStateFailed,
+			Reason:   "access denied",
+			Type:     "TestResource",
+			Owner:    "us-east-1",
+		},
+		&queue.Item{
+			Resource: &TestResource{id: "c"},
+			State:    queue.ItemStateNew,
+			Type:     "TestResource",
+			Owner:    "global",
+		},
+	)
+	return q
+}
+
+func TestReport_Build(t *testing.T) {
+	r := New("123456789012", "sandbox", true)
+	r.Build(testQueue(), fmt.Errorf("failed"))
+
+	assert.Len(t, r.Records, 3)
+	assert.Equal(t, "global", r.Records[0].Region)
+	assert.Equal(t, "a", r.Records[1].Identity)
+	assert.Equal(t, "b", r.Records[2].Identity)
+
+	assert.Equal(t, "failed", r.Records[1].State)
+	assert.Equal(t, "access denied", r.Records[1].Error)
+	assert.False(t, r.Records[1].Filtered)
+
+	assert.True(t, r.Records[2].Filtered)
+	assert.Equal(t, "filtered by config", r.Records[2].FilterReason)
+	assert.Equal(t, map[string]string{"Name": "b"}, r.Records[2].Properties)
+
+	assert.Equal(t, 3, r.Summary.Total)
+	assert.Equal(t, 1, r.Summary.Nukeable)
+	assert.Equal(t, 1, r.Summary.Filtered)
+	assert.Equal(t, 1, r.Summary.Failed)
+	assert.Equal(t, "failed", r.Summary.Error)
+}
+
+type testEstimator map[string]float64
+
+func (e testEstimator) Estimate(_ string, props types.Properties) (float64, bool) {
+	monthly, ok := e[props.Get("Name")]
+	return monthly, ok
+}
+
+func TestReport_BuildEstimates(t *testing.T) {
+	r := New("123456789012", "sandbox", true)
+	r.Estimator = testEstimator{"a": 10.1, "b": 99, "c": 20.2}
+	r.Build(testQueue(), nil)
+
+	assert.Equal(t, 20.2, r.Records[0].EstimatedMonthlyCost)
+	assert.Equal(t, 10.1, r.Records[1].EstimatedMonthlyCost)
+	assert.Equal(t, 99.0, r.Records[2].EstimatedMonthlyCost)
+	assert.Equal(t, 30.3, r.Summary.EstimatedMonthlySavings)
+
+	r.Estimator = testEstimator{}
+	r.Build(testQueue(), nil)
+
+	assert.Zero(t, r.Records[0].EstimatedMonthlyCost)
+	assert.Zero(t, r.Summary.EstimatedMonthlySavings)
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	r := New("123456789012", "sandbox", true)
+	r.Build(testQueue(), nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Write(&buf, FormatJSON))
+
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "123456789012", decoded.AccountID)
+	assert.Len(t, decoded.Records, 3)
+	assert.Equal(t, 3, decoded.Summary.Total)
+}
+
+func TestReport_WriteNDJSON(t *testing.T) {
+	r := New("123456789012", "sandbox", false)
+	r.Build(testQueue(), nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Write(&buf, FormatNDJSON))
+
+	var kinds []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		line := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		kinds = append(kinds, line["kind"].(string))
+	}
+
+	assert.Equal(t, []string{KindRecord, KindRecord, KindRecord, KindSummary}, kinds)
+}
+
+func TestReport_WriteInvalidFormat(t *testing.T) {
+	r := New("123456789012", "sandbox", true)
+	r.Build(nil, nil)
+
+	var buf bytes.Buffer
+	assert.Error(t, r.Write(&buf, Format("xml")))
+}
+
+func TestPathForAccount(t *testing.T) {
+	assert.Equal(t, "report-123456789012.json", PathForAccount("report.json", "123456789012"))
+	assert.Equal(t, "out/report-123456789012", PathForAccount("out/report", "123456789012"))
+}
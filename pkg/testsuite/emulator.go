@@ -0,0 +1,166 @@
+package testsuite
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/awsutil"
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+)
+
+const (
+	// EmulatorEndpointEnv is the environment variable that overrides the endpoint of the emulator.
+	EmulatorEndpointEnv = "AWS_NUKE_EMULATOR_ENDPOINT"
+
+	// DefaultEmulatorEndpoint is the endpoint that Moto server listens on by default.
+	DefaultEmulatorEndpoint = "http://localhost:5000"
+
+	// EmulatorRegion is the region that all requests are sent to the emulator with.
+	EmulatorRegion = "us-east-1"
+
+	// EmulatorAccountID is the account ID that the emulator uses for every resource.
+	EmulatorAccountID = "123456789012"
+)
+
+// Emulator points aws-nuke at a local AWS emulator, such as Moto server, through custom endpoints, so that resources
+// can be tested end-to-end without an AWS account. The emulator is expected to serve every service on one endpoint.
+type Emulator struct {
+	T           *testing.T
+	Endpoint    string
+	Credentials *awsutil.Credentials
+}
+
+// NewEmulator returns an emulator that routes the given services to the endpoint of the emulator, both in the
+// emulator region and the global region. The test is skipped when the emulator is not reachable.
+func NewEmulator(t *testing.T, services ...string) *Emulator {
+	t.Helper()
+
+	endpoint := os.Getenv(EmulatorEndpointEnv)
+	if endpoint == "" {
+		endpoint = DefaultEmulatorEndpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	require.NoError(t, err)
+
+	conn, err := net.DialTimeout("tcp", u.Host, time.Second)
+	if err != nil {
+		t.Skipf("emulator is not reachable at %s, set %s to use another endpoint: %v",
+			endpoint, EmulatorEndpointEnv, err)
+	}
+	_ = conn.Close()
+
+	customServices := make(config.CustomServices, 0, len(services))
+	for _, service := range services {
+		customServices = append(customServices, &config.CustomService{Service: service, URL: endpoint})
+	}
+
+	return &Emulator{
+		T:        t,
+		Endpoint: endpoint,
+		Credentials: &awsutil.Credentials{
+			AccessKeyID:     "testing",
+			SecretAccessKey: "testing",
+			CustomEndpoints: config.CustomEndpoints{
+				{Region: EmulatorRegion, Services: customServices},
+				{Region: awsutil.GlobalRegionID, Services: customServices},
+			},
+		},
+	}
+}
+
+// Session returns an SDK v1 session for the service, to seed fixtures with.
+func (e *Emulator) Session(service string) *session.Session {
+	e.T.Helper()
+
+	sess, err := e.Credentials.NewSession(EmulatorRegion, service)
+	require.NoError(e.T, err)
+
+	return sess
+}
+
+// Config returns an SDK v2 config for the service, to seed fixtures with.
+func (e *Emulator) Config(service string) *awsv2.Config {
+	e.T.Helper()
+
+	cfg, err := e.Credentials.NewConfig(context.TODO(), EmulatorRegion, service)
+	require.NoError(e.T, err)
+
+	return cfg
+}
+
+// ListerOpts returns the lister options for the resource type in the region, the same way a run creates them.
+func (e *Emulator) ListerOpts(regionName, resourceType string) *nuke.ListerOpts {
+	region := nuke.NewRegion(regionName, e.resourceTypeToServiceType, e.Credentials.NewSession, e.Credentials.NewConfig)
+
+	return nuke.MutateOpts(&nuke.ListerOpts{
+		Region:    region,
+		AccountID: ptr.String(EmulatorAccountID),
+		Logger:    logrus.WithField("region", regionName),
+	}, resourceType).(*nuke.ListerOpts)
+}
+
+// resourceTypeToServiceType resolves the service of the resource type from the custom endpoints, like the account
+// does for custom regions.
+func (e *Emulator) resourceTypeToServiceType(regionName, resourceType string) string {
+	account := &awsutil.Account{Credentials: e.Credentials}
+	return account.ResourceTypeToServiceType(regionName, resourceType)
+}
+
+// List lists the resources of the resource type in the region.
+func (e *Emulator) List(regionName, resourceType string) []resource.Resource {
+	e.T.Helper()
+
+	lister := registry.GetLister(resourceType)
+	require.NotNil(e.T, lister, "resource type %s is not registered", resourceType)
+
+	resources, err := lister.List(context.TODO(), e.ListerOpts(regionName, resourceType))
+	require.NoError(e.T, err)
+
+	return resources
+}
+
+// RunCycle runs the List, Filter and Remove cycle of a run for the resource type in the region. The fixture is the
+// resource that match returns true for, it must be listed, must not be filtered by the resource itself, and must no
+// longer be listed once it is removed.
+func (e *Emulator) RunCycle(regionName, resourceType string, match func(r resource.Resource) bool) {
+	e.T.Helper()
+
+	fixture := findResource(e.List(regionName, resourceType), match)
+	require.NotNil(e.T, fixture, "fixture of %s was not listed", resourceType)
+
+	if checker, ok := fixture.(resource.Filter); ok {
+		require.NoError(e.T, checker.Filter(), "fixture of %s was filtered", resourceType)
+	}
+
+	require.NoError(e.T, fixture.Remove(context.TODO()))
+
+	assert.Nil(e.T, findResource(e.List(regionName, resourceType), match),
+		"fixture of %s is still listed after it was removed", resourceType)
+}
+
+func findResource(resources []resource.Resource, match func(r resource.Resource) bool) resource.Resource {
+	for _, r := range resources {
+		if match(r) {
+			return r
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package tracing exports OpenTelemetry traces of a nuke run over OTLP, so that slow runs can be analyzed in a tracing
+// backend instead of by reading trace logs.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// name is the name of the instrumentation scope of the spans.
+const name = "github.com/ekristen/aws-nuke/v3"
+
+// Provider creates the tracer of a nuke run and exports its spans in batches.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// New returns a provider that exports spans to the OTLP/HTTP endpoint at the URL, for example http://localhost:4318
+// for a local collector. The version is recorded as the version of the service.
+func New(ctx context.Context, endpoint, version string) (*Provider, error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("aws-nuke"),
+		semconv.ServiceVersion(version),
+	)
+
+	return &Provider{
+		provider: sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+		),
+	}, nil
+}
+
+// Tracer returns the tracer that the spans of the run are started with.
+func (p *Provider) Tracer() trace.Tracer {
+	return p.provider.Tracer(name)
+}
+
+// Shutdown exports the spans that are not exported yet and stops the provider.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.provider.Shutdown(ctx)
+}
@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider_Export(t *testing.T) {
+	var exports atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exports.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p, err := New(context.TODO(), server.URL, "test")
+	assert.NoError(t, err)
+
+	_, span := p.Tracer().Start(context.TODO(), "nuke")
+	span.End()
+
+	assert.NoError(t, p.Shutdown(context.TODO()))
+	assert.Equal(t, int32(1), exports.Load())
+}
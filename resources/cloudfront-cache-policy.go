@@ -3,7 +3,8 @@ package resources
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -13,7 +14,7 @@ import (
 )
 
 type CloudFrontCachePolicy struct {
-	svc  *cloudfront.CloudFront
+	svc  *cloudfront.Client
 	ID   *string
 	Name *string
 }
@@ -31,21 +32,21 @@ func init() {
 
 type CloudFrontCachePolicyLister struct{}
 
-func (l *CloudFrontCachePolicyLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontCachePolicyLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
 	params := &cloudfront.ListCachePoliciesInput{}
 
 	for {
-		resp, err := svc.ListCachePolicies(params)
+		resp, err := svc.ListCachePolicies(ctx, params)
 		if err != nil {
 			return nil, err
 		}
 
 		for _, item := range resp.CachePolicyList.Items {
-			if *item.Type == "custom" { //nolint:goconst
+			if item.Type == rtypes.CachePolicyTypeCustom {
 				resources = append(resources, &CloudFrontCachePolicy{
 					svc:  svc,
 					ID:   item.CachePolicy.Id,
@@ -64,15 +65,15 @@ func (l *CloudFrontCachePolicyLister) List(_ context.Context, o interface{}) ([]
 	return resources, nil
 }
 
-func (f *CloudFrontCachePolicy) Remove(_ context.Context) error {
-	resp, err := f.svc.GetCachePolicy(&cloudfront.GetCachePolicyInput{
+func (f *CloudFrontCachePolicy) Remove(ctx context.Context) error {
+	resp, err := f.svc.GetCachePolicy(ctx, &cloudfront.GetCachePolicyInput{
 		Id: f.ID,
 	})
 	if err != nil {
 		return err
 	}
 
-	_, err = f.svc.DeleteCachePolicy(&cloudfront.DeleteCachePolicyInput{
+	_, err = f.svc.DeleteCachePolicy(ctx, &cloudfront.DeleteCachePolicyInput{
 		Id:      f.ID,
 		IfMatch: resp.ETag,
 	})
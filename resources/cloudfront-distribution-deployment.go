@@ -7,8 +7,9 @@ import (
 	"github.com/gotidy/ptr"
 	"github.com/sirupsen/logrus"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -30,19 +31,19 @@ func init() {
 
 type CloudFrontDistributionDeploymentLister struct{}
 
-func (l *CloudFrontDistributionDeploymentLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontDistributionDeploymentLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
-	var distributions []*cloudfront.DistributionSummary
+	var distributions []rtypes.DistributionSummary
 
 	params := &cloudfront.ListDistributionsInput{
-		MaxItems: aws.Int64(25),
+		MaxItems: aws.Int32(25),
 	}
 
 	for {
-		resp, err := svc.ListDistributions(params)
+		resp, err := svc.ListDistributions(ctx, params)
 		if err != nil {
 			return nil, err
 		}
@@ -57,7 +58,7 @@ func (l *CloudFrontDistributionDeploymentLister) List(_ context.Context, o inter
 	}
 
 	for _, distribution := range distributions {
-		resp, err := svc.GetDistribution(&cloudfront.GetDistributionInput{
+		resp, err := svc.GetDistribution(ctx, &cloudfront.GetDistributionInput{
 			Id: distribution.Id,
 		})
 		if err != nil {
@@ -78,17 +79,17 @@ func (l *CloudFrontDistributionDeploymentLister) List(_ context.Context, o inter
 }
 
 type CloudFrontDistributionDeployment struct {
-	svc                *cloudfront.CloudFront
+	svc                *cloudfront.Client
 	ID                 *string
 	Status             *string
 	eTag               *string
-	distributionConfig *cloudfront.DistributionConfig
+	distributionConfig *rtypes.DistributionConfig
 }
 
-func (r *CloudFrontDistributionDeployment) Remove(_ context.Context) error {
+func (r *CloudFrontDistributionDeployment) Remove(ctx context.Context) error {
 	r.distributionConfig.Enabled = aws.Bool(false)
 
-	_, err := r.svc.UpdateDistribution(&cloudfront.UpdateDistributionInput{
+	_, err := r.svc.UpdateDistribution(ctx, &cloudfront.UpdateDistributionInput{
 		Id:                 r.ID,
 		DistributionConfig: r.distributionConfig,
 		IfMatch:            r.eTag,
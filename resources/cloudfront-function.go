@@ -3,7 +3,8 @@ package resources
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -25,15 +26,15 @@ func init() {
 
 type CloudFrontFunctionLister struct{}
 
-func (l *CloudFrontFunctionLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontFunctionLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
 	params := &cloudfront.ListFunctionsInput{}
 
 	for {
-		resp, err := svc.ListFunctions(params)
+		resp, err := svc.ListFunctions(ctx, params)
 		if err != nil {
 			return nil, err
 		}
@@ -57,13 +58,13 @@ func (l *CloudFrontFunctionLister) List(_ context.Context, o interface{}) ([]res
 }
 
 type CloudFrontFunction struct {
-	svc   *cloudfront.CloudFront
+	svc   *cloudfront.Client
 	name  *string
-	stage *string
+	stage rtypes.FunctionStage
 }
 
-func (f *CloudFrontFunction) Remove(_ context.Context) error {
-	resp, err := f.svc.GetFunction(&cloudfront.GetFunctionInput{
+func (f *CloudFrontFunction) Remove(ctx context.Context) error {
+	resp, err := f.svc.GetFunction(ctx, &cloudfront.GetFunctionInput{
 		Name:  f.name,
 		Stage: f.stage,
 	})
@@ -71,7 +72,7 @@ func (f *CloudFrontFunction) Remove(_ context.Context) error {
 		return err
 	}
 
-	_, err = f.svc.DeleteFunction(&cloudfront.DeleteFunctionInput{
+	_, err = f.svc.DeleteFunction(ctx, &cloudfront.DeleteFunctionInput{
 		Name:    f.name,
 		IfMatch: resp.ETag,
 	})
@@ -82,6 +83,6 @@ func (f *CloudFrontFunction) Remove(_ context.Context) error {
 func (f *CloudFrontFunction) Properties() types.Properties {
 	properties := types.NewProperties()
 	properties.Set("name", f.name)
-	properties.Set("stage", f.stage)
+	properties.Set("stage", string(f.stage))
 	return properties
 }
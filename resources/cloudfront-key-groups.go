@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -26,15 +26,15 @@ func init() {
 
 type CloudFrontKeyGroupLister struct{}
 
-func (l *CloudFrontKeyGroupLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontKeyGroupLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
 	params := &cloudfront.ListKeyGroupsInput{}
 
 	for {
-		resp, err := svc.ListKeyGroups(params)
+		resp, err := svc.ListKeyGroups(ctx, params)
 		if err != nil {
 			return nil, err
 		}
@@ -59,21 +59,21 @@ func (l *CloudFrontKeyGroupLister) List(_ context.Context, o interface{}) ([]res
 }
 
 type CloudFrontKeyGroup struct {
-	svc              *cloudfront.CloudFront
+	svc              *cloudfront.Client
 	ID               *string
 	name             *string
 	lastModifiedTime *time.Time
 }
 
-func (f *CloudFrontKeyGroup) Remove(_ context.Context) error {
-	resp, err := f.svc.GetKeyGroup(&cloudfront.GetKeyGroupInput{
+func (f *CloudFrontKeyGroup) Remove(ctx context.Context) error {
+	resp, err := f.svc.GetKeyGroup(ctx, &cloudfront.GetKeyGroupInput{
 		Id: f.ID,
 	})
 	if err != nil {
 		return err
 	}
 
-	_, err = f.svc.DeleteKeyGroup(&cloudfront.DeleteKeyGroupInput{
+	_, err = f.svc.DeleteKeyGroup(ctx, &cloudfront.DeleteKeyGroupInput{
 		Id:      f.ID,
 		IfMatch: resp.ETag,
 	})
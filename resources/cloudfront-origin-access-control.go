@@ -3,7 +3,7 @@ package resources
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -25,15 +25,15 @@ func init() {
 
 type CloudFrontOriginAccessControlLister struct{}
 
-func (l *CloudFrontOriginAccessControlLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontOriginAccessControlLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
 	params := &cloudfront.ListOriginAccessControlsInput{}
 
 	for {
-		resp, err := svc.ListOriginAccessControls(params)
+		resp, err := svc.ListOriginAccessControls(ctx, params)
 		if err != nil {
 			return nil, err
 		}
@@ -56,19 +56,19 @@ func (l *CloudFrontOriginAccessControlLister) List(_ context.Context, o interfac
 }
 
 type CloudFrontOriginAccessControl struct {
-	svc *cloudfront.CloudFront
+	svc *cloudfront.Client
 	ID  *string
 }
 
-func (f *CloudFrontOriginAccessControl) Remove(_ context.Context) error {
-	resp, err := f.svc.GetOriginAccessControl(&cloudfront.GetOriginAccessControlInput{
+func (f *CloudFrontOriginAccessControl) Remove(ctx context.Context) error {
+	resp, err := f.svc.GetOriginAccessControl(ctx, &cloudfront.GetOriginAccessControlInput{
 		Id: f.ID,
 	})
 	if err != nil {
 		return err
 	}
 
-	_, err = f.svc.DeleteOriginAccessControl(&cloudfront.DeleteOriginAccessControlInput{
+	_, err = f.svc.DeleteOriginAccessControl(ctx, &cloudfront.DeleteOriginAccessControlInput{
 		Id:      f.ID,
 		IfMatch: resp.ETag,
 	})
@@ -3,7 +3,7 @@ package resources
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -25,13 +25,13 @@ func init() {
 
 type CloudFrontOriginAccessIdentityLister struct{}
 
-func (l *CloudFrontOriginAccessIdentityLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontOriginAccessIdentityLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
 
-	resp, err := svc.ListCloudFrontOriginAccessIdentities(nil)
+	resp, err := svc.ListCloudFrontOriginAccessIdentities(ctx, &cloudfront.ListCloudFrontOriginAccessIdentitiesInput{})
 	if err != nil {
 		return nil, err
 	}
@@ -46,19 +46,19 @@ func (l *CloudFrontOriginAccessIdentityLister) List(_ context.Context, o interfa
 }
 
 type CloudFrontOriginAccessIdentity struct {
-	svc *cloudfront.CloudFront
+	svc *cloudfront.Client
 	ID  *string
 }
 
-func (f *CloudFrontOriginAccessIdentity) Remove(_ context.Context) error {
-	resp, err := f.svc.GetCloudFrontOriginAccessIdentity(&cloudfront.GetCloudFrontOriginAccessIdentityInput{
+func (f *CloudFrontOriginAccessIdentity) Remove(ctx context.Context) error {
+	resp, err := f.svc.GetCloudFrontOriginAccessIdentity(ctx, &cloudfront.GetCloudFrontOriginAccessIdentityInput{
 		Id: f.ID,
 	})
 	if err != nil {
 		return err
 	}
 
-	_, err = f.svc.DeleteCloudFrontOriginAccessIdentity(&cloudfront.DeleteCloudFrontOriginAccessIdentityInput{
+	_, err = f.svc.DeleteCloudFrontOriginAccessIdentity(ctx, &cloudfront.DeleteCloudFrontOriginAccessIdentityInput{
 		Id:      f.ID,
 		IfMatch: resp.ETag,
 	})
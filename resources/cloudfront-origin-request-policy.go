@@ -3,7 +3,8 @@ package resources
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -25,21 +26,21 @@ func init() {
 
 type CloudFrontOriginRequestPolicyLister struct{}
 
-func (l *CloudFrontOriginRequestPolicyLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontOriginRequestPolicyLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
 	params := &cloudfront.ListOriginRequestPoliciesInput{}
 
 	for {
-		resp, err := svc.ListOriginRequestPolicies(params)
+		resp, err := svc.ListOriginRequestPolicies(ctx, params)
 		if err != nil {
 			return nil, err
 		}
 
 		for _, item := range resp.OriginRequestPolicyList.Items {
-			if *item.Type == "custom" {
+			if item.Type == rtypes.OriginRequestPolicyTypeCustom {
 				resources = append(resources, &CloudFrontOriginRequestPolicy{
 					svc: svc,
 					ID:  item.OriginRequestPolicy.Id,
@@ -58,19 +59,19 @@ func (l *CloudFrontOriginRequestPolicyLister) List(_ context.Context, o interfac
 }
 
 type CloudFrontOriginRequestPolicy struct {
-	svc *cloudfront.CloudFront
+	svc *cloudfront.Client
 	ID  *string
 }
 
-func (f *CloudFrontOriginRequestPolicy) Remove(_ context.Context) error {
-	resp, err := f.svc.GetOriginRequestPolicy(&cloudfront.GetOriginRequestPolicyInput{
+func (f *CloudFrontOriginRequestPolicy) Remove(ctx context.Context) error {
+	resp, err := f.svc.GetOriginRequestPolicy(ctx, &cloudfront.GetOriginRequestPolicyInput{
 		Id: f.ID,
 	})
 	if err != nil {
 		return err
 	}
 
-	_, err = f.svc.DeleteOriginRequestPolicy(&cloudfront.DeleteOriginRequestPolicyInput{
+	_, err = f.svc.DeleteOriginRequestPolicy(ctx, &cloudfront.DeleteOriginRequestPolicyInput{
 		Id:      f.ID,
 		IfMatch: resp.ETag,
 	})
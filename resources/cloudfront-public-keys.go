@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -26,15 +26,15 @@ func init() {
 
 type CloudFrontPublicKeyLister struct{}
 
-func (l *CloudFrontPublicKeyLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontPublicKeyLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
 	params := &cloudfront.ListPublicKeysInput{}
 
 	for {
-		resp, err := svc.ListPublicKeys(params)
+		resp, err := svc.ListPublicKeys(ctx, params)
 		if err != nil {
 			return nil, err
 		}
@@ -59,21 +59,21 @@ func (l *CloudFrontPublicKeyLister) List(_ context.Context, o interface{}) ([]re
 }
 
 type CloudFrontPublicKey struct {
-	svc         *cloudfront.CloudFront
+	svc         *cloudfront.Client
 	ID          *string
 	name        *string
 	createdTime *time.Time
 }
 
-func (f *CloudFrontPublicKey) Remove(_ context.Context) error {
-	resp, err := f.svc.GetPublicKey(&cloudfront.GetPublicKeyInput{
+func (f *CloudFrontPublicKey) Remove(ctx context.Context) error {
+	resp, err := f.svc.GetPublicKey(ctx, &cloudfront.GetPublicKeyInput{
 		Id: f.ID,
 	})
 	if err != nil {
 		return err
 	}
 
-	_, err = f.svc.DeletePublicKey(&cloudfront.DeletePublicKeyInput{
+	_, err = f.svc.DeletePublicKey(ctx, &cloudfront.DeletePublicKeyInput{
 		Id:      f.ID,
 		IfMatch: resp.ETag,
 	})
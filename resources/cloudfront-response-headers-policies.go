@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -27,15 +27,15 @@ func init() {
 
 type CloudFrontResponseHeadersPolicyLister struct{}
 
-func (l *CloudFrontResponseHeadersPolicyLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *CloudFrontResponseHeadersPolicyLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 
-	svc := cloudfront.New(opts.Session)
+	svc := cloudfront.NewFromConfig(*opts.Config)
 	resources := make([]resource.Resource, 0)
 	params := &cloudfront.ListResponseHeadersPoliciesInput{}
 
 	for {
-		resp, err := svc.ListResponseHeadersPolicies(params)
+		resp, err := svc.ListResponseHeadersPolicies(ctx, params)
 		if err != nil {
 			return nil, err
 		}
@@ -59,7 +59,7 @@ func (l *CloudFrontResponseHeadersPolicyLister) List(_ context.Context, o interf
 }
 
 type CloudFrontResponseHeadersPolicy struct {
-	svc  *cloudfront.CloudFront
+	svc  *cloudfront.Client
 	ID   *string
 	name *string
 }
@@ -71,15 +71,15 @@ func (f *CloudFrontResponseHeadersPolicy) Filter() error {
 	return nil
 }
 
-func (f *CloudFrontResponseHeadersPolicy) Remove(_ context.Context) error {
-	resp, err := f.svc.GetResponseHeadersPolicy(&cloudfront.GetResponseHeadersPolicyInput{
+func (f *CloudFrontResponseHeadersPolicy) Remove(ctx context.Context) error {
+	resp, err := f.svc.GetResponseHeadersPolicy(ctx, &cloudfront.GetResponseHeadersPolicyInput{
 		Id: f.ID,
 	})
 	if err != nil {
 		return err
 	}
 
-	_, err = f.svc.DeleteResponseHeadersPolicy(&cloudfront.DeleteResponseHeadersPolicyInput{
+	_, err = f.svc.DeleteResponseHeadersPolicy(ctx, &cloudfront.DeleteResponseHeadersPolicyInput{
 		Id:      f.ID,
 		IfMatch: resp.ETag,
 	})
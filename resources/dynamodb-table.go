@@ -2,13 +2,17 @@ package resources
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/gotidy/ptr"
 	"github.com/sirupsen/logrus"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
+	liberrors "github.com/ekristen/libnuke/pkg/errors"
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
 	"github.com/ekristen/libnuke/pkg/settings"
@@ -27,6 +31,8 @@ func init() {
 		Lister:   &DynamoDBTableLister{},
 		Settings: []string{
 			"DisableDeletionProtection",
+			nuke.ArchiveBucketSetting,
+			nuke.ArchivePrefixSetting,
 		},
 		DependsOn: []string{
 			DynamoDBTableItemResource,
@@ -74,6 +80,7 @@ func (l *DynamoDBTableLister) List(_ context.Context, o interface{}) ([]resource
 		resources = append(resources, &DynamoDBTable{
 			svc:        svc,
 			id:         tableName,
+			arn:        table.Table.TableArn,
 			protection: table.Table.DeletionProtectionEnabled,
 			Name:       tableName,
 			Tags:       tags.Tags,
@@ -87,12 +94,18 @@ type DynamoDBTable struct {
 	svc        dynamodbiface.DynamoDBAPI
 	settings   *settings.Setting
 	id         *string `property:"Identifier"` // TODO(v4): remove this
+	arn        *string
+	exportArn  *string
 	protection *bool
 	Name       *string
 	Tags       []*dynamodb.Tag
 }
 
 func (r *DynamoDBTable) Remove(_ context.Context) error {
+	if err := r.Archive(); err != nil {
+		return err
+	}
+
 	if err := r.DisableDeletionProtection(); err != nil {
 		return err
 	}
@@ -109,6 +122,77 @@ func (r *DynamoDBTable) Remove(_ context.Context) error {
 	return nil
 }
 
+// Archive exports the table to the archive bucket, when the ArchiveBucket setting is set. The export needs point in
+// time recovery, which is enabled if necessary. The table is held until the export completes.
+func (r *DynamoDBTable) Archive() error {
+	bucket, prefix := nuke.ArchiveLocation(r.settings, ptr.ToString(r.Name))
+	if bucket == "" {
+		return nil
+	}
+
+	if r.exportArn == nil {
+		return r.startExport(bucket, prefix)
+	}
+
+	resp, err := r.svc.DescribeExport(&dynamodb.DescribeExportInput{
+		ExportArn: r.exportArn,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch ptr.ToString(resp.ExportDescription.ExportStatus) {
+	case dynamodb.ExportStatusCompleted:
+		return nil
+	case dynamodb.ExportStatusFailed:
+		r.exportArn = nil
+		return fmt.Errorf("unable to archive to %s: %s", bucket, ptr.ToString(resp.ExportDescription.FailureMessage))
+	default:
+		return liberrors.ErrHoldResource("waiting for export to complete")
+	}
+}
+
+func (r *DynamoDBTable) startExport(bucket, prefix string) error {
+	backups, err := r.svc.DescribeContinuousBackups(&dynamodb.DescribeContinuousBackupsInput{
+		TableName: r.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	recovery := backups.ContinuousBackupsDescription.PointInTimeRecoveryDescription
+	if recovery == nil || ptr.ToString(recovery.PointInTimeRecoveryStatus) != dynamodb.PointInTimeRecoveryStatusEnabled {
+		_, err := r.svc.UpdateContinuousBackups(&dynamodb.UpdateContinuousBackupsInput{
+			TableName: r.Name,
+			PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+				PointInTimeRecoveryEnabled: ptr.Bool(true),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := r.svc.ExportTableToPointInTime(&dynamodb.ExportTableToPointInTimeInput{
+		TableArn:     r.arn,
+		S3Bucket:     ptr.String(bucket),
+		S3Prefix:     ptr.String(prefix),
+		ExportFormat: ptr.String(dynamodb.ExportFormatDynamodbJson),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodePointInTimeRecoveryUnavailableException {
+			return liberrors.ErrHoldResource("waiting for point in time recovery")
+		}
+
+		return err
+	}
+
+	r.exportArn = resp.ExportDescription.ExportArn
+
+	return liberrors.ErrHoldResource("waiting for export to complete")
+}
+
 func (r *DynamoDBTable) DisableDeletionProtection() error {
 	if !r.settings.GetBool("DisableDeletionProtection") {
 		return nil
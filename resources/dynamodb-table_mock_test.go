@@ -163,3 +163,82 @@ func Test_Mock_DynamoDBTable_Remove_DeletionProtection_Disable(t *testing.T) {
 	err := resource.Remove(context.TODO())
 	a.Error(err)
 }
+
+func Test_Mock_DynamoDBTable_Remove_Archive(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mock_dynamodbiface.NewMockDynamoDBAPI(ctrl)
+
+	mockSvc.EXPECT().DescribeContinuousBackups(&dynamodb.DescribeContinuousBackupsInput{
+		TableName: ptr.String("ExampleTable"),
+	}).Return(&dynamodb.DescribeContinuousBackupsOutput{
+		ContinuousBackupsDescription: &dynamodb.ContinuousBackupsDescription{
+			PointInTimeRecoveryDescription: &dynamodb.PointInTimeRecoveryDescription{
+				PointInTimeRecoveryStatus: ptr.String(dynamodb.PointInTimeRecoveryStatusDisabled),
+			},
+		},
+	}, nil)
+
+	mockSvc.EXPECT().UpdateContinuousBackups(&dynamodb.UpdateContinuousBackupsInput{
+		TableName: ptr.String("ExampleTable"),
+		PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: ptr.Bool(true),
+		},
+	}).Return(&dynamodb.UpdateContinuousBackupsOutput{}, nil)
+
+	mockSvc.EXPECT().ExportTableToPointInTime(&dynamodb.ExportTableToPointInTimeInput{
+		TableArn:     ptr.String("arn:aws:dynamodb:us-west-2:123456789012:table/ExampleTable"),
+		S3Bucket:     ptr.String("archive-bucket"),
+		S3Prefix:     ptr.String("sandbox/ExampleTable/"),
+		ExportFormat: ptr.String(dynamodb.ExportFormatDynamodbJson),
+	}).Return(&dynamodb.ExportTableToPointInTimeOutput{
+		ExportDescription: &dynamodb.ExportDescription{
+			ExportArn: ptr.String("arn:aws:dynamodb:us-west-2:123456789012:table/ExampleTable/export/01"),
+		},
+	}, nil)
+
+	gomock.InOrder(
+		mockSvc.EXPECT().DescribeExport(&dynamodb.DescribeExportInput{
+			ExportArn: ptr.String("arn:aws:dynamodb:us-west-2:123456789012:table/ExampleTable/export/01"),
+		}).Return(&dynamodb.DescribeExportOutput{
+			ExportDescription: &dynamodb.ExportDescription{
+				ExportStatus: ptr.String(dynamodb.ExportStatusInProgress),
+			},
+		}, nil),
+		mockSvc.EXPECT().DescribeExport(&dynamodb.DescribeExportInput{
+			ExportArn: ptr.String("arn:aws:dynamodb:us-west-2:123456789012:table/ExampleTable/export/01"),
+		}).Return(&dynamodb.DescribeExportOutput{
+			ExportDescription: &dynamodb.ExportDescription{
+				ExportStatus: ptr.String(dynamodb.ExportStatusCompleted),
+			},
+		}, nil),
+	)
+
+	mockSvc.EXPECT().DeleteTable(&dynamodb.DeleteTableInput{
+		TableName: ptr.String("ExampleTable"),
+	}).Return(&dynamodb.DeleteTableOutput{}, nil)
+
+	settings := &libsettings.Setting{}
+	settings.Set("ArchiveBucket", "archive-bucket")
+	settings.Set("ArchivePrefix", "sandbox")
+
+	resource := &DynamoDBTable{
+		svc:        mockSvc,
+		settings:   settings,
+		id:         ptr.String("ExampleTable"),
+		arn:        ptr.String("arn:aws:dynamodb:us-west-2:123456789012:table/ExampleTable"),
+		protection: ptr.Bool(false),
+		Name:       ptr.String("ExampleTable"),
+	}
+
+	err := resource.Remove(context.TODO())
+	a.ErrorContains(err, "waiting for export to complete")
+
+	err = resource.Remove(context.TODO())
+	a.ErrorContains(err, "waiting for export to complete")
+
+	err = resource.Remove(context.TODO())
+	a.NoError(err)
+}
@@ -63,6 +63,8 @@ func (e *EC2Volume) Properties() types.Properties {
 	properties := types.NewProperties()
 	properties.Set("State", e.volume.State)
 	properties.Set("CreateTime", e.volume.CreateTime.Format(time.RFC3339))
+	properties.Set("VolumeType", e.volume.VolumeType)
+	properties.Set("Size", e.volume.Size)
 	for _, tagValue := range e.volume.Tags {
 		properties.SetTag(tagValue.Key, tagValue.Value)
 	}
@@ -2,15 +2,18 @@ package resources
 
 import (
 	"context"
+	"errors"
 
 	"github.com/gotidy/ptr"
 	"github.com/sirupsen/logrus"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/elasticache"
 	"github.com/aws/aws-sdk-go/service/elasticache/elasticacheiface"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
@@ -29,6 +32,10 @@ func init() {
 			ElasticacheReplicationGroupResource,
 			ElasticacheSubnetGroupResource,
 		},
+		Settings: []string{
+			nuke.CreateFinalSnapshotSetting,
+			nuke.FinalSnapshotNameSetting,
+		},
 	})
 }
 
@@ -60,10 +67,12 @@ func (l *ElasticacheCacheClusterLister) List(_ context.Context, o interface{}) (
 		}
 
 		resources = append(resources, &ElasticacheCacheCluster{
-			svc:       svc,
-			ClusterID: cacheCluster.CacheClusterId,
-			Status:    cacheCluster.CacheClusterStatus,
-			Tags:      tags,
+			svc:                svc,
+			engine:             cacheCluster.Engine,
+			replicationGroupID: cacheCluster.ReplicationGroupId,
+			ClusterID:          cacheCluster.CacheClusterId,
+			Status:             cacheCluster.CacheClusterStatus,
+			Tags:               tags,
 		})
 	}
 
@@ -86,6 +95,7 @@ func (l *ElasticacheCacheClusterLister) List(_ context.Context, o interface{}) (
 
 		resources = append(resources, &ElasticacheCacheCluster{
 			svc:        svc,
+			engine:     serverlessCache.Engine,
 			Serverless: true,
 			ClusterID:  serverlessCache.ServerlessCacheName,
 			Status:     serverlessCache.Status,
@@ -108,29 +118,120 @@ func (l *ElasticacheCacheClusterLister) getResourceTags(svc elasticacheiface.Ela
 }
 
 type ElasticacheCacheCluster struct {
-	svc        elasticacheiface.ElastiCacheAPI
-	ClusterID  *string
-	Status     *string
-	Serverless bool
-	Tags       []*elasticache.Tag
+	svc                elasticacheiface.ElastiCacheAPI
+	settings           *libsettings.Setting
+	finalSnapshot      nuke.FinalSnapshot
+	engine             *string
+	replicationGroupID *string
+	ClusterID          *string
+	Status             *string
+	Serverless         bool
+	Tags               []*elasticache.Tag
+}
+
+func (r *ElasticacheCacheCluster) Settings(settings *libsettings.Setting) {
+	r.settings = settings
 }
 
 func (r *ElasticacheCacheCluster) Remove(_ context.Context) error {
+	snapshotName, err := r.finalSnapshotName()
+	if err != nil {
+		return err
+	}
+
 	if r.Serverless {
-		_, err := r.svc.DeleteServerlessCache(&elasticache.DeleteServerlessCacheInput{
+		params := &elasticache.DeleteServerlessCacheInput{
 			ServerlessCacheName: r.ClusterID,
-		})
+		}
+		if snapshotName != "" {
+			params.FinalSnapshotName = ptr.String(snapshotName)
+		}
+
+		_, err := r.svc.DeleteServerlessCache(params)
 
 		return err
 	}
 
-	_, err := r.svc.DeleteCacheCluster(&elasticache.DeleteCacheClusterInput{
+	params := &elasticache.DeleteCacheClusterInput{
 		CacheClusterId: r.ClusterID,
-	})
+	}
+	if snapshotName != "" {
+		params.FinalSnapshotIdentifier = ptr.String(snapshotName)
+	}
+
+	_, err = r.svc.DeleteCacheCluster(params)
 
 	return err
 }
 
+// finalSnapshotName returns the name of the final snapshot, memcached has no snapshots and the final snapshot of the
+// clusters of a replication group is taken of the replication group.
+func (r *ElasticacheCacheCluster) finalSnapshotName() (string, error) {
+	if ptr.ToString(r.engine) == "memcached" || r.replicationGroupID != nil {
+		return "", nil
+	}
+
+	return r.finalSnapshot.Name(r.settings, ptr.ToString(r.ClusterID))
+}
+
+// HandleWait waits for the final snapshot, if one was requested, to become available.
+func (r *ElasticacheCacheCluster) HandleWait(_ context.Context) error {
+	snapshotName := r.finalSnapshot.Requested()
+	if snapshotName == "" {
+		return nil
+	}
+
+	if !r.Serverless {
+		status, err := elasticacheSnapshotStatus(r.svc, snapshotName)
+		if err != nil {
+			return err
+		}
+
+		return r.finalSnapshot.Wait(status)
+	}
+
+	resp, err := r.svc.DescribeServerlessCacheSnapshots(&elasticache.DescribeServerlessCacheSnapshotsInput{
+		ServerlessCacheSnapshotName: ptr.String(snapshotName),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == elasticache.ErrCodeServerlessCacheSnapshotNotFoundFault {
+			return r.finalSnapshot.Wait("")
+		}
+
+		return err
+	}
+
+	var status string
+	if len(resp.ServerlessCacheSnapshots) > 0 {
+		status = ptr.ToString(resp.ServerlessCacheSnapshots[0].Status)
+	}
+
+	return r.finalSnapshot.Wait(status)
+}
+
+// elasticacheSnapshotStatus returns the status of the snapshot with the name, or an empty status when it does not
+// exist yet.
+func elasticacheSnapshotStatus(svc elasticacheiface.ElastiCacheAPI, name string) (string, error) {
+	resp, err := svc.DescribeSnapshots(&elasticache.DescribeSnapshotsInput{
+		SnapshotName: ptr.String(name),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == elasticache.ErrCodeSnapshotNotFoundFault {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	if len(resp.Snapshots) == 0 {
+		return "", nil
+	}
+
+	return ptr.ToString(resp.Snapshots[0].SnapshotStatus), nil
+}
+
 func (r *ElasticacheCacheCluster) Properties() types.Properties {
 	return types.NewPropertiesFromStruct(r)
 }
@@ -2,6 +2,7 @@ package resources
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -13,6 +14,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/elasticache"
 
+	liberrors "github.com/ekristen/libnuke/pkg/errors"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
+
 	"github.com/ekristen/aws-nuke/v3/mocks/mock_elasticacheiface"
 	"github.com/ekristen/aws-nuke/v3/pkg/testsuite"
 )
@@ -220,3 +224,125 @@ func Test_Mock_ElastiCache_CacheCluster_List_TagsInvalidARN(t *testing.T) {
 
 	a.True(called, "expected global hook called and log message to be found")
 }
+
+func Test_Mock_ElastiCache_CacheCluster_Remove_FinalSnapshot(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElastiCache := mock_elasticacheiface.NewMockElastiCacheAPI(ctrl)
+
+	settings := &libsettings.Setting{
+		"CreateFinalSnapshot": true,
+		"FinalSnapshotName":   "{{ .Name }}-final",
+	}
+
+	cacheCluster := ElasticacheCacheCluster{
+		svc:       mockElastiCache,
+		settings:  settings,
+		engine:    ptr.String("redis"),
+		ClusterID: ptr.String("foobar"),
+	}
+
+	mockElastiCache.EXPECT().DeleteCacheCluster(&elasticache.DeleteCacheClusterInput{
+		CacheClusterId:          ptr.String("foobar"),
+		FinalSnapshotIdentifier: ptr.String("aws-nuke-final-foobar-final"),
+	}).Return(&elasticache.DeleteCacheClusterOutput{}, nil)
+
+	err := cacheCluster.Remove(context.TODO())
+	a.Nil(err)
+
+	memcached := ElasticacheCacheCluster{
+		svc:       mockElastiCache,
+		settings:  settings,
+		engine:    ptr.String("memcached"),
+		ClusterID: ptr.String("foobaz"),
+	}
+
+	mockElastiCache.EXPECT().DeleteCacheCluster(&elasticache.DeleteCacheClusterInput{
+		CacheClusterId: ptr.String("foobaz"),
+	}).Return(&elasticache.DeleteCacheClusterOutput{}, nil)
+
+	err = memcached.Remove(context.TODO())
+	a.Nil(err)
+}
+
+func Test_Mock_ElastiCache_CacheCluster_HandleWait_FinalSnapshot(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElastiCache := mock_elasticacheiface.NewMockElastiCacheAPI(ctrl)
+
+	cacheCluster := ElasticacheCacheCluster{
+		svc: mockElastiCache,
+		settings: &libsettings.Setting{
+			"CreateFinalSnapshot": true,
+		},
+		engine:    ptr.String("redis"),
+		ClusterID: ptr.String("foobar"),
+	}
+
+	var snapshotName string
+	mockElastiCache.EXPECT().DeleteCacheCluster(gomock.Any()).DoAndReturn(
+		func(input *elasticache.DeleteCacheClusterInput) (*elasticache.DeleteCacheClusterOutput, error) {
+			snapshotName = ptr.ToString(input.FinalSnapshotIdentifier)
+			return nil, awserr.New("InvalidCacheClusterState", "cluster is modifying", nil)
+		})
+
+	a.Error(cacheCluster.Remove(context.TODO()))
+	a.Regexp(`^aws-nuke-final-foobar-\d{14}$`, snapshotName)
+
+	// Note: the retried removal requests the same snapshot
+	mockElastiCache.EXPECT().DeleteCacheCluster(&elasticache.DeleteCacheClusterInput{
+		CacheClusterId:          ptr.String("foobar"),
+		FinalSnapshotIdentifier: ptr.String(snapshotName),
+	}).Return(&elasticache.DeleteCacheClusterOutput{}, nil)
+
+	a.NoError(cacheCluster.Remove(context.TODO()))
+
+	describe := &elasticache.DescribeSnapshotsInput{SnapshotName: ptr.String(snapshotName)}
+
+	mockElastiCache.EXPECT().DescribeSnapshots(describe).
+		Return(nil, awserr.New(elasticache.ErrCodeSnapshotNotFoundFault, "not found", nil))
+	var waitErr liberrors.ErrWaitResource
+	a.ErrorAs(cacheCluster.HandleWait(context.TODO()), &waitErr)
+
+	mockElastiCache.EXPECT().DescribeSnapshots(describe).Return(&elasticache.DescribeSnapshotsOutput{
+		Snapshots: []*elasticache.Snapshot{{SnapshotStatus: ptr.String("creating")}},
+	}, nil)
+	a.ErrorAs(cacheCluster.HandleWait(context.TODO()), &waitErr)
+
+	mockElastiCache.EXPECT().DescribeSnapshots(describe).Return(&elasticache.DescribeSnapshotsOutput{
+		Snapshots: []*elasticache.Snapshot{{SnapshotStatus: ptr.String("available")}},
+	}, nil)
+	a.NoError(cacheCluster.HandleWait(context.TODO()))
+
+	mockElastiCache.EXPECT().DescribeSnapshots(describe).Return(&elasticache.DescribeSnapshotsOutput{
+		Snapshots: []*elasticache.Snapshot{{SnapshotStatus: ptr.String("failed")}},
+	}, nil)
+	err := cacheCluster.HandleWait(context.TODO())
+	a.ErrorContains(err, "is failed")
+	a.False(errors.As(err, &waitErr))
+}
+
+func Test_Mock_ElastiCache_CacheCluster_HandleWait_NoFinalSnapshot(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElastiCache := mock_elasticacheiface.NewMockElastiCacheAPI(ctrl)
+
+	cacheCluster := ElasticacheCacheCluster{
+		svc:       mockElastiCache,
+		engine:    ptr.String("redis"),
+		ClusterID: ptr.String("foobar"),
+	}
+
+	mockElastiCache.EXPECT().DeleteCacheCluster(&elasticache.DeleteCacheClusterInput{
+		CacheClusterId: ptr.String("foobar"),
+	}).Return(&elasticache.DeleteCacheClusterOutput{}, nil)
+
+	a.NoError(cacheCluster.Remove(context.TODO()))
+	a.NoError(cacheCluster.HandleWait(context.TODO()))
+}
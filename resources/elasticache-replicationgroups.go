@@ -8,6 +8,7 @@ import (
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
 )
@@ -20,6 +21,10 @@ func init() {
 		Scope:    nuke.Account,
 		Resource: &ElasticacheReplicationGroup{},
 		Lister:   &ElasticacheReplicationGroupLister{},
+		Settings: []string{
+			nuke.CreateFinalSnapshotSetting,
+			nuke.FinalSnapshotNameSetting,
+		},
 	})
 }
 
@@ -57,8 +62,14 @@ func (l *ElasticacheReplicationGroupLister) List(_ context.Context, o interface{
 }
 
 type ElasticacheReplicationGroup struct {
-	svc     *elasticache.ElastiCache
-	groupID *string
+	svc           *elasticache.ElastiCache
+	settings      *libsettings.Setting
+	finalSnapshot nuke.FinalSnapshot
+	groupID       *string
+}
+
+func (i *ElasticacheReplicationGroup) Settings(settings *libsettings.Setting) {
+	i.settings = settings
 }
 
 func (i *ElasticacheReplicationGroup) Remove(_ context.Context) error {
@@ -66,7 +77,16 @@ func (i *ElasticacheReplicationGroup) Remove(_ context.Context) error {
 		ReplicationGroupId: i.groupID,
 	}
 
-	_, err := i.svc.DeleteReplicationGroup(params)
+	snapshotName, err := i.finalSnapshot.Name(i.settings, aws.StringValue(i.groupID))
+	if err != nil {
+		return err
+	}
+
+	if snapshotName != "" {
+		params.FinalSnapshotIdentifier = aws.String(snapshotName)
+	}
+
+	_, err = i.svc.DeleteReplicationGroup(params)
 	if err != nil {
 		return err
 	}
@@ -74,6 +94,21 @@ func (i *ElasticacheReplicationGroup) Remove(_ context.Context) error {
 	return nil
 }
 
+// HandleWait waits for the final snapshot, if one was requested, to become available.
+func (i *ElasticacheReplicationGroup) HandleWait(_ context.Context) error {
+	snapshotName := i.finalSnapshot.Requested()
+	if snapshotName == "" {
+		return nil
+	}
+
+	status, err := elasticacheSnapshotStatus(i.svc, snapshotName)
+	if err != nil {
+		return err
+	}
+
+	return i.finalSnapshot.Wait(status)
+}
+
 func (i *ElasticacheReplicationGroup) String() string {
 	return *i.groupID
 }
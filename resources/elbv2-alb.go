@@ -76,6 +76,7 @@ func (l *ELBv2Lister) List(_ context.Context, o interface{}) ([]resource.Resourc
 				ARN:         elb.LoadBalancerArn,
 				Name:        elb.LoadBalancerName,
 				CreatedTime: elb.CreatedTime,
+				Type:        elb.Type,
 				Tags:        elbv2TagInfo.Tags,
 			})
 		}
@@ -92,6 +93,7 @@ type ELBv2LoadBalancer struct {
 	ARN         *string    `description:"ARN of the load balancer"`
 	Name        *string    `description:"Name of the load balancer"`
 	CreatedTime *time.Time `description:"Creation time of the load balancer"`
+	Type        *string    `description:"Type of the load balancer, either application, network or gateway"`
 	Tags        []*elbv2.Tag
 }
 
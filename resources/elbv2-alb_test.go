@@ -19,6 +19,7 @@ func Test_ELBv2LoadBalancer_Properties(t *testing.T) {
 		Name:        ptr.String("foobar-name"),
 		ARN:         ptr.String("foobar-arn"),
 		CreatedTime: ptr.Time(now),
+		Type:        ptr.String("application"),
 		Tags: []*elbv2.Tag{
 			{
 				Key:   aws.String("Name"),
@@ -32,5 +33,6 @@ func Test_ELBv2LoadBalancer_Properties(t *testing.T) {
 	a.Equal("foobar-name", props.Get("Name"))
 	a.Equal("foobar-arn", props.Get("ARN"))
 	a.Equal(now.Format(time.RFC3339), props.Get("CreatedTime"))
+	a.Equal("application", props.Get("Type"))
 	a.Equal("foobar-name", props.Get("tag:Name"))
 }
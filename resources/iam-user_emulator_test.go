@@ -0,0 +1,35 @@
+//go:build emulator
+
+package resources
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/service/iam"
+
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/awsutil"
+	"github.com/ekristen/aws-nuke/v3/pkg/testsuite"
+)
+
+func Test_Emulator_IAMUser(t *testing.T) {
+	emulator := testsuite.NewEmulator(t, "iam")
+
+	svc := iam.New(emulator.Session("iam"))
+
+	userName := fmt.Sprintf("aws-nuke-testing-%d", time.Now().UnixNano())
+	_, err := svc.CreateUser(&iam.CreateUserInput{
+		UserName: ptr.String(userName),
+	})
+	require.NoError(t, err)
+
+	emulator.RunCycle(awsutil.GlobalRegionID, IAMUserResource, func(r resource.Resource) bool {
+		return ptr.ToString(r.(*IAMUser).Name) == userName
+	})
+}
@@ -2,16 +2,26 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/gotidy/ptr"
 	"github.com/sirupsen/logrus"
 
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/rds"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -23,6 +33,14 @@ import (
 
 const KMSKeyResource = "KMSKey"
 
+const (
+	// KMSKeyDefaultPendingWindowInDays is the waiting period before a key is deleted, when none is set.
+	KMSKeyDefaultPendingWindowInDays = 7
+
+	kmsKeyMinPendingWindowInDays = 7
+	kmsKeyMaxPendingWindowInDays = 30
+)
+
 func init() {
 	registry.Register(&registry.Registration{
 		Name:     KMSKeyResource,
@@ -33,16 +51,53 @@ func init() {
 			KMSAliasResource,
 		},
 		Settings: []string{
+			"DeleteKeysInUse",
 			"IgnoreErrors",
+			"PendingWindowInDays",
 		},
 	})
 }
 
 type KMSKeyLister struct {
 	mockSvc kmsiface.KMSAPI
+
+	// mockReferences replaces the lookup of the resources that refer to the keys
+	mockReferences kmsKeyReferenceLookup
+
+	// buckets are the encrypted buckets of every account, they are looked up once per account instead of once per
+	// region
+	buckets     map[string]*kmsKeyBuckets
+	bucketsLock sync.Mutex
+}
+
+// kmsKeyReferenceLookup returns the resources of the region that are encrypted with a KMS key, by the key ID, ARN,
+// alias name or alias ARN that they refer to the key with. The error is set when some of the resources could not be
+// looked up, the references are then incomplete.
+type kmsKeyReferenceLookup func(ctx context.Context, opts *nuke.ListerOpts) (map[string][]string, error)
+
+// KMSKeyS3APIClient is the part of the S3 client that looks up the KMS keys of the buckets.
+type KMSKeyS3APIClient interface {
+	ListBuckets(context.Context, *s3.ListBucketsInput, ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
+	GetBucketEncryption(context.Context, *s3.GetBucketEncryptionInput,
+		...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error)
+}
+
+// kmsKeyBuckets are the encrypted buckets of an account.
+type kmsKeyBuckets struct {
+	once    sync.Once
+	buckets []kmsKeyBucket
+	err     error
+}
+
+// kmsKeyBucket is a bucket that is encrypted with a KMS key by default, or whose encryption could not be looked up.
+type kmsKeyBucket struct {
+	name   string
+	region string
+	keyID  string
+	err    error
 }
 
-func (l *KMSKeyLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+func (l *KMSKeyLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*nuke.ListerOpts)
 	resources := make([]resource.Resource, 0)
 
@@ -54,6 +109,10 @@ func (l *KMSKeyLister) List(_ context.Context, o interface{}) ([]resource.Resour
 	}
 
 	inaccessibleKeys := false
+	keysInUse := false
+
+	var references map[string][]string
+	var referencesErr error
 
 	if err := svc.ListKeysPages(nil, func(keysOut *kms.ListKeysOutput, lastPage bool) bool {
 		for _, key := range keysOut.Keys {
@@ -75,10 +134,19 @@ func (l *KMSKeyLister) List(_ context.Context, o interface{}) ([]resource.Resour
 			}
 
 			kmsKey := &KMSKey{
-				svc:     svc,
-				ID:      resp.KeyMetadata.KeyId,
-				State:   resp.KeyMetadata.KeyState,
-				Manager: resp.KeyMetadata.KeyManager,
+				svc:         svc,
+				ID:          resp.KeyMetadata.KeyId,
+				State:       resp.KeyMetadata.KeyState,
+				Manager:     resp.KeyMetadata.KeyManager,
+				KeyUsage:    resp.KeyMetadata.KeyUsage,
+				MultiRegion: resp.KeyMetadata.MultiRegion,
+			}
+
+			if cfg := resp.KeyMetadata.MultiRegionConfiguration; cfg != nil {
+				kmsKey.MultiRegionKeyType = cfg.MultiRegionKeyType
+				if cfg.PrimaryKey != nil {
+					kmsKey.PrimaryKeyArn = cfg.PrimaryKey.Arn
+				}
 			}
 
 			// Note: we check for customer managed keys here because we can't list tags for AWS managed keys
@@ -110,10 +178,54 @@ func (l *KMSKeyLister) List(_ context.Context, o interface{}) ([]resource.Resour
 				logrus.WithError(err).Error("unable to list aliases")
 			}
 
-			if len(keyAliases.Aliases) > 0 {
+			if keyAliases != nil && len(keyAliases.Aliases) > 0 {
 				kmsKey.Alias = keyAliases.Aliases[0].AliasName
 			}
 
+			// Note: the references are only looked up once there is a key that could be removed, as it takes a
+			// number of calls to other services
+			if ptr.ToString(resp.KeyMetadata.KeyManager) == kms.KeyManagerTypeCustomer {
+				if references == nil {
+					lookup := l.mockReferences
+					if lookup == nil {
+						lookup = l.references
+					}
+
+					references, referencesErr = lookup(ctx, opts)
+					if referencesErr != nil {
+						logrus.WithError(referencesErr).Warn("unable to look up whether KMS keys are in use")
+					}
+				}
+
+				// Note: when some of the references could not be looked up, a key without references is not known to
+				// be unused, so InUse is left unset
+				usedBy := kmsKeyUsedBy(references, resp.KeyMetadata, keyAliases)
+				switch {
+				case len(usedBy) > 0:
+					keysInUse = true
+					kmsKey.InUse = ptr.Bool(true)
+					kmsKey.UsedBy = ptr.String(strings.Join(usedBy, ", "))
+				case referencesErr == nil:
+					kmsKey.InUse = ptr.Bool(false)
+				}
+
+				policy, err := svc.GetKeyPolicy(&kms.GetKeyPolicyInput{
+					KeyId:      key.KeyId,
+					PolicyName: aws.String("default"),
+				})
+				if err == nil {
+					var sharedWith []string
+					sharedWith, err = kmsKeySharedWith(ptr.ToString(policy.Policy), ptr.ToString(resp.KeyMetadata.AWSAccountId))
+					if len(sharedWith) > 0 {
+						kmsKey.SharedWith = ptr.String(strings.Join(sharedWith, ", "))
+					}
+				}
+				if err != nil {
+					kmsKey.policyUnknown = true
+					logrus.WithField("id", ptr.ToString(key.KeyId)).WithError(err).Warn("unable to read the key policy")
+				}
+			}
+
 			resources = append(resources, kmsKey)
 		}
 
@@ -126,17 +238,309 @@ func (l *KMSKeyLister) List(_ context.Context, o interface{}) ([]resource.Resour
 		logrus.Warn("one or more KMS keys were inaccessible, debug logging will contain more information")
 	}
 
+	if keysInUse {
+		logrus.Warn("one or more KMS keys are in use by other resources and are skipped, see the UsedBy property of " +
+			"the keys and the DeleteKeysInUse setting")
+	}
+
 	return resources, nil
 }
 
+// references returns the resources of the region that are encrypted with a KMS key, by the key ID, ARN, alias name or
+// alias ARN that they refer to the key with. Resources that cannot be looked up are skipped, and their errors are
+// returned together with the references that were found.
+func (l *KMSKeyLister) references(ctx context.Context, opts *nuke.ListerOpts) (map[string][]string, error) {
+	var errs []error
+	references := map[string][]string{}
+	add := func(keyID *string, ref string) {
+		if keyID != nil && *keyID != "" {
+			references[*keyID] = append(references[*keyID], ref)
+		}
+	}
+
+	if err := ec2.New(opts.Session).DescribeVolumesPages(&ec2.DescribeVolumesInput{},
+		func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+			for _, volume := range page.Volumes {
+				add(volume.KmsKeyId, fmt.Sprintf("%s:%s", EC2VolumeResource, ptr.ToString(volume.VolumeId)))
+			}
+			return !lastPage
+		}); err != nil {
+		errs = append(errs, fmt.Errorf("unable to look up the KMS keys of EBS volumes: %w", err))
+	}
+
+	rdsSvc := rds.New(opts.Session)
+	if err := rdsSvc.DescribeDBInstancesPages(&rds.DescribeDBInstancesInput{},
+		func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+			for _, instance := range page.DBInstances {
+				add(instance.KmsKeyId, fmt.Sprintf("%s:%s", RDSInstanceResource, ptr.ToString(instance.DBInstanceIdentifier)))
+			}
+			return !lastPage
+		}); err != nil {
+		errs = append(errs, fmt.Errorf("unable to look up the KMS keys of RDS instances: %w", err))
+	}
+
+	if err := rdsSvc.DescribeDBClustersPages(&rds.DescribeDBClustersInput{},
+		func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+			for _, cluster := range page.DBClusters {
+				add(cluster.KmsKeyId, fmt.Sprintf("%s:%s", RDSDBClusterResource, ptr.ToString(cluster.DBClusterIdentifier)))
+			}
+			return !lastPage
+		}); err != nil {
+		errs = append(errs, fmt.Errorf("unable to look up the KMS keys of RDS clusters: %w", err))
+	}
+
+	buckets, err := l.encryptedBuckets(ctx, s3.NewFromConfig(*opts.Config), ptr.ToString(opts.AccountID))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("unable to look up the KMS keys of S3 buckets: %w", err))
+	}
+
+	// Note: a bucket can only be encrypted with a key of its own region
+	for _, bucket := range buckets {
+		if bucket.region != opts.Region.Name {
+			continue
+		}
+
+		if bucket.err != nil {
+			errs = append(errs, fmt.Errorf("unable to look up the KMS key of S3 bucket %s: %w", bucket.name, bucket.err))
+			continue
+		}
+
+		add(ptr.String(bucket.keyID), fmt.Sprintf("%s:%s", S3BucketResource, bucket.name))
+	}
+
+	return references, errors.Join(errs...)
+}
+
+// encryptedBuckets returns the buckets of the account that are encrypted with a KMS key by default. The buckets of
+// every region are looked up once per account, as the buckets and their encryption are not regional API calls, and
+// the regions of an account are scanned at the same time.
+func (l *KMSKeyLister) encryptedBuckets(
+	ctx context.Context, svc KMSKeyS3APIClient, accountID string) ([]kmsKeyBucket, error) {
+	l.bucketsLock.Lock()
+	if l.buckets == nil {
+		l.buckets = map[string]*kmsKeyBuckets{}
+	}
+
+	cached, ok := l.buckets[accountID]
+	if !ok {
+		cached = &kmsKeyBuckets{}
+		l.buckets[accountID] = cached
+	}
+	l.bucketsLock.Unlock()
+
+	cached.once.Do(func() {
+		cached.buckets, cached.err = kmsKeyEncryptedBuckets(ctx, svc)
+	})
+
+	return cached.buckets, cached.err
+}
+
+// kmsKeyEncryptedBuckets looks up the buckets of every region that are encrypted with a KMS key by default. The
+// buckets whose encryption could not be looked up are returned with the error.
+func kmsKeyEncryptedBuckets(ctx context.Context, svc KMSKeyS3APIClient) ([]kmsKeyBucket, error) {
+	var buckets []kmsKeyBucket
+
+	params := &s3.ListBucketsInput{
+		MaxBuckets: ptr.Int32(100),
+	}
+
+	for {
+		resp, err := svc.ListBuckets(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, bucket := range resp.Buckets {
+			region := ptr.ToString(bucket.BucketRegion)
+
+			// Note: the encryption is requested from the region of the bucket
+			enc, err := svc.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{
+				Bucket: bucket.Name,
+			}, func(o *s3.Options) {
+				if region != "" {
+					o.Region = region
+				}
+			})
+			if err != nil {
+				var apiErr smithy.APIError
+				if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ServerSideEncryptionConfigurationNotFoundError" {
+					continue
+				}
+
+				buckets = append(buckets, kmsKeyBucket{
+					name:   ptr.ToString(bucket.Name),
+					region: region,
+					err:    err,
+				})
+				continue
+			}
+
+			for _, rule := range enc.ServerSideEncryptionConfiguration.Rules {
+				if rule.ApplyServerSideEncryptionByDefault == nil ||
+					rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm == s3types.ServerSideEncryptionAes256 ||
+					ptr.ToString(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID) == "" {
+					continue
+				}
+
+				buckets = append(buckets, kmsKeyBucket{
+					name:   ptr.ToString(bucket.Name),
+					region: region,
+					keyID:  ptr.ToString(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID),
+				})
+			}
+		}
+
+		if resp.ContinuationToken == nil {
+			break
+		}
+
+		params.ContinuationToken = resp.ContinuationToken
+	}
+
+	return buckets, nil
+}
+
+// kmsKeyPolicy is the part of a key policy that grants the use of the key.
+type kmsKeyPolicy struct {
+	Statement json.RawMessage
+}
+
+// kmsKeyPolicyStatement is a statement of a key policy, the principal is either "*" or a map of the principal type to
+// one or more principals.
+type kmsKeyPolicyStatement struct {
+	Effect    string
+	Principal json.RawMessage
+}
+
+// kmsKeySharedWith returns the sorted accounts other than the account of the key, and the AWS services, that the
+// statements of the key policy allow to use the key. An AWS principal of "*" is returned as is.
+func kmsKeySharedWith(policy, accountID string) ([]string, error) {
+	var doc kmsKeyPolicy
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return nil, fmt.Errorf("invalid key policy: %w", err)
+	}
+
+	// Note: the statements are either a list or a single statement
+	var statements []kmsKeyPolicyStatement
+	if err := json.Unmarshal(doc.Statement, &statements); err != nil {
+		var statement kmsKeyPolicyStatement
+		if err := json.Unmarshal(doc.Statement, &statement); err != nil {
+			return nil, fmt.Errorf("invalid key policy: %w", err)
+		}
+		statements = []kmsKeyPolicyStatement{statement}
+	}
+
+	seen := map[string]bool{}
+	sharedWith := make([]string, 0)
+	add := func(principal string) {
+		if principal != "" && !seen[principal] {
+			seen[principal] = true
+			sharedWith = append(sharedWith, principal)
+		}
+	}
+
+	for _, statement := range statements {
+		if statement.Effect != "Allow" || len(statement.Principal) == 0 {
+			continue
+		}
+
+		var everyone string
+		if err := json.Unmarshal(statement.Principal, &everyone); err == nil {
+			add(everyone)
+			continue
+		}
+
+		var principals map[string]json.RawMessage
+		if err := json.Unmarshal(statement.Principal, &principals); err != nil {
+			return nil, fmt.Errorf("invalid key policy: %w", err)
+		}
+
+		for principalType, raw := range principals {
+			var values []string
+			if err := json.Unmarshal(raw, &values); err != nil {
+				var value string
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return nil, fmt.Errorf("invalid key policy: %w", err)
+				}
+				values = []string{value}
+			}
+
+			for _, value := range values {
+				switch principalType {
+				case "AWS":
+					if account := kmsKeyPrincipalAccount(value); account != accountID {
+						add(account)
+					}
+				case "Service":
+					add(value)
+				}
+			}
+		}
+	}
+
+	sort.Strings(sharedWith)
+
+	return sharedWith, nil
+}
+
+// kmsKeyPrincipalAccount returns the account of an AWS principal, which is either an account ID or the ARN of an IAM
+// identity, or "*".
+func kmsKeyPrincipalAccount(principal string) string {
+	if !strings.HasPrefix(principal, "arn:") {
+		return principal
+	}
+
+	parts := strings.SplitN(principal, ":", 6)
+	if len(parts) < 5 {
+		return principal
+	}
+
+	return parts[4]
+}
+
+// kmsKeyUsedBy returns the sorted resources that refer to the key by its ID, ARN or one of its aliases.
+func kmsKeyUsedBy(references map[string][]string, key *kms.KeyMetadata, aliases *kms.ListAliasesOutput) []string {
+	ids := []*string{key.KeyId, key.Arn}
+	if aliases != nil {
+		for _, alias := range aliases.Aliases {
+			ids = append(ids, alias.AliasName, alias.AliasArn)
+		}
+	}
+
+	seen := map[string]bool{}
+	usedBy := make([]string, 0)
+	for _, id := range ids {
+		for _, ref := range references[ptr.ToString(id)] {
+			if !seen[ref] {
+				seen[ref] = true
+				usedBy = append(usedBy, ref)
+			}
+		}
+	}
+
+	sort.Strings(usedBy)
+
+	return usedBy
+}
+
 type KMSKey struct {
-	svc      kmsiface.KMSAPI
-	settings *libsettings.Setting
-	ID       *string
-	State    *string
-	Manager  *string
-	Alias    *string
-	Tags     []*kms.Tag
+	svc                kmsiface.KMSAPI
+	settings           *libsettings.Setting
+	ID                 *string `description:"The ID of the key"`
+	State              *string `description:"The state of the key"`
+	Manager            *string `description:"The manager of the key, either AWS or CUSTOMER"`
+	Alias              *string `description:"The first alias of the key"`
+	KeyUsage           *string `description:"The cryptographic operations the key is used for, for example ENCRYPT_DECRYPT"`
+	MultiRegion        *bool   `description:"Whether the key is a multi-region key"`
+	MultiRegionKeyType *string `description:"The type of a multi-region key, either PRIMARY or REPLICA"`
+	PrimaryKeyArn      *string `description:"The ARN of the primary key of a multi-region key"`
+	InUse              *bool   `description:"Whether EBS volumes, RDS instances or clusters, or S3 buckets of the region are encrypted with the key, unset when it could not be looked up"`
+	UsedBy             *string `description:"The resources of the region that are encrypted with the key, as a comma separated list of type:name"`
+	SharedWith         *string `description:"The other accounts and the AWS services that the key policy allows to use the key, as a comma separated list"`
+	Tags               []*kms.Tag
+
+	// policyUnknown is set when the key policy could not be read
+	policyUnknown bool
 }
 
 func (r *KMSKey) Filter() error {
@@ -148,13 +552,35 @@ func (r *KMSKey) Filter() error {
 		return fmt.Errorf("cannot delete AWS managed key")
 	}
 
+	if r.settings != nil && r.settings.GetBool("DeleteKeysInUse") {
+		return nil
+	}
+
+	// Note: a key that is not known to be unused is kept, deleting a key that is in use makes the data that it
+	// encrypts unreadable
+	switch {
+	case r.InUse == nil:
+		return fmt.Errorf("unable to determine whether the key is in use")
+	case *r.InUse:
+		return fmt.Errorf("key is in use by %s", ptr.ToString(r.UsedBy))
+	case r.policyUnknown:
+		return fmt.Errorf("unable to read the key policy")
+	case r.SharedWith != nil:
+		return fmt.Errorf("key policy allows %s to use the key", ptr.ToString(r.SharedWith))
+	}
+
 	return nil
 }
 
 func (r *KMSKey) Remove(_ context.Context) error {
-	_, err := r.svc.ScheduleKeyDeletion(&kms.ScheduleKeyDeletionInput{
+	pendingWindow, err := kmsKeyPendingWindowInDays(r.settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.svc.ScheduleKeyDeletion(&kms.ScheduleKeyDeletionInput{
 		KeyId:               r.ID,
-		PendingWindowInDays: aws.Int64(7),
+		PendingWindowInDays: aws.Int64(pendingWindow),
 	})
 
 	// Ignore errors if the setting is enabled as AWS KMS keys can be in a state where they can't
@@ -166,6 +592,33 @@ func (r *KMSKey) Remove(_ context.Context) error {
 	return err
 }
 
+// ValidateSettings validates the PendingWindowInDays setting, so that a waiting period that is out of range is caught
+// when the configuration is loaded instead of failing the removal of every key.
+func (r *KMSKey) ValidateSettings(settings *libsettings.Setting) error {
+	_, err := kmsKeyPendingWindowInDays(settings)
+	return err
+}
+
+// kmsKeyPendingWindowInDays returns the waiting period of the PendingWindowInDays setting, between 7 and 30 days.
+func kmsKeyPendingWindowInDays(settings *libsettings.Setting) (int64, error) {
+	if settings == nil || settings.Get("PendingWindowInDays") == nil {
+		return KMSKeyDefaultPendingWindowInDays, nil
+	}
+
+	days, ok := settings.Get("PendingWindowInDays").(int)
+	if !ok || days < kmsKeyMinPendingWindowInDays || days > kmsKeyMaxPendingWindowInDays {
+		return 0, fmt.Errorf("PendingWindowInDays must be a number of days between %d and %d",
+			kmsKeyMinPendingWindowInDays, kmsKeyMaxPendingWindowInDays)
+	}
+
+	return int64(days), nil
+}
+
+// IsReplica returns true if the key is the replica of a multi-region key.
+func (r *KMSKey) IsReplica() bool {
+	return ptr.ToString(r.MultiRegionKeyType) == kms.MultiRegionKeyTypeReplica
+}
+
 func (r *KMSKey) String() string {
 	return *r.ID
 }
@@ -2,19 +2,141 @@ package resources
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/gotidy/ptr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/kms"
 
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
+
 	"github.com/ekristen/aws-nuke/v3/mocks/mock_kmsiface"
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
 )
 
+// kmsKeyTestPolicy is the default key policy, which only allows the account of the key to use it.
+const kmsKeyTestPolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [
+		{
+			"Sid": "Enable IAM User Permissions",
+			"Effect": "Allow",
+			"Principal": {"AWS": "arn:aws:iam::123456789012:root"},
+			"Action": "kms:*",
+			"Resource": "*"
+		}
+	]
+}`
+
+// kmsKeyReferences returns a lookup of the references that returns the references regardless of the region.
+func kmsKeyReferences(references map[string][]string) kmsKeyReferenceLookup {
+	return func(context.Context, *nuke.ListerOpts) (map[string][]string, error) {
+		if references == nil {
+			return map[string][]string{}, nil
+		}
+		return references, nil
+	}
+}
+
+type mockKMSKeyS3Client struct {
+	mock.Mock
+}
+
+func (m *mockKMSKeyS3Client) ListBuckets(ctx context.Context, params *s3.ListBucketsInput,
+	_ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*s3.ListBucketsOutput), args.Error(1)
+}
+
+func (m *mockKMSKeyS3Client) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput,
+	_ ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	args := m.Called(ctx, params)
+	out, _ := args.Get(0).(*s3.GetBucketEncryptionOutput)
+	return out, args.Error(1)
+}
+
+func kmsKeyBucketEncryption(algorithm s3types.ServerSideEncryption, keyID string) *s3.GetBucketEncryptionOutput {
+	return &s3.GetBucketEncryptionOutput{
+		ServerSideEncryptionConfiguration: &s3types.ServerSideEncryptionConfiguration{
+			Rules: []s3types.ServerSideEncryptionRule{
+				{
+					ApplyServerSideEncryptionByDefault: &s3types.ServerSideEncryptionByDefault{
+						SSEAlgorithm:   algorithm,
+						KMSMasterKeyID: ptr.String(keyID),
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_Mock_KMSKey_EncryptedBuckets(t *testing.T) {
+	a := assert.New(t)
+
+	svc := &mockKMSKeyS3Client{}
+	svc.On("ListBuckets", mock.Anything, &s3.ListBucketsInput{MaxBuckets: ptr.Int32(100)}).
+		Return(&s3.ListBucketsOutput{
+			Buckets: []s3types.Bucket{
+				{Name: ptr.String("bucket-1"), BucketRegion: ptr.String("us-east-2")},
+				{Name: ptr.String("bucket-2"), BucketRegion: ptr.String("us-west-2")},
+			},
+			ContinuationToken: ptr.String("next"),
+		}, nil)
+	svc.On("ListBuckets", mock.Anything, &s3.ListBucketsInput{
+		MaxBuckets:        ptr.Int32(100),
+		ContinuationToken: ptr.String("next"),
+	}).Return(&s3.ListBucketsOutput{
+		Buckets: []s3types.Bucket{
+			{Name: ptr.String("bucket-3"), BucketRegion: ptr.String("us-east-2")},
+		},
+	}, nil)
+	svc.On("GetBucketEncryption", mock.Anything, &s3.GetBucketEncryptionInput{Bucket: ptr.String("bucket-1")}).
+		Return(kmsKeyBucketEncryption(s3types.ServerSideEncryptionAwsKms, "key-1"), nil)
+	svc.On("GetBucketEncryption", mock.Anything, &s3.GetBucketEncryptionInput{Bucket: ptr.String("bucket-2")}).
+		Return(kmsKeyBucketEncryption(s3types.ServerSideEncryptionAwsKms, "key-2"), nil)
+	svc.On("GetBucketEncryption", mock.Anything, &s3.GetBucketEncryptionInput{Bucket: ptr.String("bucket-3")}).
+		Return(kmsKeyBucketEncryption(s3types.ServerSideEncryptionAes256, ""), nil)
+
+	lister := &KMSKeyLister{}
+
+	// Note: the regions of an account are scanned at the same time, the buckets are only looked up once
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buckets, err := lister.encryptedBuckets(context.TODO(), svc, "012345678901")
+			a.NoError(err)
+			a.Equal([]kmsKeyBucket{
+				{name: "bucket-1", region: "us-east-2", keyID: "key-1"},
+				{name: "bucket-2", region: "us-west-2", keyID: "key-2"},
+			}, buckets)
+		}()
+	}
+	wg.Wait()
+
+	svc.AssertNumberOfCalls(t, "ListBuckets", 2)
+	svc.AssertNumberOfCalls(t, "GetBucketEncryption", 3)
+
+	// Note: the buckets of another account are looked up again
+	_, err := lister.encryptedBuckets(context.TODO(), svc, "123456789012")
+	a.NoError(err)
+
+	svc.AssertNumberOfCalls(t, "ListBuckets", 4)
+	svc.AssertNumberOfCalls(t, "GetBucketEncryption", 6)
+}
+
 func Test_Mock_KMSKey_List(t *testing.T) {
 	a := assert.New(t)
 	ctrl := gomock.NewController(t)
@@ -64,8 +186,14 @@ func Test_Mock_KMSKey_List(t *testing.T) {
 		},
 	}, nil)
 
+	mockKMS.EXPECT().GetKeyPolicy(&kms.GetKeyPolicyInput{
+		KeyId:      aws.String("test-key-id"),
+		PolicyName: aws.String("default"),
+	}).Return(&kms.GetKeyPolicyOutput{Policy: aws.String(kmsKeyTestPolicy)}, nil)
+
 	lister := KMSKeyLister{
-		mockSvc: mockKMS,
+		mockSvc:        mockKMS,
+		mockReferences: kmsKeyReferences(nil),
 	}
 
 	resources, err := lister.List(context.TODO(), testListerOpts)
@@ -139,8 +267,11 @@ func Test_Mock_KMSKey_List_WithAccessDenied(t *testing.T) {
 		},
 	}, nil)
 
+	mockKMS.EXPECT().GetKeyPolicy(gomock.Any()).Return(&kms.GetKeyPolicyOutput{Policy: aws.String(kmsKeyTestPolicy)}, nil)
+
 	lister := KMSKeyLister{
-		mockSvc: mockKMS,
+		mockSvc:        mockKMS,
+		mockReferences: kmsKeyReferences(nil),
 	}
 
 	resources, err := lister.List(context.TODO(), testListerOpts)
@@ -150,10 +281,13 @@ func Test_Mock_KMSKey_List_WithAccessDenied(t *testing.T) {
 
 func Test_Mock_KMSKey_Filter(t *testing.T) {
 	cases := []struct {
-		name    string
-		state   string
-		manager string
-		error   string
+		name       string
+		state      string
+		manager    string
+		inUse      *bool
+		sharedWith *string
+		settings   *libsettings.Setting
+		error      string
 	}{
 		{
 			name:    "aws-managed-key",
@@ -177,15 +311,48 @@ func Test_Mock_KMSKey_Filter(t *testing.T) {
 			name:    "enabled-key",
 			state:   kms.KeyStateEnabled,
 			manager: kms.KeyManagerTypeCustomer,
+			inUse:   ptr.Bool(false),
 			error:   "",
 		},
+		{
+			name:    "in-use-key",
+			state:   kms.KeyStateEnabled,
+			manager: kms.KeyManagerTypeCustomer,
+			inUse:   ptr.Bool(true),
+			error:   "key is in use by",
+		},
+		{
+			name:     "in-use-key-deleted",
+			state:    kms.KeyStateEnabled,
+			manager:  kms.KeyManagerTypeCustomer,
+			inUse:    ptr.Bool(true),
+			settings: &libsettings.Setting{"DeleteKeysInUse": true},
+			error:    "",
+		},
+		{
+			name:    "unknown-key",
+			state:   kms.KeyStateEnabled,
+			manager: kms.KeyManagerTypeCustomer,
+			error:   "unable to determine whether the key is in use",
+		},
+		{
+			name:       "shared-key",
+			state:      kms.KeyStateEnabled,
+			manager:    kms.KeyManagerTypeCustomer,
+			inUse:      ptr.Bool(false),
+			sharedWith: ptr.String("210987654321"),
+			error:      "key policy allows 210987654321 to use the key",
+		},
 	}
 
 	for _, tc := range cases {
 		kmsKey := KMSKey{
-			ID:      ptr.String("test-key-id"),
-			State:   ptr.String(tc.state),
-			Manager: ptr.String(tc.manager),
+			settings:   tc.settings,
+			ID:         ptr.String("test-key-id"),
+			State:      ptr.String(tc.state),
+			Manager:    ptr.String(tc.manager),
+			InUse:      tc.inUse,
+			SharedWith: tc.sharedWith,
 		}
 
 		err := kmsKey.Filter()
@@ -237,3 +404,256 @@ func Test_Mock_KMSKey_Remove(t *testing.T) {
 	err := kmsKey.Remove(context.TODO())
 	a.NoError(err)
 }
+
+func Test_Mock_KMSKey_List_MultiRegionInUse(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKMS := mock_kmsiface.NewMockKMSAPI(ctrl)
+
+	mockKMS.EXPECT().ListKeysPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *kms.ListKeysInput, fn func(*kms.ListKeysOutput, bool) bool) error {
+			fn(&kms.ListKeysOutput{
+				Keys: []*kms.KeyListEntry{
+					{KeyId: aws.String("mrk-test-key-id")},
+				},
+			}, true)
+			return nil
+		},
+	)
+
+	mockKMS.EXPECT().DescribeKey(gomock.Any()).Return(&kms.DescribeKeyOutput{
+		KeyMetadata: &kms.KeyMetadata{
+			KeyId:       aws.String("mrk-test-key-id"),
+			Arn:         aws.String("arn:aws:kms:us-east-2:123456789012:key/mrk-test-key-id"),
+			KeyManager:  aws.String(kms.KeyManagerTypeCustomer),
+			KeyState:    aws.String(kms.KeyStateEnabled),
+			KeyUsage:    aws.String(kms.KeyUsageTypeEncryptDecrypt),
+			MultiRegion: aws.Bool(true),
+			MultiRegionConfiguration: &kms.MultiRegionConfiguration{
+				MultiRegionKeyType: aws.String(kms.MultiRegionKeyTypeReplica),
+				PrimaryKey: &kms.MultiRegionKey{
+					Arn:    aws.String("arn:aws:kms:us-east-1:123456789012:key/mrk-test-key-id"),
+					Region: aws.String("us-east-1"),
+				},
+			},
+		},
+	}, nil)
+
+	mockKMS.EXPECT().ListResourceTags(gomock.Any()).Return(&kms.ListResourceTagsOutput{}, nil)
+
+	mockKMS.EXPECT().ListAliases(&kms.ListAliasesInput{
+		KeyId: aws.String("mrk-test-key-id"),
+	}).Return(&kms.ListAliasesOutput{
+		Aliases: []*kms.AliasListEntry{
+			{AliasName: aws.String("alias/test-key")},
+		},
+	}, nil)
+
+	mockKMS.EXPECT().GetKeyPolicy(gomock.Any()).Return(&kms.GetKeyPolicyOutput{Policy: aws.String(kmsKeyTestPolicy)}, nil)
+
+	lister := KMSKeyLister{
+		mockSvc: mockKMS,
+		mockReferences: kmsKeyReferences(map[string][]string{
+			"arn:aws:kms:us-east-2:123456789012:key/mrk-test-key-id": {"EC2Volume:vol-1", "RDSInstance:db-1"},
+			"alias/test-key": {"S3Bucket:bucket-1", "EC2Volume:vol-1"},
+			"other-key-id":   {"EC2Volume:vol-2"},
+		}),
+	}
+
+	resources, err := lister.List(context.TODO(), testListerOpts)
+	a.NoError(err)
+	a.Len(resources, 1)
+
+	kmsKey := resources[0].(*KMSKey)
+	a.True(kmsKey.IsReplica())
+	a.Equal(kms.KeyUsageTypeEncryptDecrypt, kmsKey.Properties().Get("KeyUsage"))
+	a.Equal("true", kmsKey.Properties().Get("MultiRegion"))
+	a.Equal(kms.MultiRegionKeyTypeReplica, kmsKey.Properties().Get("MultiRegionKeyType"))
+	a.Equal("arn:aws:kms:us-east-1:123456789012:key/mrk-test-key-id", kmsKey.Properties().Get("PrimaryKeyArn"))
+	a.Equal("true", kmsKey.Properties().Get("InUse"))
+	a.Equal("EC2Volume:vol-1, RDSInstance:db-1, S3Bucket:bucket-1", kmsKey.Properties().Get("UsedBy"))
+	a.EqualError(kmsKey.Filter(), "key is in use by EC2Volume:vol-1, RDSInstance:db-1, S3Bucket:bucket-1")
+}
+
+func Test_Mock_KMSKey_List_Unknown(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKMS := mock_kmsiface.NewMockKMSAPI(ctrl)
+
+	mockKMS.EXPECT().ListKeysPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *kms.ListKeysInput, fn func(*kms.ListKeysOutput, bool) bool) error {
+			fn(&kms.ListKeysOutput{
+				Keys: []*kms.KeyListEntry{
+					{KeyId: aws.String("used-key-id")},
+					{KeyId: aws.String("unknown-key-id")},
+				},
+			}, true)
+			return nil
+		},
+	)
+
+	for _, id := range []string{"used-key-id", "unknown-key-id"} {
+		mockKMS.EXPECT().DescribeKey(&kms.DescribeKeyInput{KeyId: aws.String(id)}).Return(&kms.DescribeKeyOutput{
+			KeyMetadata: &kms.KeyMetadata{
+				AWSAccountId: aws.String("123456789012"),
+				KeyId:        aws.String(id),
+				Arn:          aws.String("arn:aws:kms:us-east-2:123456789012:key/" + id),
+				KeyManager:   aws.String(kms.KeyManagerTypeCustomer),
+				KeyState:     aws.String(kms.KeyStateEnabled),
+			},
+		}, nil)
+		mockKMS.EXPECT().ListResourceTags(&kms.ListResourceTagsInput{KeyId: aws.String(id)}).
+			Return(&kms.ListResourceTagsOutput{}, nil)
+		mockKMS.EXPECT().ListAliases(&kms.ListAliasesInput{KeyId: aws.String(id)}).
+			Return(&kms.ListAliasesOutput{}, nil)
+	}
+
+	mockKMS.EXPECT().GetKeyPolicy(&kms.GetKeyPolicyInput{
+		KeyId:      aws.String("used-key-id"),
+		PolicyName: aws.String("default"),
+	}).Return(&kms.GetKeyPolicyOutput{Policy: aws.String(kmsKeyTestPolicy)}, nil)
+	mockKMS.EXPECT().GetKeyPolicy(&kms.GetKeyPolicyInput{
+		KeyId:      aws.String("unknown-key-id"),
+		PolicyName: aws.String("default"),
+	}).Return(nil, awserr.New("AccessDeniedException", "not authorized to perform kms:GetKeyPolicy", nil))
+
+	// Note: the RDS instances could not be looked up, only the key that is known to be used is in use
+	lister := KMSKeyLister{
+		mockSvc: mockKMS,
+		mockReferences: func(context.Context, *nuke.ListerOpts) (map[string][]string, error) {
+			return map[string][]string{
+				"used-key-id": {"EC2Volume:vol-1"},
+			}, errors.New("unable to look up the KMS keys of RDS instances: access denied")
+		},
+	}
+
+	resources, err := lister.List(context.TODO(), testListerOpts)
+	a.NoError(err)
+	a.Len(resources, 2)
+
+	used := resources[0].(*KMSKey)
+	a.Equal("true", used.Properties().Get("InUse"))
+	a.EqualError(used.Filter(), "key is in use by EC2Volume:vol-1")
+
+	unknown := resources[1].(*KMSKey)
+	a.Nil(unknown.InUse)
+	a.Equal("", unknown.Properties().Get("InUse"))
+	a.True(unknown.policyUnknown)
+	a.EqualError(unknown.Filter(), "unable to determine whether the key is in use")
+
+	unknown.InUse = ptr.Bool(false)
+	a.EqualError(unknown.Filter(), "unable to read the key policy")
+
+	unknown.settings = &libsettings.Setting{"DeleteKeysInUse": true}
+	a.NoError(unknown.Filter())
+}
+
+func Test_Mock_KMSKey_SharedWith(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy string
+		want   []string
+		error  string
+	}{
+		{
+			name:   "default",
+			policy: kmsKeyTestPolicy,
+			want:   []string{},
+		},
+		{
+			name: "other-account-and-service",
+			policy: `{"Statement": [
+				{"Effect": "Allow", "Principal": {"AWS": "arn:aws:iam::123456789012:root"}, "Action": "kms:*"},
+				{"Effect": "Allow", "Principal": {"AWS": ["arn:aws:iam::210987654321:role/app", "111111111111"]}},
+				{"Effect": "Allow", "Principal": {"Service": "logs.us-east-2.amazonaws.com"}},
+				{"Effect": "Deny", "Principal": {"AWS": "arn:aws:iam::333333333333:root"}}
+			]}`,
+			want: []string{"111111111111", "210987654321", "logs.us-east-2.amazonaws.com"},
+		},
+		{
+			name:   "everyone",
+			policy: `{"Statement": {"Effect": "Allow", "Principal": "*", "Action": "kms:Decrypt"}}`,
+			want:   []string{"*"},
+		},
+		{
+			name:   "invalid",
+			policy: `{"Statement": [{"Effect": "Allow", "Principal": 1}]}`,
+			error:  "invalid key policy",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sharedWith, err := kmsKeySharedWith(tc.policy, "123456789012")
+			if tc.error != "" {
+				assert.ErrorContains(t, err, tc.error)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, sharedWith)
+		})
+	}
+}
+
+func Test_Mock_KMSKey_Remove_PendingWindowInDays(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKMS := mock_kmsiface.NewMockKMSAPI(ctrl)
+
+	mockKMS.EXPECT().ScheduleKeyDeletion(&kms.ScheduleKeyDeletionInput{
+		KeyId:               aws.String("test-key-id"),
+		PendingWindowInDays: aws.Int64(30),
+	}).Return(&kms.ScheduleKeyDeletionOutput{}, nil)
+
+	kmsKey := KMSKey{
+		svc:      mockKMS,
+		settings: &libsettings.Setting{"PendingWindowInDays": 30},
+		ID:       ptr.String("test-key-id"),
+	}
+
+	err := kmsKey.Remove(context.TODO())
+	a.NoError(err)
+
+	for _, days := range []interface{}{6, 31, "14"} {
+		kmsKey.settings = &libsettings.Setting{"PendingWindowInDays": days}
+
+		err = kmsKey.Remove(context.TODO())
+		a.ErrorContains(err, "PendingWindowInDays must be a number of days between 7 and 30")
+
+		err = kmsKey.ValidateSettings(kmsKey.settings)
+		a.ErrorContains(err, "PendingWindowInDays must be a number of days between 7 and 30")
+	}
+
+	a.NoError(kmsKey.ValidateSettings(&libsettings.Setting{"PendingWindowInDays": 14}))
+	a.NoError(kmsKey.ValidateSettings(nil))
+}
+
+func Test_Mock_KMSKey_EncryptedBuckets_Error(t *testing.T) {
+	a := assert.New(t)
+
+	svc := &mockKMSKeyS3Client{}
+	svc.On("ListBuckets", mock.Anything, mock.Anything).Return(&s3.ListBucketsOutput{
+		Buckets: []s3types.Bucket{
+			{Name: ptr.String("bucket-1"), BucketRegion: ptr.String("us-east-2")},
+			{Name: ptr.String("bucket-2"), BucketRegion: ptr.String("us-east-2")},
+		},
+	}, nil)
+	svc.On("GetBucketEncryption", mock.Anything, &s3.GetBucketEncryptionInput{Bucket: ptr.String("bucket-1")}).
+		Return(nil, &smithy.GenericAPIError{Code: "ServerSideEncryptionConfigurationNotFoundError"})
+	svc.On("GetBucketEncryption", mock.Anything, &s3.GetBucketEncryptionInput{Bucket: ptr.String("bucket-2")}).
+		Return(nil, &smithy.GenericAPIError{Code: "AccessDenied"})
+
+	// Note: a bucket without encryption is not encrypted with a key, a bucket that cannot be looked up is unknown
+	buckets, err := kmsKeyEncryptedBuckets(context.TODO(), svc)
+	a.NoError(err)
+	a.Len(buckets, 1)
+	a.Equal("bucket-2", buckets[0].name)
+	a.ErrorContains(buckets[0].err, "AccessDenied")
+}
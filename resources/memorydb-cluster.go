@@ -2,12 +2,15 @@ package resources
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/memorydb"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
@@ -21,6 +24,10 @@ func init() {
 		Scope:    nuke.Account,
 		Resource: &MemoryDBCluster{},
 		Lister:   &MemoryDBClusterLister{},
+		Settings: []string{
+			nuke.CreateFinalSnapshotSetting,
+			nuke.FinalSnapshotNameSetting,
+		},
 	})
 }
 
@@ -70,6 +77,13 @@ type MemoryDBCluster struct {
 	svc  *memorydb.MemoryDB
 	name *string
 	tags []*memorydb.Tag
+
+	settings      *libsettings.Setting
+	finalSnapshot nuke.FinalSnapshot
+}
+
+func (c *MemoryDBCluster) Settings(settings *libsettings.Setting) {
+	c.settings = settings
 }
 
 func (c *MemoryDBCluster) Remove(_ context.Context) error {
@@ -77,7 +91,16 @@ func (c *MemoryDBCluster) Remove(_ context.Context) error {
 		ClusterName: c.name,
 	}
 
-	_, err := c.svc.DeleteCluster(params)
+	snapshotName, err := c.finalSnapshot.Name(c.settings, aws.StringValue(c.name))
+	if err != nil {
+		return err
+	}
+
+	if snapshotName != "" {
+		params.FinalSnapshotName = aws.String(snapshotName)
+	}
+
+	_, err = c.svc.DeleteCluster(params)
 	if err != nil {
 		return err
 	}
@@ -85,6 +108,33 @@ func (c *MemoryDBCluster) Remove(_ context.Context) error {
 	return nil
 }
 
+// HandleWait waits for the final snapshot, if one was requested, to become available.
+func (c *MemoryDBCluster) HandleWait(_ context.Context) error {
+	snapshotName := c.finalSnapshot.Requested()
+	if snapshotName == "" {
+		return nil
+	}
+
+	resp, err := c.svc.DescribeSnapshots(&memorydb.DescribeSnapshotsInput{
+		SnapshotName: aws.String(snapshotName),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == memorydb.ErrCodeSnapshotNotFoundFault {
+			return c.finalSnapshot.Wait("")
+		}
+
+		return err
+	}
+
+	var status string
+	if len(resp.Snapshots) > 0 {
+		status = aws.StringValue(resp.Snapshots[0].Status)
+	}
+
+	return c.finalSnapshot.Wait(status)
+}
+
 func (c *MemoryDBCluster) String() string {
 	return *c.name
 }
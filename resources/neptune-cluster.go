@@ -2,11 +2,13 @@ package resources
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/gotidy/ptr"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/neptune"
 
 	"github.com/ekristen/libnuke/pkg/registry"
@@ -30,6 +32,8 @@ func init() {
 		},
 		Settings: []string{
 			"DisableDeletionProtection",
+			nuke.CreateFinalSnapshotSetting,
+			nuke.FinalSnapshotNameSetting,
 		},
 	})
 }
@@ -88,8 +92,9 @@ func (l *NeptuneClusterLister) List(_ context.Context, o interface{}) ([]resourc
 }
 
 type NeptuneCluster struct {
-	svc      *neptune.Neptune
-	settings *libsettings.Setting
+	svc           *neptune.Neptune
+	settings      *libsettings.Setting
+	finalSnapshot nuke.FinalSnapshot
 
 	ID     *string
 	Status *string
@@ -118,14 +123,53 @@ func (r *NeptuneCluster) Remove(_ context.Context) error {
 		}
 	}
 
-	_, err := r.svc.DeleteDBCluster(&neptune.DeleteDBClusterInput{
+	params := &neptune.DeleteDBClusterInput{
 		DBClusterIdentifier: r.ID,
 		SkipFinalSnapshot:   ptr.Bool(true),
-	})
+	}
+
+	snapshotName, err := r.finalSnapshot.Name(r.settings, ptr.ToString(r.ID))
+	if err != nil {
+		return err
+	}
+
+	if snapshotName != "" {
+		params.SkipFinalSnapshot = ptr.Bool(false)
+		params.FinalDBSnapshotIdentifier = ptr.String(snapshotName)
+	}
+
+	_, err = r.svc.DeleteDBCluster(params)
 
 	return err
 }
 
+// HandleWait waits for the final snapshot, if one was requested, to become available.
+func (r *NeptuneCluster) HandleWait(_ context.Context) error {
+	snapshotName := r.finalSnapshot.Requested()
+	if snapshotName == "" {
+		return nil
+	}
+
+	resp, err := r.svc.DescribeDBClusterSnapshots(&neptune.DescribeDBClusterSnapshotsInput{
+		DBClusterSnapshotIdentifier: ptr.String(snapshotName),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == neptune.ErrCodeDBClusterSnapshotNotFoundFault {
+			return r.finalSnapshot.Wait("")
+		}
+
+		return err
+	}
+
+	var status string
+	if len(resp.DBClusterSnapshots) > 0 {
+		status = ptr.ToString(resp.DBClusterSnapshots[0].Status)
+	}
+
+	return r.finalSnapshot.Wait(status)
+}
+
 func (r *NeptuneCluster) Properties() types.Properties {
 	return types.NewPropertiesFromStruct(r)
 }
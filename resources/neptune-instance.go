@@ -2,13 +2,16 @@ package resources
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/gotidy/ptr"
 	"github.com/sirupsen/logrus"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/neptune"
+	"github.com/aws/aws-sdk-go/service/rds"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -29,6 +32,8 @@ func init() {
 		Settings: []string{
 			"DisableClusterDeletionProtection",
 			"DisableDeletionProtection",
+			nuke.CreateFinalSnapshotSetting,
+			nuke.FinalSnapshotNameSetting,
 		},
 	})
 }
@@ -39,6 +44,7 @@ func (l *NeptuneInstanceLister) List(_ context.Context, o interface{}) ([]resour
 	opts := o.(*nuke.ListerOpts)
 
 	svc := neptune.New(opts.Session)
+	rdsSvc := rds.New(opts.Session)
 	resources := make([]resource.Resource, 0)
 
 	params := &neptune.DescribeDBInstancesInput{
@@ -71,6 +77,7 @@ func (l *NeptuneInstanceLister) List(_ context.Context, o interface{}) ([]resour
 
 			resources = append(resources, &NeptuneInstance{
 				svc:       svc,
+				rdsSvc:    rdsSvc,
 				ID:        dbInstance.DBInstanceIdentifier,
 				ClusterID: dbInstance.DBClusterIdentifier,
 				Name:      dbInstance.DBName,
@@ -90,13 +97,15 @@ func (l *NeptuneInstanceLister) List(_ context.Context, o interface{}) ([]resour
 }
 
 type NeptuneInstance struct {
-	svc       *neptune.Neptune
-	settings  *libsettings.Setting
-	ID        *string
-	ClusterID *string
-	Name      *string
-	Status    *string
-	Tags      []*neptune.Tag
+	svc           *neptune.Neptune
+	rdsSvc        *rds.RDS
+	settings      *libsettings.Setting
+	finalSnapshot nuke.FinalSnapshot
+	ID            *string
+	ClusterID     *string
+	Name          *string
+	Status        *string
+	Tags          []*neptune.Tag
 }
 
 func (r *NeptuneInstance) Settings(settings *libsettings.Setting) {
@@ -131,14 +140,57 @@ func (r *NeptuneInstance) Remove(_ context.Context) error {
 		}
 	}
 
-	_, err := r.svc.DeleteDBInstance(&neptune.DeleteDBInstanceInput{
+	params := &neptune.DeleteDBInstanceInput{
 		DBInstanceIdentifier: r.ID,
 		SkipFinalSnapshot:    ptr.Bool(true),
-	})
+	}
+
+	// Note: the instances of a cluster have no snapshots of their own, the final snapshot is taken of the cluster
+	if r.ClusterID == nil {
+		snapshotName, err := r.finalSnapshot.Name(r.settings, ptr.ToString(r.ID))
+		if err != nil {
+			return err
+		}
+
+		if snapshotName != "" {
+			params.SkipFinalSnapshot = ptr.Bool(false)
+			params.FinalDBSnapshotIdentifier = ptr.String(snapshotName)
+		}
+	}
+
+	_, err := r.svc.DeleteDBInstance(params)
 
 	return err
 }
 
+// HandleWait waits for the final snapshot, if one was requested, to become available.
+func (r *NeptuneInstance) HandleWait(_ context.Context) error {
+	snapshotName := r.finalSnapshot.Requested()
+	if snapshotName == "" {
+		return nil
+	}
+
+	// Note: the Neptune API has no DB snapshots, the final snapshot of an instance is described with the RDS API
+	resp, err := r.rdsSvc.DescribeDBSnapshots(&rds.DescribeDBSnapshotsInput{
+		DBSnapshotIdentifier: ptr.String(snapshotName),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == rds.ErrCodeDBSnapshotNotFoundFault {
+			return r.finalSnapshot.Wait("")
+		}
+
+		return err
+	}
+
+	var status string
+	if len(resp.DBSnapshots) > 0 {
+		status = ptr.ToString(resp.DBSnapshots[0].Status)
+	}
+
+	return r.finalSnapshot.Wait(status)
+}
+
 func (r *NeptuneInstance) Properties() types.Properties {
 	return types.NewPropertiesFromStruct(r)
 }
@@ -2,6 +2,7 @@ package resources
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
@@ -22,6 +24,9 @@ func init() {
 		Scope:    nuke.Account,
 		Resource: &NeptuneSnapshot{},
 		Lister:   &NeptuneSnapshotLister{},
+		Settings: []string{
+			nuke.DeleteFinalSnapshotsSetting,
+		},
 		DeprecatedAliases: []string{
 			"NetpuneSnapshot",
 		},
@@ -76,6 +81,18 @@ type NeptuneSnapshot struct {
 	ID         *string
 	Status     *string
 	CreateTime *time.Time
+	settings   *libsettings.Setting
+}
+
+func (r *NeptuneSnapshot) Settings(settings *libsettings.Setting) {
+	r.settings = settings
+}
+
+func (r *NeptuneSnapshot) Filter() error {
+	if nuke.KeepFinalSnapshot(r.settings, aws.StringValue(r.ID)) {
+		return fmt.Errorf("final snapshot of a removed resource")
+	}
+	return nil
 }
 
 func (r *NeptuneSnapshot) Remove(_ context.Context) error {
@@ -0,0 +1,4 @@
+//go:generate ../mocks/generate_mocks.sh organizations organizationsiface
+package resources
+
+// Note: empty on purpose, this file exist purely to generate mocks for the Organizations service
@@ -0,0 +1,31 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/config"
+)
+
+func TestExposesTags(t *testing.T) {
+	cases := []struct {
+		name     string
+		resource interface{}
+		want     bool
+	}{
+		{name: "CloudFrontFunction", resource: &CloudFrontFunction{}},
+		{name: "CloudFrontDistributionDeployment", resource: &CloudFrontDistributionDeployment{}},
+		{name: "ServiceCatalogTagOptionPortfolioAttachment", resource: &ServiceCatalogTagOptionPortfolioAttachment{}},
+		{name: "CloudFrontDistribution", resource: &CloudFrontDistribution{}, want: true},
+		{name: "S3Bucket", resource: &S3Bucket{}, want: true},
+		{name: "IAMRole", resource: &IAMRole{}, want: true},
+		{name: "EC2InternetGatewayAttachment", resource: &EC2InternetGatewayAttachment{}, want: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, config.ExposesTags(tc.resource))
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
@@ -23,6 +24,9 @@ func init() {
 		Scope:    nuke.Account,
 		Resource: &RDSClusterSnapshot{},
 		Lister:   &RDSClusterSnapshotLister{},
+		Settings: []string{
+			nuke.DeleteFinalSnapshotsSetting,
+		},
 	})
 }
 
@@ -62,12 +66,20 @@ type RDSClusterSnapshot struct {
 	svc      *rds.RDS
 	snapshot *rds.DBClusterSnapshot
 	tags     []*rds.Tag
+	settings *libsettings.Setting
+}
+
+func (i *RDSClusterSnapshot) Settings(settings *libsettings.Setting) {
+	i.settings = settings
 }
 
 func (i *RDSClusterSnapshot) Filter() error {
 	if *i.snapshot.SnapshotType == "automated" {
 		return fmt.Errorf("cannot delete automated snapshots")
 	}
+	if nuke.KeepFinalSnapshot(i.settings, aws.StringValue(i.snapshot.DBClusterSnapshotIdentifier)) {
+		return fmt.Errorf("final snapshot of a removed resource")
+	}
 	return nil
 }
 
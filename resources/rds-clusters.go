@@ -2,12 +2,15 @@ package resources
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
@@ -21,6 +24,10 @@ func init() {
 		Scope:    nuke.Account,
 		Resource: &RDSDBCluster{},
 		Lister:   &RDSDBClusterLister{},
+		Settings: []string{
+			nuke.CreateFinalSnapshotSetting,
+			nuke.FinalSnapshotNameSetting,
+		},
 		DeprecatedAliases: []string{
 			"RDSCluster",
 		},
@@ -65,6 +72,13 @@ type RDSDBCluster struct {
 	id                 string
 	deletionProtection bool
 	tags               []*rds.Tag
+
+	settings      *libsettings.Setting
+	finalSnapshot nuke.FinalSnapshot
+}
+
+func (i *RDSDBCluster) Settings(settings *libsettings.Setting) {
+	i.settings = settings
 }
 
 func (i *RDSDBCluster) Remove(_ context.Context) error {
@@ -84,7 +98,17 @@ func (i *RDSDBCluster) Remove(_ context.Context) error {
 		SkipFinalSnapshot:   aws.Bool(true),
 	}
 
-	_, err := i.svc.DeleteDBCluster(params)
+	snapshotName, err := i.finalSnapshot.Name(i.settings, i.id)
+	if err != nil {
+		return err
+	}
+
+	if snapshotName != "" {
+		params.SkipFinalSnapshot = aws.Bool(false)
+		params.FinalDBSnapshotIdentifier = aws.String(snapshotName)
+	}
+
+	_, err = i.svc.DeleteDBCluster(params)
 	if err != nil {
 		return err
 	}
@@ -92,6 +116,33 @@ func (i *RDSDBCluster) Remove(_ context.Context) error {
 	return nil
 }
 
+// HandleWait waits for the final snapshot, if one was requested, to become available.
+func (i *RDSDBCluster) HandleWait(_ context.Context) error {
+	snapshotName := i.finalSnapshot.Requested()
+	if snapshotName == "" {
+		return nil
+	}
+
+	resp, err := i.svc.DescribeDBClusterSnapshots(&rds.DescribeDBClusterSnapshotsInput{
+		DBClusterSnapshotIdentifier: aws.String(snapshotName),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == rds.ErrCodeDBClusterSnapshotNotFoundFault {
+			return i.finalSnapshot.Wait("")
+		}
+
+		return err
+	}
+
+	var status string
+	if len(resp.DBClusterSnapshots) > 0 {
+		status = aws.StringValue(resp.DBClusterSnapshots[0].Status)
+	}
+
+	return i.finalSnapshot.Wait(status)
+}
+
 func (i *RDSDBCluster) String() string {
 	return i.id
 }
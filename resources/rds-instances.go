@@ -32,6 +32,8 @@ func init() {
 		Settings: []string{
 			"DisableDeletionProtection",
 			"StartClusterToDelete",
+			nuke.CreateFinalSnapshotSetting,
+			nuke.FinalSnapshotNameSetting,
 		},
 	})
 }
@@ -41,7 +43,8 @@ type RDSInstance struct {
 	instance *rds.DBInstance
 	tags     []*rds.Tag
 
-	settings *libsettings.Setting
+	settings      *libsettings.Setting
+	finalSnapshot nuke.FinalSnapshot
 }
 
 type RDSInstanceLister struct{}
@@ -133,6 +136,19 @@ func (i *RDSInstance) deleteDBInstance() error {
 		SkipFinalSnapshot:    aws.Bool(true),
 	}
 
+	// Note: the instances of a cluster and read replicas have no snapshots of their own
+	if i.instance.DBClusterIdentifier == nil && i.instance.ReadReplicaSourceDBInstanceIdentifier == nil {
+		snapshotName, err := i.finalSnapshot.Name(i.settings, aws.StringValue(i.instance.DBInstanceIdentifier))
+		if err != nil {
+			return err
+		}
+
+		if snapshotName != "" {
+			params.SkipFinalSnapshot = aws.Bool(false)
+			params.FinalDBSnapshotIdentifier = aws.String(snapshotName)
+		}
+	}
+
 	if _, err := i.svc.DeleteDBInstance(params); err != nil {
 		return err
 	}
@@ -206,7 +222,7 @@ func (i *RDSInstance) HandleWait(ctx context.Context) error {
 		var awsErr awserr.Error
 		ok := errors.As(err, &awsErr)
 		if ok && awsErr.Code() == "DBInstanceNotFound" {
-			return nil
+			return i.waitFinalSnapshot()
 		}
 
 		return err
@@ -228,5 +244,32 @@ func (i *RDSInstance) HandleWait(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	return i.waitFinalSnapshot()
+}
+
+// waitFinalSnapshot waits for the final snapshot, if one was requested, to become available.
+func (i *RDSInstance) waitFinalSnapshot() error {
+	snapshotName := i.finalSnapshot.Requested()
+	if snapshotName == "" {
+		return nil
+	}
+
+	resp, err := i.svc.DescribeDBSnapshots(&rds.DescribeDBSnapshotsInput{
+		DBSnapshotIdentifier: aws.String(snapshotName),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == rds.ErrCodeDBSnapshotNotFoundFault {
+			return i.finalSnapshot.Wait("")
+		}
+
+		return err
+	}
+
+	var status string
+	if len(resp.DBSnapshots) > 0 {
+		status = aws.StringValue(resp.DBSnapshots[0].Status)
+	}
+
+	return i.finalSnapshot.Wait(status)
 }
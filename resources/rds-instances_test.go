@@ -0,0 +1,28 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
+)
+
+func TestRDSInstance_CreatedAt(t *testing.T) {
+	createTime := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	r := &RDSInstance{
+		instance: &rds.DBInstance{
+			DBInstanceIdentifier: ptr.String("test"),
+			InstanceCreateTime:   ptr.Time(createTime),
+		},
+	}
+
+	createdAt, ok := nuke.CreatedAt(r)
+	assert.True(t, ok)
+	assert.True(t, createTime.Equal(createdAt))
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
@@ -24,6 +25,9 @@ func init() {
 		Scope:    nuke.Account,
 		Resource: &RDSSnapshot{},
 		Lister:   &RDSSnapshotLister{},
+		Settings: []string{
+			nuke.DeleteFinalSnapshotsSetting,
+		},
 	})
 }
 
@@ -62,12 +66,20 @@ type RDSSnapshot struct {
 	svc      *rds.RDS
 	snapshot *rds.DBSnapshot
 	tags     []*rds.Tag
+	settings *libsettings.Setting
+}
+
+func (i *RDSSnapshot) Settings(settings *libsettings.Setting) {
+	i.settings = settings
 }
 
 func (i *RDSSnapshot) Filter() error {
 	if *i.snapshot.SnapshotType == "automated" {
 		return fmt.Errorf("cannot delete automated snapshots")
 	}
+	if nuke.KeepFinalSnapshot(i.settings, aws.StringValue(i.snapshot.DBSnapshotIdentifier)) {
+		return fmt.Errorf("final snapshot of a removed resource")
+	}
 	return nil
 }
 
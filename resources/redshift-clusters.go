@@ -2,12 +2,15 @@ package resources
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/redshift"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
@@ -21,6 +24,10 @@ func init() {
 		Scope:    nuke.Account,
 		Resource: &RedshiftCluster{},
 		Lister:   &RedshiftClusterLister{},
+		Settings: []string{
+			nuke.CreateFinalSnapshotSetting,
+			nuke.FinalSnapshotNameSetting,
+		},
 	})
 }
 
@@ -62,11 +69,20 @@ func (l *RedshiftClusterLister) List(_ context.Context, o interface{}) ([]resour
 type RedshiftCluster struct {
 	svc     *redshift.Redshift
 	cluster *redshift.Cluster
+
+	settings      *libsettings.Setting
+	finalSnapshot nuke.FinalSnapshot
+}
+
+func (f *RedshiftCluster) Settings(settings *libsettings.Setting) {
+	f.settings = settings
 }
 
 func (f *RedshiftCluster) Properties() types.Properties {
 	properties := types.NewProperties().
-		Set("CreatedTime", f.cluster.ClusterCreateTime)
+		Set("CreatedTime", f.cluster.ClusterCreateTime).
+		Set("NodeType", f.cluster.NodeType).
+		Set("NumberOfNodes", f.cluster.NumberOfNodes)
 
 	for _, tag := range f.cluster.Tags {
 		properties.SetTag(tag.Key, tag.Value)
@@ -76,14 +92,53 @@ func (f *RedshiftCluster) Properties() types.Properties {
 }
 
 func (f *RedshiftCluster) Remove(_ context.Context) error {
-	_, err := f.svc.DeleteCluster(&redshift.DeleteClusterInput{
+	params := &redshift.DeleteClusterInput{
 		ClusterIdentifier:        f.cluster.ClusterIdentifier,
 		SkipFinalClusterSnapshot: aws.Bool(true),
-	})
+	}
+
+	snapshotName, err := f.finalSnapshot.Name(f.settings, aws.StringValue(f.cluster.ClusterIdentifier))
+	if err != nil {
+		return err
+	}
+
+	if snapshotName != "" {
+		params.SkipFinalClusterSnapshot = aws.Bool(false)
+		params.FinalClusterSnapshotIdentifier = aws.String(snapshotName)
+	}
+
+	_, err = f.svc.DeleteCluster(params)
 
 	return err
 }
 
+// HandleWait waits for the final snapshot, if one was requested, to become available.
+func (f *RedshiftCluster) HandleWait(_ context.Context) error {
+	snapshotName := f.finalSnapshot.Requested()
+	if snapshotName == "" {
+		return nil
+	}
+
+	resp, err := f.svc.DescribeClusterSnapshots(&redshift.DescribeClusterSnapshotsInput{
+		SnapshotIdentifier: aws.String(snapshotName),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == redshift.ErrCodeClusterSnapshotNotFoundFault {
+			return f.finalSnapshot.Wait("")
+		}
+
+		return err
+	}
+
+	var status string
+	if len(resp.Snapshots) > 0 {
+		status = aws.StringValue(resp.Snapshots[0].Status)
+	}
+
+	return f.finalSnapshot.Wait(status)
+}
+
 func (f *RedshiftCluster) String() string {
 	return *f.cluster.ClusterIdentifier
 }
@@ -2,12 +2,14 @@ package resources
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/redshift"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
 	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
@@ -21,6 +23,9 @@ func init() {
 		Scope:    nuke.Account,
 		Resource: &RedshiftSnapshot{},
 		Lister:   &RedshiftSnapshotLister{},
+		Settings: []string{
+			nuke.DeleteFinalSnapshotsSetting,
+		},
 	})
 }
 
@@ -62,6 +67,7 @@ func (l *RedshiftSnapshotLister) List(_ context.Context, o interface{}) ([]resou
 type RedshiftSnapshot struct {
 	svc      *redshift.Redshift
 	snapshot *redshift.Snapshot
+	settings *libsettings.Setting
 }
 
 func (f *RedshiftSnapshot) Properties() types.Properties {
@@ -75,6 +81,17 @@ func (f *RedshiftSnapshot) Properties() types.Properties {
 	return properties
 }
 
+func (f *RedshiftSnapshot) Settings(settings *libsettings.Setting) {
+	f.settings = settings
+}
+
+func (f *RedshiftSnapshot) Filter() error {
+	if nuke.KeepFinalSnapshot(f.settings, aws.StringValue(f.snapshot.SnapshotIdentifier)) {
+		return fmt.Errorf("final snapshot of a removed resource")
+	}
+	return nil
+}
+
 func (f *RedshiftSnapshot) Remove(_ context.Context) error {
 	_, err := f.svc.DeleteClusterSnapshot(&redshift.DeleteClusterSnapshotInput{
 		SnapshotIdentifier: f.snapshot.SnapshotIdentifier,
@@ -0,0 +1,4 @@
+//go:generate ../mocks/generate_mocks.sh resourcegroupstaggingapi resourcegroupstaggingapiiface
+package resources
+
+// Note: empty on purpose, this file exist purely to generate mocks for the Resource Groups Tagging API service
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/gotidy/ptr"
@@ -37,6 +38,8 @@ func init() {
 		Settings: []string{
 			"BypassGovernanceRetention",
 			"RemoveObjectLegalHold",
+			nuke.ArchiveBucketSetting,
+			nuke.ArchivePrefixSetting,
 		},
 	})
 }
@@ -140,7 +143,12 @@ type S3Bucket struct {
 }
 
 func (r *S3Bucket) Remove(ctx context.Context) error {
-	_, err := r.svc.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{
+	err := r.Archive(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.svc.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{
 		Bucket: r.Name,
 	})
 	if err != nil {
@@ -177,6 +185,258 @@ func (r *S3Bucket) Remove(ctx context.Context) error {
 	return err
 }
 
+// Archive copies the current version of every object to the archive bucket, when the ArchiveBucket setting is set.
+// The bucket is only removed once every object is copied.
+func (r *S3Bucket) Archive(ctx context.Context) error {
+	bucket, prefix := nuke.ArchiveLocation(r.settings, ptr.ToString(r.Name))
+	if bucket == "" {
+		return nil
+	}
+
+	location, err := r.svc.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
+		Bucket: ptr.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to get location of archive bucket %s: %w", bucket, err)
+	}
+
+	// Note: the copy is requested from the region of the archive bucket, which may differ from the removed bucket
+	region := string(location.LocationConstraint)
+	switch region {
+	case "":
+		region = "us-east-1"
+	case string(s3types.BucketLocationConstraintEu):
+		region = "eu-west-1"
+	}
+
+	archiver := &s3Archiver{
+		svc:    r.svc,
+		source: ptr.ToString(r.Name),
+		bucket: bucket,
+		prefix: prefix,
+		region: region,
+	}
+
+	return archiver.Archive(ctx)
+}
+
+const (
+	// s3CopyObjectMaxSize is the largest object that is copied with a single CopyObject, larger objects are copied in
+	// parts.
+	s3CopyObjectMaxSize = 5 * 1024 * 1024 * 1024
+
+	// s3CopyPartSize is the size of the parts of a copy in parts, unless the object needs larger parts to stay within
+	// the maximum number of parts.
+	s3CopyPartSize = 512 * 1024 * 1024
+
+	// s3CopyMaxParts is the maximum number of parts of a multipart upload.
+	s3CopyMaxParts = 10000
+
+	// s3ArchiveSourceETagMetadata is the metadata of an object that is copied in parts with the ETag of its source. The
+	// ETag of an object that is copied in parts differs from the ETag of its source.
+	s3ArchiveSourceETagMetadata = "aws-nuke-source-etag"
+)
+
+// S3ArchiveAPIClient is the part of the S3 client that archives the objects of a bucket.
+type S3ArchiveAPIClient interface {
+	s3.ListObjectsV2APIClient
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput,
+		optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput,
+		optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput,
+		optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput,
+		optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// s3Archiver copies the current version of every object of the source bucket below the prefix of the archive bucket.
+// Objects that are already archived are skipped, so that a removal that is retried only copies the objects that are
+// missing.
+type s3Archiver struct {
+	svc    S3ArchiveAPIClient
+	source string
+	bucket string
+	prefix string
+	region string
+}
+
+func (a *s3Archiver) Archive(ctx context.Context) error {
+	paginator := s3.NewListObjectsV2Paginator(a.svc, &s3.ListObjectsV2Input{
+		Bucket: ptr.String(a.source),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range page.Contents {
+			if err := a.archive(ctx, obj); err != nil {
+				return fmt.Errorf("unable to archive %s to %s: %w", ptr.ToString(obj.Key), a.bucket, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// archive copies the object, unless it is already archived.
+func (a *s3Archiver) archive(ctx context.Context, obj s3types.Object) error {
+	key := a.prefix + ptr.ToString(obj.Key)
+
+	archived, err := a.archived(ctx, key, obj)
+	if err != nil {
+		return err
+	}
+
+	if archived {
+		return nil
+	}
+
+	source := (&url.URL{Path: a.source + "/" + ptr.ToString(obj.Key)}).EscapedPath()
+
+	if ptr.ToInt64(obj.Size) <= s3CopyObjectMaxSize {
+		_, err := a.svc.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:     ptr.String(a.bucket),
+			Key:        ptr.String(key),
+			CopySource: ptr.String(source),
+		}, a.withRegion)
+
+		return err
+	}
+
+	return a.copyParts(ctx, key, source, obj)
+}
+
+// archived returns whether the object is already archived with the same size and ETag.
+func (a *s3Archiver) archived(ctx context.Context, key string, obj s3types.Object) (bool, error) {
+	head, err := a.svc.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: ptr.String(a.bucket),
+		Key:    ptr.String(key),
+	}, a.withRegion)
+	if err != nil {
+		var notFound *s3types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+
+		var aerr smithy.APIError
+		if errors.As(err, &aerr) && (aerr.ErrorCode() == "NotFound" || aerr.ErrorCode() == "NoSuchKey") {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if ptr.ToInt64(head.ContentLength) != ptr.ToInt64(obj.Size) {
+		return false, nil
+	}
+
+	etag := ptr.ToString(obj.ETag)
+
+	return ptr.ToString(head.ETag) == etag || head.Metadata[s3ArchiveSourceETagMetadata] == etag, nil
+}
+
+// copyParts copies an object that is too large for a single CopyObject in parts. The parts only copy the data, the
+// metadata of the source is set when the upload is created.
+func (a *s3Archiver) copyParts(ctx context.Context, key, source string, obj s3types.Object) error {
+	head, err := a.svc.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: ptr.String(a.source),
+		Key:    obj.Key,
+	})
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{}
+	for k, v := range head.Metadata {
+		metadata[k] = v
+	}
+	metadata[s3ArchiveSourceETagMetadata] = ptr.ToString(obj.ETag)
+
+	upload, err := a.svc.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             ptr.String(a.bucket),
+		Key:                ptr.String(key),
+		CacheControl:       head.CacheControl,
+		ContentDisposition: head.ContentDisposition,
+		ContentEncoding:    head.ContentEncoding,
+		ContentLanguage:    head.ContentLanguage,
+		ContentType:        head.ContentType,
+		Metadata:           metadata,
+	}, a.withRegion)
+	if err != nil {
+		return err
+	}
+
+	parts, err := a.uploadParts(ctx, key, source, ptr.ToString(upload.UploadId), obj)
+	if err == nil {
+		_, err = a.svc.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          ptr.String(a.bucket),
+			Key:             ptr.String(key),
+			UploadId:        upload.UploadId,
+			MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+		}, a.withRegion)
+	}
+
+	if err != nil {
+		_, abortErr := a.svc.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   ptr.String(a.bucket),
+			Key:      ptr.String(key),
+			UploadId: upload.UploadId,
+		}, a.withRegion)
+
+		return errors.Join(err, abortErr)
+	}
+
+	return nil
+}
+
+// uploadParts copies the parts of the object and returns them in order. The source must still have the ETag that it
+// was listed with, so that the parts are all copied from the same version of the object.
+func (a *s3Archiver) uploadParts(
+	ctx context.Context, key, source, uploadID string, obj s3types.Object) ([]s3types.CompletedPart, error) {
+	size := ptr.ToInt64(obj.Size)
+
+	partSize := int64(s3CopyPartSize)
+	if minimum := (size + s3CopyMaxParts - 1) / s3CopyMaxParts; minimum > partSize {
+		partSize = minimum
+	}
+
+	var parts []s3types.CompletedPart
+	for start, number := int64(0), int32(1); start < size; start, number = start+partSize, number+1 {
+		end := min(start+partSize, size) - 1
+
+		part, err := a.svc.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:            ptr.String(a.bucket),
+			Key:               ptr.String(key),
+			UploadId:          ptr.String(uploadID),
+			PartNumber:        ptr.Int32(number),
+			CopySource:        ptr.String(source),
+			CopySourceIfMatch: obj.ETag,
+			CopySourceRange:   ptr.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		}, a.withRegion)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, s3types.CompletedPart{
+			ETag:       part.CopyPartResult.ETag,
+			PartNumber: ptr.Int32(number),
+		})
+	}
+
+	return parts, nil
+}
+
+// withRegion sends the request to the region of the archive bucket.
+func (a *s3Archiver) withRegion(o *s3.Options) {
+	o.Region = a.region
+}
+
 func (r *S3Bucket) RemoveAllLegalHolds(ctx context.Context) error {
 	if !r.settings.GetBool("RemoveObjectLegalHold") {
 		return nil
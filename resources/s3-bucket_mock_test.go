@@ -0,0 +1,197 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type mockS3ArchiveClient struct {
+	mock.Mock
+}
+
+func (m *mockS3ArchiveClient) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input,
+	_ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*s3.ListObjectsV2Output), args.Error(1)
+}
+
+func (m *mockS3ArchiveClient) HeadObject(ctx context.Context, params *s3.HeadObjectInput,
+	_ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	args := m.Called(ctx, params)
+	out, _ := args.Get(0).(*s3.HeadObjectOutput)
+	return out, args.Error(1)
+}
+
+func (m *mockS3ArchiveClient) CopyObject(ctx context.Context, params *s3.CopyObjectInput,
+	_ ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*s3.CopyObjectOutput), args.Error(1)
+}
+
+func (m *mockS3ArchiveClient) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput,
+	_ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*s3.CreateMultipartUploadOutput), args.Error(1)
+}
+
+func (m *mockS3ArchiveClient) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput,
+	_ ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	args := m.Called(ctx, params)
+	out, _ := args.Get(0).(*s3.UploadPartCopyOutput)
+	return out, args.Error(1)
+}
+
+func (m *mockS3ArchiveClient) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput,
+	_ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*s3.CompleteMultipartUploadOutput), args.Error(1)
+}
+
+func (m *mockS3ArchiveClient) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput,
+	_ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*s3.AbortMultipartUploadOutput), args.Error(1)
+}
+
+func newTestS3Archiver(svc S3ArchiveAPIClient, objects ...s3types.Object) *s3Archiver {
+	if m, ok := svc.(*mockS3ArchiveClient); ok {
+		m.On("ListObjectsV2", mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+			Contents: objects,
+		}, nil)
+	}
+
+	return &s3Archiver{
+		svc:    svc,
+		source: "source",
+		bucket: "archive",
+		prefix: "nuked/source/",
+		region: "eu-west-1",
+	}
+}
+
+func headArchived(key string) interface{} {
+	return mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+		return ptr.ToString(input.Bucket) == "archive" && ptr.ToString(input.Key) == key
+	})
+}
+
+func Test_Mock_S3Bucket_Archive_Skip(t *testing.T) {
+	mockSvc := new(mockS3ArchiveClient)
+
+	archiver := newTestS3Archiver(mockSvc,
+		s3types.Object{Key: ptr.String("same"), Size: ptr.Int64(10), ETag: ptr.String(`"a"`)},
+		s3types.Object{Key: ptr.String("changed"), Size: ptr.Int64(10), ETag: ptr.String(`"b"`)},
+		s3types.Object{Key: ptr.String("resized"), Size: ptr.Int64(10), ETag: ptr.String(`"c"`)},
+		s3types.Object{Key: ptr.String("missing"), Size: ptr.Int64(10), ETag: ptr.String(`"d"`)},
+		s3types.Object{Key: ptr.String("parts"), Size: ptr.Int64(10), ETag: ptr.String(`"e"`)},
+	)
+
+	mockSvc.On("HeadObject", mock.Anything, headArchived("nuked/source/same")).Return(&s3.HeadObjectOutput{
+		ContentLength: ptr.Int64(10), ETag: ptr.String(`"a"`),
+	}, nil)
+	mockSvc.On("HeadObject", mock.Anything, headArchived("nuked/source/changed")).Return(&s3.HeadObjectOutput{
+		ContentLength: ptr.Int64(10), ETag: ptr.String(`"x"`),
+	}, nil)
+	mockSvc.On("HeadObject", mock.Anything, headArchived("nuked/source/resized")).Return(&s3.HeadObjectOutput{
+		ContentLength: ptr.Int64(5), ETag: ptr.String(`"c"`),
+	}, nil)
+	mockSvc.On("HeadObject", mock.Anything, headArchived("nuked/source/missing")).
+		Return(nil, &s3types.NotFound{})
+	mockSvc.On("HeadObject", mock.Anything, headArchived("nuked/source/parts")).Return(&s3.HeadObjectOutput{
+		ContentLength: ptr.Int64(10),
+		ETag:          ptr.String(`"y-2"`),
+		Metadata:      map[string]string{s3ArchiveSourceETagMetadata: `"e"`},
+	}, nil)
+
+	for _, key := range []string{"changed", "resized", "missing"} {
+		mockSvc.On("CopyObject", mock.Anything, &s3.CopyObjectInput{
+			Bucket:     ptr.String("archive"),
+			Key:        ptr.String("nuked/source/" + key),
+			CopySource: ptr.String("source/" + key),
+		}).Return(&s3.CopyObjectOutput{}, nil).Once()
+	}
+
+	assert.NoError(t, archiver.Archive(context.TODO()))
+	mockSvc.AssertExpectations(t)
+	mockSvc.AssertNumberOfCalls(t, "CopyObject", 3)
+}
+
+func Test_Mock_S3Bucket_Archive_Parts(t *testing.T) {
+	mockSvc := new(mockS3ArchiveClient)
+
+	size := int64(6*1024*1024*1024 + 1)
+	archiver := newTestS3Archiver(mockSvc,
+		s3types.Object{Key: ptr.String("large"), Size: ptr.Int64(size), ETag: ptr.String(`"large"`)},
+	)
+
+	mockSvc.On("HeadObject", mock.Anything, headArchived("nuked/source/large")).Return(nil, &s3types.NotFound{})
+	mockSvc.On("HeadObject", mock.Anything, &s3.HeadObjectInput{
+		Bucket: ptr.String("source"),
+		Key:    ptr.String("large"),
+	}).Return(&s3.HeadObjectOutput{
+		ContentType: ptr.String("application/octet-stream"),
+		Metadata:    map[string]string{"owner": "platform"},
+	}, nil)
+
+	mockSvc.On("CreateMultipartUpload", mock.Anything, &s3.CreateMultipartUploadInput{
+		Bucket:      ptr.String("archive"),
+		Key:         ptr.String("nuked/source/large"),
+		ContentType: ptr.String("application/octet-stream"),
+		Metadata:    map[string]string{"owner": "platform", s3ArchiveSourceETagMetadata: `"large"`},
+	}).Return(&s3.CreateMultipartUploadOutput{UploadId: ptr.String("upload")}, nil)
+
+	var ranges []string
+	mockSvc.On("UploadPartCopy", mock.Anything, mock.MatchedBy(func(input *s3.UploadPartCopyInput) bool {
+		return ptr.ToString(input.UploadId) == "upload" && ptr.ToString(input.CopySource) == "source/large" &&
+			ptr.ToString(input.CopySourceIfMatch) == `"large"`
+	})).Run(func(args mock.Arguments) {
+		input := args.Get(1).(*s3.UploadPartCopyInput)
+		ranges = append(ranges, ptr.ToString(input.CopySourceRange))
+	}).Return(&s3.UploadPartCopyOutput{CopyPartResult: &s3types.CopyPartResult{ETag: ptr.String("part")}}, nil)
+
+	mockSvc.On("CompleteMultipartUpload", mock.Anything, mock.MatchedBy(func(input *s3.CompleteMultipartUploadInput) bool {
+		parts := input.MultipartUpload.Parts
+		return len(parts) == 13 && ptr.ToInt32(parts[0].PartNumber) == 1 && ptr.ToInt32(parts[12].PartNumber) == 13
+	})).Return(&s3.CompleteMultipartUploadOutput{}, nil)
+
+	assert.NoError(t, archiver.Archive(context.TODO()))
+	mockSvc.AssertExpectations(t)
+	mockSvc.AssertNotCalled(t, "CopyObject", mock.Anything, mock.Anything)
+
+	assert.Len(t, ranges, 13)
+	assert.Equal(t, fmt.Sprintf("bytes=0-%d", s3CopyPartSize-1), ranges[0])
+	assert.Equal(t, fmt.Sprintf("bytes=%d-%d", 12*int64(s3CopyPartSize), size-1), ranges[12])
+}
+
+func Test_Mock_S3Bucket_Archive_PartsAbort(t *testing.T) {
+	mockSvc := new(mockS3ArchiveClient)
+
+	archiver := newTestS3Archiver(mockSvc,
+		s3types.Object{Key: ptr.String("large"), Size: ptr.Int64(6 * 1024 * 1024 * 1024), ETag: ptr.String(`"large"`)},
+	)
+
+	mockSvc.On("HeadObject", mock.Anything, headArchived("nuked/source/large")).Return(nil, &s3types.NotFound{})
+	mockSvc.On("HeadObject", mock.Anything, mock.Anything).Return(&s3.HeadObjectOutput{}, nil)
+	mockSvc.On("CreateMultipartUpload", mock.Anything, mock.Anything).
+		Return(&s3.CreateMultipartUploadOutput{UploadId: ptr.String("upload")}, nil)
+	mockSvc.On("UploadPartCopy", mock.Anything, mock.Anything).Return(nil, errors.New("precondition failed"))
+	mockSvc.On("AbortMultipartUpload", mock.Anything, &s3.AbortMultipartUploadInput{
+		Bucket:   ptr.String("archive"),
+		Key:      ptr.String("nuked/source/large"),
+		UploadId: ptr.String("upload"),
+	}).Return(&s3.AbortMultipartUploadOutput{}, nil)
+
+	err := archiver.Archive(context.TODO())
+	assert.ErrorContains(t, err, "unable to archive large to archive: precondition failed")
+	mockSvc.AssertExpectations(t)
+	mockSvc.AssertNotCalled(t, "CompleteMultipartUpload", mock.Anything, mock.Anything)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
 
 	"github.com/ekristen/aws-nuke/v3/pkg/nuke"
 )
@@ -45,6 +46,8 @@ func (l *SageMakerNotebookInstanceLister) List(_ context.Context, o interface{})
 			resources = append(resources, &SageMakerNotebookInstance{
 				svc:                  svc,
 				notebookInstanceName: notebookInstance.NotebookInstanceName,
+				instanceType:         notebookInstance.InstanceType,
+				status:               notebookInstance.NotebookInstanceStatus,
 			})
 		}
 
@@ -61,6 +64,8 @@ func (l *SageMakerNotebookInstanceLister) List(_ context.Context, o interface{})
 type SageMakerNotebookInstance struct {
 	svc                  *sagemaker.SageMaker
 	notebookInstanceName *string
+	instanceType         *string
+	status               *string
 }
 
 func (f *SageMakerNotebookInstance) Remove(_ context.Context) error {
@@ -71,6 +76,13 @@ func (f *SageMakerNotebookInstance) Remove(_ context.Context) error {
 	return err
 }
 
+func (f *SageMakerNotebookInstance) Properties() types.Properties {
+	return types.NewProperties().
+		Set("Name", f.notebookInstanceName).
+		Set("InstanceType", f.instanceType).
+		Set("Status", f.status)
+}
+
 func (f *SageMakerNotebookInstance) String() string {
 	return *f.notebookInstanceName
 }
@@ -0,0 +1,36 @@
+//go:build emulator
+
+package resources
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/aws-nuke/v3/pkg/testsuite"
+)
+
+func Test_Emulator_SQSQueue(t *testing.T) {
+	emulator := testsuite.NewEmulator(t, "sqs")
+
+	svc := sqs.New(emulator.Session("sqs"))
+
+	out, err := svc.CreateQueue(&sqs.CreateQueueInput{
+		QueueName: ptr.String(fmt.Sprintf("aws-nuke-testing-%d", time.Now().UnixNano())),
+		Tags: map[string]*string{
+			"aws-nuke": ptr.String("testing"),
+		},
+	})
+	require.NoError(t, err)
+
+	emulator.RunCycle(testsuite.EmulatorRegion, SQSQueueResource, func(r resource.Resource) bool {
+		return ptr.ToString(r.(*SQSQueue).queueURL) == ptr.ToString(out.QueueUrl)
+	})
+}